@@ -2,9 +2,12 @@ package routes
 
 import (
 	"application/app/controllers"
+	"application/app/enums"
 	"application/app/repositories"
+	"application/app/services"
 	"application/config"
 	"application/pkg/middleware"
+	"application/pkg/signature"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,20 +19,21 @@ type Route struct {
 	appVersion string
 	cfg        *config.Config
 	repo       *repositories.RepositoryContext
+	deps       *services.Dependencies
 	router     *gin.RouterGroup
 	auth       *middleware.Auth
 	ctrl       *controllers.Controller
 }
 
-func NewRoute(startTime time.Time, appVersion string, cfg *config.Config, repo *repositories.RepositoryContext, router *gin.RouterGroup) *Route {
-	return &Route{startTime: startTime, appVersion: appVersion, cfg: cfg, repo: repo, router: router}
+func NewRoute(startTime time.Time, appVersion string, cfg *config.Config, repo *repositories.RepositoryContext, deps *services.Dependencies, router *gin.RouterGroup) *Route {
+	return &Route{startTime: startTime, appVersion: appVersion, cfg: cfg, repo: repo, deps: deps, router: router}
 }
 
 func (r *Route) RegisterCoreServicesRoutes() {
-	ctrl := controllers.NewController(r.startTime, r.appVersion, r.cfg, r.repo)
+	ctrl := controllers.NewController(r.startTime, r.appVersion, r.cfg, r.repo, r.deps)
 	log.Warn().Msg("running route ....")
 
-	auth := middleware.NewAuth(r.cfg, r.repo)
+	auth := middleware.NewAuth(r.cfg, r.repo, r.deps)
 
 	if r.auth == nil {
 		r.auth = auth
@@ -43,6 +47,65 @@ func (r *Route) RegisterCoreServicesRoutes() {
 	r.initRoute()
 }
 
+// RegisterWellKnownRoutes registers the discovery routes served from the root path.
+func (r *Route) RegisterWellKnownRoutes(e *gin.Engine) {
+	e.GET("/.well-known/jwks.json", r.ctrl.JwksController)
+}
+
+// RegisterOAuthRoutes registers the OAuth2 endpoints served from the root path.
+func (r *Route) RegisterOAuthRoutes(e *gin.Engine) {
+	e.POST("/oauth/token", r.ctrl.TokenController)
+	e.POST("/oauth/introspect", r.ctrl.IntrospectionController)
+}
+
 func (r *Route) initRoute() {
+	r.initAuthRoute()
+	r.initAdminRoute()
+	r.initClientRoute()
+	r.initPartnerRoute()
+}
+
+func (r *Route) initAuthRoute() {
+	auth := r.router.Group("/auth")
+	auth.POST("/login", r.ctrl.LoginController)
+	auth.POST("/login/mfa", r.ctrl.MfaLoginController)
+	auth.POST("/refresh", r.ctrl.RefreshTokenController)
+	auth.POST("/password/forgot", r.ctrl.ForgotPasswordController)
+	auth.POST("/password/reset", r.ctrl.ResetPasswordController)
+	auth.POST("/oidc/authorize", r.ctrl.OidcAuthorizeController)
+	auth.POST("/oidc/callback", r.ctrl.OidcCallbackController)
+	auth.GET("/me", r.auth.Authentication(), r.ctrl.MeController)
+	auth.PUT("/me/locale", r.auth.Authentication(), r.ctrl.UpdateLocaleController)
+	auth.POST("/logout", r.auth.Authentication(), r.ctrl.LogoutController)
+	auth.POST("/logout-all", r.auth.Authentication(), r.ctrl.LogoutAllController)
+
+	sessions := auth.Group("/sessions", r.auth.Authentication())
+	sessions.GET("", r.ctrl.ListSessionController)
+	sessions.DELETE("/:sessionId", r.ctrl.RevokeSessionController)
+
+	twoFactor := auth.Group("/2fa", r.auth.Authentication())
+	twoFactor.GET("", r.ctrl.TwoFactorStatusController)
+	twoFactor.POST("/enroll", r.ctrl.EnrollTwoFactorController)
+	twoFactor.POST("/confirm", r.ctrl.ConfirmTwoFactorController)
+	twoFactor.POST("/disable", r.ctrl.DisableTwoFactorController)
+	twoFactor.POST("/recovery-codes", r.ctrl.RegenerateRecoveryCodesController)
+}
+
+func (r *Route) initAdminRoute() {
+	admin := r.router.Group("/admin", r.auth.Authentication(), r.auth.AdminAuthorization())
+	admin.GET("/roles", r.auth.RequirePermission(enums.PermissionRolesRead), r.ctrl.ListRoleController)
+	admin.POST("/users/:userId/revoke-tokens", r.auth.RequirePermission(enums.PermissionSessionsRevoke), r.ctrl.RevokeUserTokensController)
+	admin.GET("/api-keys", r.auth.RequirePermission(enums.PermissionApiKeysRead), r.ctrl.ListApiKeyController)
+	admin.POST("/api-keys", r.auth.RequirePermission(enums.PermissionApiKeysWrite), r.ctrl.CreateApiKeyController)
+	admin.DELETE("/api-keys/:keyId", r.auth.RequirePermission(enums.PermissionApiKeysWrite), r.ctrl.RevokeApiKeyController)
+}
+
+func (r *Route) initClientRoute() {
+	clients := r.router.Group("/clients", r.auth.Authentication(), r.auth.RequireScope())
+	clients.GET("/me", r.ctrl.ClientMeController)
+}
 
+func (r *Route) initPartnerRoute() {
+	partners := r.router.Group("/partners", r.auth.ClientMiddleware())
+	partners.POST("/payments/callback", r.auth.RequireScope(signature.ScopePaymentCallback), r.ctrl.PaymentCallbackController)
 }
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	apperror "application/app/error"
+	"application/app/services"
+	"application/app/web"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (c *Controller) ListRoleController(ctx *gin.Context) {
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	roles, err := service.ListRoles()
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "list roles",
+		Data:    roles,
+	})
+}
+
+// RevokeUserTokensController logs a user out of every device, e.g. when the account is compromised.
+func (c *Controller) RevokeUserTokensController(ctx *gin.Context) {
+	userId, err := strconv.ParseInt(ctx.Param("userId"), 10, 64)
+	if err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "revoke user tokens").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	if err := service.RevokeUserTokens(userId, services.RevokeReasonAdminRevoked); err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "user tokens revoked",
+	})
+}
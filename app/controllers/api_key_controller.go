@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	apperror "application/app/error"
+	"application/app/services"
+	"application/app/web"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (c *Controller) CreateApiKeyController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	var request web.CreateApiKeyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "create api key").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	key, err := service.CreateApiKey(&request, &claims.Id, claims.Sub)
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, web.ResponseWeb{
+		Success: true,
+		Message: "api key created",
+		Data:    key,
+	})
+}
+
+func (c *Controller) ListApiKeyController(ctx *gin.Context) {
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	keys, err := service.ListApiKeys()
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "list api keys",
+		Data:    keys,
+	})
+}
+
+func (c *Controller) RevokeApiKeyController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	keyId, err := strconv.ParseInt(ctx.Param("keyId"), 10, 64)
+	if err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "revoke api key").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	if err := service.RevokeApiKey(keyId, claims.Sub); err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "api key revoked",
+	})
+}
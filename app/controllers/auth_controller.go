@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	apperror "application/app/error"
+	"application/app/services"
+	"application/app/web"
+	"application/pkg/lockout"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (c *Controller) LoginController(ctx *gin.Context) {
+	var request web.LoginRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "login").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	session, challenge, err := service.Login(&request, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		loginErrorResponse(ctx, err)
+		return
+	}
+
+	if challenge != nil {
+		ctx.JSON(http.StatusOK, web.ResponseWeb{
+			Success: true,
+			Message: "two-factor code required",
+			Data:    challenge,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "login success",
+		Data:    session,
+	})
+}
+
+// MfaLoginController completes a login of an account with two-factor authentication.
+func (c *Controller) MfaLoginController(ctx *gin.Context) {
+	var request web.MfaLoginRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "login").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	session, err := service.LoginWithMfa(&request, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		loginErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "login success",
+		Data:    session,
+	})
+}
+
+func (c *Controller) MeController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	user, err := service.Me(claims)
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "me",
+		Data:    user,
+	})
+}
+
+// UpdateLocaleController stores the language the admin gets messages in.
+func (c *Controller) UpdateLocaleController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	var request web.UpdateLocaleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "update locale").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	if err := service.UpdateLocale(claims.Id, &request); err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "locale updated",
+	})
+}
+
+// loginErrorResponse tells locked out clients when to retry.
+func loginErrorResponse(ctx *gin.Context, err error) {
+	var locked *lockout.LockedError
+	if errors.As(err, &locked) {
+		ctx.Header("Retry-After", strconv.Itoa(locked.RetryAfter()))
+	}
+
+	apperror.ErrorResponse(ctx, err)
+}
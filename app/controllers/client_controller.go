@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	apperror "application/app/error"
+	"application/app/web"
+	"application/pkg/middleware"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientMeController returns the client authenticated with basic auth, an API
+// key or an OAuth2 access token, and the scopes it was granted.
+func (c *Controller) ClientMeController(ctx *gin.Context) {
+	client, ok := middleware.GetClient(ctx)
+	if !ok {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(errClientRequired, "client me").Status(http.StatusUnauthorized))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "authenticated client",
+		Data:    client,
+	})
+}
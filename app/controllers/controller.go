@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"application/app/repositories"
+	"application/app/services"
 	"application/config"
 	"time"
 )
@@ -9,14 +10,16 @@ import (
 type Controller struct {
 	repo       *repositories.RepositoryContext
 	cfg        *config.Config
+	deps       *services.Dependencies
 	startTime  time.Time
 	appVersion string
 	context    string
 }
 
-func NewController(startTime time.Time, appVersion string, cfg *config.Config, repo *repositories.RepositoryContext) *Controller {
+func NewController(startTime time.Time, appVersion string, cfg *config.Config, repo *repositories.RepositoryContext, deps *services.Dependencies) *Controller {
 	return &Controller{
 		cfg:        cfg,
+		deps:       deps,
 		repo:       repo,
 		startTime:  startTime,
 		appVersion: appVersion,
@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	pkgjwt "application/pkg/jwt"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JwksController publishes the pending, active and recently retired public signing keys.
+func (c *Controller) JwksController(ctx *gin.Context) {
+	ctx.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(pkgjwt.JwksMaxAge.Seconds())))
+	ctx.JSON(http.StatusOK, c.deps.Jwt.Keys.JWKS())
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	apperror "application/app/error"
+	"application/app/services"
+	"application/app/web"
+	"application/pkg/lockout"
+	"application/pkg/oauth"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenController is the OAuth2 token endpoint. It answers in the format of
+// RFC 6749 rather than the ResponseWeb envelope so that standard clients work.
+func (c *Controller) TokenController(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+
+	var request web.TokenRequest
+	if err := ctx.ShouldBind(&request); err != nil {
+		oauthErrorResponse(ctx, oauth.ErrInvalidRequest)
+		return
+	}
+
+	credentials, ok := clientCredentials(ctx, request.ClientId, request.ClientSecret)
+	if !ok {
+		oauthErrorResponse(ctx, oauth.ErrInvalidRequest)
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	token, err := service.IssueClientToken(&request, credentials, ctx.ClientIP())
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, token)
+}
+
+// IntrospectionController is the token introspection endpoint of RFC 7662.
+func (c *Controller) IntrospectionController(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	var request web.IntrospectionRequest
+	if err := ctx.ShouldBind(&request); err != nil {
+		oauthErrorResponse(ctx, oauth.ErrInvalidRequest)
+		return
+	}
+
+	credentials, ok := clientCredentials(ctx, "", "")
+	if !ok {
+		oauthErrorResponse(ctx, oauth.ErrInvalidRequest)
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	response, err := service.IntrospectToken(&request, credentials, ctx.ClientIP())
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// clientCredentials reads the credentials sent with HTTP Basic (form encoded
+// as required by RFC 6749 section 2.3.1) or in the form. Using both is refused.
+func clientCredentials(ctx *gin.Context, formId string, formSecret string) (*services.ClientCredentials, bool) {
+	username, password, basic := ctx.Request.BasicAuth()
+	if !basic {
+		return &services.ClientCredentials{ClientId: formId, ClientSecret: formSecret}, true
+	}
+
+	if formId != "" || formSecret != "" {
+		return nil, false
+	}
+
+	clientId, err := url.QueryUnescape(username)
+	if err != nil {
+		return nil, false
+	}
+
+	clientSecret, err := url.QueryUnescape(password)
+	if err != nil {
+		return nil, false
+	}
+
+	return &services.ClientCredentials{ClientId: clientId, ClientSecret: clientSecret}, true
+}
+
+func oauthErrorResponse(ctx *gin.Context, err error) {
+	var locked *lockout.LockedError
+	if errors.As(err, &locked) {
+		ctx.Header("Retry-After", strconv.Itoa(locked.RetryAfter()))
+	}
+
+	var oauthErr *oauth.Error
+	if !errors.As(err, &oauthErr) {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	if oauthErr == oauth.ErrInvalidClient {
+		ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+
+	ctx.JSON(oauthErr.StatusCode, web.OAuthErrorResponse{
+		Error:            oauthErr.Code,
+		ErrorDescription: oauthErr.Description,
+	})
+}
@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"application/app/models"
+	"application/app/repositories/repositorytest"
+	"application/app/services"
+	"application/app/web"
+	"application/config"
+	"application/pkg/audit"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/lockout"
+	"application/pkg/middleware"
+	"application/pkg/oauth"
+	"application/pkg/revocation"
+	"application/pkg/util"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testClientKey = []byte("client-secret-key")
+
+// newOAuthTestRouter serves the OAuth2 endpoints and GET /clients/me on an
+// in-memory database with the clients "gateway", granted introspection, and
+// "orders", granted orders:*.
+func newOAuthTestRouter(t *testing.T) (*gin.Engine, *services.Dependencies) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repo, db := repositorytest.Open(t, &models.OAuthClient{}, &models.LoginAttempt{}, &models.AuditEvent{}, &models.TokenRevocation{})
+
+	clients := []*models.OAuthClient{
+		{ClientId: "gateway", SecretHash: util.HashSecret(testClientKey, "gateway-secret"), Scopes: models.StringList{oauth.ScopeIntrospect}, IsEnabled: true},
+		{ClientId: "orders", SecretHash: util.HashSecret(testClientKey, "orders-secret"), Scopes: models.StringList{"orders:*"}, IsEnabled: true},
+	}
+	if err := db.Create(clients).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	deps := &services.Dependencies{
+		Jwt:          pkgjwt.NewJwtAdapter("test", "secret"),
+		Revocation:   revocation.NewStore(repo, 0, 0),
+		Lockout:      lockout.NewGuard(repo, lockout.Config{Threshold: 3, IpThreshold: 10}),
+		Audit:        audit.NewRecorder(repo),
+		OAuthClients: oauth.NewStore(repo, testClientKey, 0),
+	}
+
+	cfg := &config.Config{}
+	ctrl := NewController(time.Now(), "test", cfg, repo, deps)
+	auth := middleware.NewAuth(cfg, repo, deps)
+
+	router := gin.New()
+	router.POST("/oauth/token", ctrl.TokenController)
+	router.POST("/oauth/introspect", ctrl.IntrospectionController)
+	router.GET("/clients/me", auth.Authentication(), auth.RequireScope(), ctrl.ClientMeController)
+
+	return router, deps
+}
+
+func postForm(router *gin.Engine, path string, form url.Values, clientId string, secret string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if clientId != "" {
+		request.SetBasicAuth(url.QueryEscape(clientId), url.QueryEscape(secret))
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func issueTestToken(t *testing.T, router *gin.Engine, clientId string, secret string, scope string) string {
+	t.Helper()
+
+	recorder := postForm(router, "/oauth/token", url.Values{"grant_type": {"client_credentials"}, "scope": {scope}}, clientId, secret)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("token status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var token web.TokenResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &token); err != nil {
+		t.Fatal(err)
+	}
+
+	return token.AccessToken
+}
+
+func TestTokenController(t *testing.T) {
+	router, _ := newOAuthTestRouter(t)
+
+	grant := url.Values{"grant_type": {"client_credentials"}}
+
+	tests := []struct {
+		name     string
+		form     url.Values
+		clientId string
+		secret   string
+		status   int
+		error    string
+	}{
+		{"basic", grant, "orders", "orders-secret", http.StatusOK, ""},
+		{"form", url.Values{"grant_type": {"client_credentials"}, "client_id": {"orders"}, "client_secret": {"orders-secret"}}, "", "", http.StatusOK, ""},
+		{"basic and form", url.Values{"grant_type": {"client_credentials"}, "client_id": {"orders"}}, "orders", "orders-secret", http.StatusBadRequest, "invalid_request"},
+		{"missing grant type", url.Values{}, "orders", "orders-secret", http.StatusBadRequest, "invalid_request"},
+		{"other grant type", url.Values{"grant_type": {"password"}}, "orders", "orders-secret", http.StatusBadRequest, "unsupported_grant_type"},
+		{"wrong secret", grant, "orders", "wrong", http.StatusUnauthorized, "invalid_client"},
+		{"exceeding scope", url.Values{"grant_type": {"client_credentials"}, "scope": {"users:read"}}, "orders", "orders-secret", http.StatusBadRequest, "invalid_scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := postForm(router, "/oauth/token", tt.form, tt.clientId, tt.secret)
+
+			if recorder.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body = %s", recorder.Code, tt.status, recorder.Body)
+			}
+			if recorder.Header().Get("Cache-Control") != "no-store" {
+				t.Error("token response may be cached")
+			}
+
+			if tt.error == "" {
+				var token web.TokenResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &token); err != nil || token.AccessToken == "" ||
+					token.TokenType != pkgjwt.TokenTypeBearer || token.Scope != "orders:*" {
+					t.Errorf("token = %+v, %v", token, err)
+				}
+				return
+			}
+
+			var response web.OAuthErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil || response.Error != tt.error {
+				t.Errorf("error = %+v, want %s", response, tt.error)
+			}
+			if tt.error == "invalid_client" && recorder.Header().Get("WWW-Authenticate") == "" {
+				t.Error("invalid_client without WWW-Authenticate")
+			}
+		})
+	}
+}
+
+func TestTokenControllerLocksTheClient(t *testing.T) {
+	router, _ := newOAuthTestRouter(t)
+	grant := url.Values{"grant_type": {"client_credentials"}}
+
+	for range 3 {
+		postForm(router, "/oauth/token", grant, "orders", "wrong")
+	}
+
+	recorder := postForm(router, "/oauth/token", grant, "orders", "orders-secret")
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") == "" {
+		t.Errorf("status = %d, Retry-After = %q, want %d with a delay", recorder.Code, recorder.Header().Get("Retry-After"), http.StatusTooManyRequests)
+	}
+}
+
+func TestIntrospectionController(t *testing.T) {
+	router, deps := newOAuthTestRouter(t)
+
+	clientToken := issueTestToken(t, router, "orders", "orders-secret", "orders:read")
+
+	admin, err := deps.Jwt.IssueJwt(&pkgjwt.IssueJwtPayload{Id: 7, Subject: "admin", Lifetime: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revoked := issueTestToken(t, router, "orders", "orders-secret", "")
+	claims, err := deps.Jwt.VerifyClientJwt(revoked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := deps.Revocation.RevokeToken(context.Background(), claims.Jti, 0, time.Now().Add(time.Hour), "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	introspect := func(clientId string, secret string, token string) (*httptest.ResponseRecorder, web.IntrospectionResponse) {
+		t.Helper()
+
+		recorder := postForm(router, "/oauth/introspect", url.Values{"token": {token}}, clientId, secret)
+
+		var response web.IntrospectionResponse
+		_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		return recorder, response
+	}
+
+	if recorder, _ := introspect("orders", "orders-secret", clientToken); recorder.Code != http.StatusForbidden {
+		t.Errorf("introspection without the scope status = %d, want %d", recorder.Code, http.StatusForbidden)
+	}
+	if recorder, _ := introspect("gateway", "wrong", clientToken); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("introspection with a wrong secret status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+	if recorder, _ := introspect("gateway", "gateway-secret", ""); recorder.Code != http.StatusBadRequest {
+		t.Errorf("introspection without a token status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+
+	if _, response := introspect("gateway", "gateway-secret", clientToken); !response.Active ||
+		response.ClientId != "orders" || response.Scope != "orders:read" {
+		t.Errorf("client token introspection = %+v, want active for orders", response)
+	}
+	if _, response := introspect("gateway", "gateway-secret", admin.Token); !response.Active || response.Username != "admin" {
+		t.Errorf("admin token introspection = %+v, want active for admin", response)
+	}
+	if _, response := introspect("gateway", "gateway-secret", revoked); response.Active {
+		t.Errorf("revoked token introspection = %+v, want inactive", response)
+	}
+	if _, response := introspect("gateway", "gateway-secret", "garbage"); response.Active {
+		t.Errorf("invalid token introspection = %+v, want inactive", response)
+	}
+}
+
+func TestClientMeController(t *testing.T) {
+	router, deps := newOAuthTestRouter(t)
+
+	get := func(token string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/clients/me", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	recorder := get(issueTestToken(t, router, "orders", "orders-secret", ""))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"name":"orders"`) {
+		t.Errorf("status = %d, body = %s, want the orders client", recorder.Code, recorder.Body)
+	}
+
+	// an admin token is not a client
+	admin, err := deps.Jwt.IssueJwt(&pkgjwt.IssueJwtPayload{Id: 7, Subject: "admin", Lifetime: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorder := get(admin.Token); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("status with an admin token = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+}
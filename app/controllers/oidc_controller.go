@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	apperror "application/app/error"
+	"application/app/services"
+	"application/app/web"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OidcAuthorizeController starts a single sign-on. The client keeps the flow
+// token and sends the browser to the authorization URL.
+func (c *Controller) OidcAuthorizeController(ctx *gin.Context) {
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	authorization, err := service.StartOidcLogin()
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "redirect to the identity provider",
+		Data:    authorization,
+	})
+}
+
+// OidcCallbackController completes a single sign-on with the code and state
+// the identity provider redirected back with.
+func (c *Controller) OidcCallbackController(ctx *gin.Context) {
+	var request web.OidcCallbackRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "oidc login").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	session, challenge, err := service.OidcLogin(&request, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	if challenge != nil {
+		ctx.JSON(http.StatusOK, web.ResponseWeb{
+			Success: true,
+			Message: "two-factor code required",
+			Data:    challenge,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "login success",
+		Data:    session,
+	})
+}
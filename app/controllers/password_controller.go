@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	apperror "application/app/error"
+	"application/app/services"
+	"application/app/web"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForgotPasswordController always answers the same way, whether the account exists or not.
+func (c *Controller) ForgotPasswordController(ctx *gin.Context) {
+	var request web.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "forgot password").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	if err := service.ForgotPassword(&request, ctx.ClientIP()); err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, web.ResponseWeb{
+		Success: true,
+		Message: "if the account exists, a reset link has been sent to its email",
+	})
+}
+
+func (c *Controller) ResetPasswordController(ctx *gin.Context) {
+	var request web.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "reset password").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	if err := service.ResetPassword(&request, ctx.ClientIP()); err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "password reset",
+	})
+}
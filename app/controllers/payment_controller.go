@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	apperror "application/app/error"
+	"application/app/services"
+	"application/app/web"
+	"application/pkg/middleware"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errClientRequired = errors.New("client required")
+
+// PaymentCallbackController accepts the payment status sent by a partner
+// signing its requests.
+func (c *Controller) PaymentCallbackController(ctx *gin.Context) {
+	client, ok := middleware.GetClient(ctx)
+	if !ok {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(errClientRequired, "payment callback").Status(http.StatusUnauthorized))
+		return
+	}
+
+	var request web.PaymentCallbackRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "payment callback").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+	service.ReceivePaymentCallback(client.Name, ctx.ClientIP(), &request)
+
+	ctx.JSON(http.StatusAccepted, web.ResponseWeb{
+		Success: true,
+		Message: "payment callback accepted",
+	})
+}
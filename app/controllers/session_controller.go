@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	apperror "application/app/error"
+	"application/app/services"
+	"application/app/web"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/middleware"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errBearerTokenRequired = errors.New("bearer token required")
+
+func (c *Controller) RefreshTokenController(ctx *gin.Context) {
+	var request web.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "refresh token").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	session, err := service.RefreshSession(request.RefreshToken, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "token refreshed",
+		Data:    session,
+	})
+}
+
+func (c *Controller) ListSessionController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	sessions, err := service.ListSessions(claims.Id, claims.Sid)
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "list sessions",
+		Data:    sessions,
+	})
+}
+
+func (c *Controller) RevokeSessionController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	if err := service.RevokeSession(claims.Id, ctx.Param("sessionId")); err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "session revoked",
+	})
+}
+
+// bearerClaims returns the claims of the bearer token or writes an unauthorized response.
+func (c *Controller) bearerClaims(ctx *gin.Context) (*pkgjwt.JwtResponse, bool) {
+	value, exists := ctx.Get(middleware.BearerToken)
+	if !exists {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(errBearerTokenRequired, "session").Status(http.StatusUnauthorized))
+		return nil, false
+	}
+
+	return value.(*pkgjwt.JwtResponse), true
+}
+
+func (c *Controller) LogoutController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	if err := service.Logout(claims); err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "logged out",
+	})
+}
+
+func (c *Controller) LogoutAllController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	if err := service.RevokeUserTokens(claims.Id, services.RevokeReasonLogoutAll); err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "logged out from all devices",
+	})
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	apperror "application/app/error"
+	"application/app/services"
+	"application/app/web"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (c *Controller) TwoFactorStatusController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	status, err := service.TwoFactorStatus(claims)
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "two-factor status",
+		Data:    status,
+	})
+}
+
+func (c *Controller) EnrollTwoFactorController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	enrollment, err := service.EnrollTwoFactor(claims)
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "scan the qr code and confirm a code to enable two-factor authentication",
+		Data:    enrollment,
+	})
+}
+
+func (c *Controller) ConfirmTwoFactorController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	var request web.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "confirm two-factor").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	codes, err := service.ConfirmTwoFactor(claims, request.Code)
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "two-factor authentication enabled",
+		Data:    codes,
+	})
+}
+
+func (c *Controller) DisableTwoFactorController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	var request web.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "disable two-factor").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	if err := service.DisableTwoFactor(claims, request.Code, ctx.ClientIP()); err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "two-factor authentication disabled",
+	})
+}
+
+func (c *Controller) RegenerateRecoveryCodesController(ctx *gin.Context) {
+	claims, ok := c.bearerClaims(ctx)
+	if !ok {
+		return
+	}
+
+	var request web.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		apperror.ErrorResponse(ctx, apperror.NewErrorTrace(err, "regenerate recovery codes").Status(http.StatusBadRequest))
+		return
+	}
+
+	service := services.NewService(ctx, c.repo, c.cfg, c.deps)
+
+	codes, err := service.RegenerateRecoveryCodes(claims, request.Code, ctx.ClientIP())
+	if err != nil {
+		apperror.ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, web.ResponseWeb{
+		Success: true,
+		Message: "recovery codes regenerated",
+		Data:    codes,
+	})
+}
@@ -0,0 +1,24 @@
+package enums
+
+import "slices"
+
+type CodeAdminRole string
+
+const (
+	AdminRoleSuperAdmin CodeAdminRole = "SUPER_ADMIN"
+	AdminRoleAdmin      CodeAdminRole = "ADMIN"
+	AdminRoleOperator   CodeAdminRole = "OPERATOR"
+	AdminRoleViewer     CodeAdminRole = "VIEWER"
+)
+
+func AdminRoles() []CodeAdminRole {
+	return []CodeAdminRole{AdminRoleSuperAdmin, AdminRoleAdmin, AdminRoleOperator, AdminRoleViewer}
+}
+
+func (r CodeAdminRole) IsValid() bool {
+	return slices.Contains(AdminRoles(), r)
+}
+
+func (r CodeAdminRole) String() string {
+	return string(r)
+}
@@ -0,0 +1,33 @@
+package enums
+
+type CodeAuditEvent string
+
+const (
+	AuditEventLoginSucceeded          CodeAuditEvent = "LOGIN_SUCCEEDED"
+	AuditEventLoginFailed             CodeAuditEvent = "LOGIN_FAILED"
+	AuditEventBasicAuthFailed         CodeAuditEvent = "BASIC_AUTH_FAILED"
+	AuditEventAccountLocked           CodeAuditEvent = "ACCOUNT_LOCKED"
+	AuditEventAccountUnlocked         CodeAuditEvent = "ACCOUNT_UNLOCKED"
+	AuditEventIpAddressLocked         CodeAuditEvent = "IP_ADDRESS_LOCKED"
+	AuditEventLockedLoginDenied       CodeAuditEvent = "LOCKED_LOGIN_DENIED"
+	AuditEventApiKeyCreated           CodeAuditEvent = "API_KEY_CREATED"
+	AuditEventApiKeyRevoked           CodeAuditEvent = "API_KEY_REVOKED"
+	AuditEventMfaEnabled              CodeAuditEvent = "MFA_ENABLED"
+	AuditEventMfaDisabled             CodeAuditEvent = "MFA_DISABLED"
+	AuditEventMfaReset                CodeAuditEvent = "MFA_RESET"
+	AuditEventMfaFailed               CodeAuditEvent = "MFA_FAILED"
+	AuditEventRecoveryCodeUsed        CodeAuditEvent = "RECOVERY_CODE_USED"
+	AuditEventPasswordResetRequested  CodeAuditEvent = "PASSWORD_RESET_REQUESTED"
+	AuditEventPasswordReset           CodeAuditEvent = "PASSWORD_RESET"
+	AuditEventPasswordRehashed        CodeAuditEvent = "PASSWORD_REHASHED"
+	AuditEventOAuthClientFailed       CodeAuditEvent = "OAUTH_CLIENT_FAILED"
+	AuditEventOidcLoginFailed         CodeAuditEvent = "OIDC_LOGIN_FAILED"
+	AuditEventOidcUserProvisioned     CodeAuditEvent = "OIDC_USER_PROVISIONED"
+	AuditEventOidcIdentityLinked      CodeAuditEvent = "OIDC_IDENTITY_LINKED"
+	AuditEventOidcRoleChanged         CodeAuditEvent = "OIDC_ROLE_CHANGED"
+	AuditEventPaymentCallbackReceived CodeAuditEvent = "PAYMENT_CALLBACK_RECEIVED"
+)
+
+func (e CodeAuditEvent) String() string {
+	return string(e)
+}
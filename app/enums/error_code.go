@@ -0,0 +1,86 @@
+package enums
+
+import "net/http"
+
+// CodeError is the machine-readable code of an error response. Clients branch
+// on it rather than on the message, which may change or be translated.
+type CodeError string
+
+const (
+	ErrorCodeBadRequest         CodeError = "BAD_REQUEST"
+	ErrorCodeValidationFailed   CodeError = "VALIDATION_FAILED"
+	ErrorCodeUnauthorized       CodeError = "UNAUTHORIZED"
+	ErrorCodeForbidden          CodeError = "FORBIDDEN"
+	ErrorCodeNotFound           CodeError = "NOT_FOUND"
+	ErrorCodeConflict           CodeError = "CONFLICT"
+	ErrorCodePayloadTooLarge    CodeError = "PAYLOAD_TOO_LARGE"
+	ErrorCodeUnprocessable      CodeError = "UNPROCESSABLE_ENTITY"
+	ErrorCodeAccountLocked      CodeError = "ACCOUNT_LOCKED"
+	ErrorCodeTooManyRequests    CodeError = "TOO_MANY_REQUESTS"
+	ErrorCodeInternal           CodeError = "INTERNAL_ERROR"
+	ErrorCodeBadGateway         CodeError = "BAD_GATEWAY"
+	ErrorCodeServiceUnavailable CodeError = "SERVICE_UNAVAILABLE"
+	ErrorCodeDatabase           CodeError = "DATABASE_ERROR"
+
+	ErrorCodeInvalidCredentials        CodeError = "INVALID_CREDENTIALS"
+	ErrorCodeAuthenticationRequired    CodeError = "AUTHENTICATION_REQUIRED"
+	ErrorCodeAccessDenied              CodeError = "ACCESS_DENIED"
+	ErrorCodeUserNotFound              CodeError = "USER_NOT_FOUND"
+	ErrorCodeInvalidRefreshToken       CodeError = "INVALID_REFRESH_TOKEN"
+	ErrorCodeSessionNotFound           CodeError = "SESSION_NOT_FOUND"
+	ErrorCodeMfaUnavailable            CodeError = "MFA_UNAVAILABLE"
+	ErrorCodeMfaAlreadyEnabled         CodeError = "MFA_ALREADY_ENABLED"
+	ErrorCodeMfaNotEnrolled            CodeError = "MFA_NOT_ENROLLED"
+	ErrorCodeMfaNotEnabled             CodeError = "MFA_NOT_ENABLED"
+	ErrorCodeInvalidMfaCode            CodeError = "INVALID_MFA_CODE"
+	ErrorCodeInvalidMfaToken           CodeError = "INVALID_MFA_TOKEN"
+	ErrorCodePasswordPolicy            CodeError = "PASSWORD_POLICY_VIOLATION"
+	ErrorCodeInvalidPasswordResetToken CodeError = "INVALID_PASSWORD_RESET_TOKEN"
+	ErrorCodePasswordResetUnavailable  CodeError = "PASSWORD_RESET_UNAVAILABLE"
+	ErrorCodeApiKeyNotFound            CodeError = "API_KEY_NOT_FOUND"
+	ErrorCodeInvalidRateLimitTier      CodeError = "INVALID_RATE_LIMIT_TIER"
+	ErrorCodeApiKeyExpirationPassed    CodeError = "API_KEY_EXPIRATION_PASSED"
+	ErrorCodeSsoUnavailable            CodeError = "SSO_UNAVAILABLE"
+	ErrorCodeInvalidSsoFlow            CodeError = "INVALID_SSO_FLOW"
+	ErrorCodeSsoFailed                 CodeError = "SSO_FAILED"
+	ErrorCodeSsoUnknownUser            CodeError = "SSO_UNKNOWN_USER"
+	ErrorCodeSsoNoRole                 CodeError = "SSO_NO_ROLE"
+	ErrorCodeSsoEmailConflict          CodeError = "SSO_EMAIL_CONFLICT"
+	ErrorCodeAccountDisabled           CodeError = "ACCOUNT_DISABLED"
+)
+
+// ErrorCodeForStatus is the code of an error that was given none.
+func ErrorCodeForStatus(status int) CodeError {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorCodeBadRequest
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrorCodeForbidden
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusConflict:
+		return ErrorCodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return ErrorCodePayloadTooLarge
+	case http.StatusUnprocessableEntity:
+		return ErrorCodeUnprocessable
+	case http.StatusTooManyRequests:
+		return ErrorCodeTooManyRequests
+	case http.StatusBadGateway:
+		return ErrorCodeBadGateway
+	case http.StatusServiceUnavailable:
+		return ErrorCodeServiceUnavailable
+	}
+
+	if status >= http.StatusBadRequest && status < http.StatusInternalServerError {
+		return ErrorCodeBadRequest
+	}
+
+	return ErrorCodeInternal
+}
+
+func (c CodeError) String() string {
+	return string(c)
+}
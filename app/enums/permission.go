@@ -0,0 +1,32 @@
+package enums
+
+// CodePermission is a "resource:action" permission granted to roles.
+type CodePermission string
+
+const (
+	PermissionAll            CodePermission = "*"
+	PermissionUsersRead      CodePermission = "users:read"
+	PermissionUsersWrite     CodePermission = "users:write"
+	PermissionRolesRead      CodePermission = "roles:read"
+	PermissionRolesWrite     CodePermission = "roles:write"
+	PermissionSessionsRevoke CodePermission = "sessions:revoke"
+	PermissionApiKeysRead    CodePermission = "api_keys:read"
+	PermissionApiKeysWrite   CodePermission = "api_keys:write"
+)
+
+func Permissions() []CodePermission {
+	return []CodePermission{
+		PermissionAll,
+		PermissionUsersRead,
+		PermissionUsersWrite,
+		PermissionRolesRead,
+		PermissionRolesWrite,
+		PermissionSessionsRevoke,
+		PermissionApiKeysRead,
+		PermissionApiKeysWrite,
+	}
+}
+
+func (p CodePermission) String() string {
+	return string(p)
+}
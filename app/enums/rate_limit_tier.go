@@ -0,0 +1,26 @@
+package enums
+
+import "slices"
+
+// CodeRateLimitTier is the request budget of an API key. It is recorded for a
+// gateway in front of the API; the API itself does not enforce it.
+type CodeRateLimitTier string
+
+const (
+	RateLimitTierBasic     CodeRateLimitTier = "BASIC"
+	RateLimitTierStandard  CodeRateLimitTier = "STANDARD"
+	RateLimitTierPremium   CodeRateLimitTier = "PREMIUM"
+	RateLimitTierUnlimited CodeRateLimitTier = "UNLIMITED"
+)
+
+func RateLimitTiers() []CodeRateLimitTier {
+	return []CodeRateLimitTier{RateLimitTierBasic, RateLimitTierStandard, RateLimitTierPremium, RateLimitTierUnlimited}
+}
+
+func (t CodeRateLimitTier) IsValid() bool {
+	return slices.Contains(RateLimitTiers(), t)
+}
+
+func (t CodeRateLimitTier) String() string {
+	return string(t)
+}
@@ -0,0 +1,91 @@
+package error
+
+//go:generate go run ../../cmd/errordoc -out ../../ERRORS.md
+
+import (
+	"application/app/enums"
+	"application/pkg/i18n"
+	"net/http"
+)
+
+// CatalogEntry is the message of an error code in every supported language.
+// Templates may use {name} placeholders filled from ErrorTrace.Params.
+type CatalogEntry struct {
+	Code   enums.CodeError
+	Status int
+	En     string
+	Id     string
+}
+
+// Message renders the template of the locale, English when it has none.
+func (e CatalogEntry) Message(locale string, params map[string]any) string {
+	template := e.En
+	if locale == i18n.Indonesian && e.Id != "" {
+		template = e.Id
+	}
+
+	return i18n.Render(template, params)
+}
+
+// catalog lists every error code sent to clients, in the order of ERRORS.md.
+var catalog = []CatalogEntry{
+	{enums.ErrorCodeBadRequest, http.StatusBadRequest, "the request is invalid", "permintaan tidak valid"},
+	{enums.ErrorCodeValidationFailed, http.StatusBadRequest, "request validation failed", "validasi permintaan gagal"},
+	{enums.ErrorCodeUnauthorized, http.StatusUnauthorized, "unauthorized", "tidak terautentikasi"},
+	{enums.ErrorCodeForbidden, http.StatusForbidden, "forbidden", "akses ditolak"},
+	{enums.ErrorCodeNotFound, http.StatusNotFound, "resource not found", "data tidak ditemukan"},
+	{enums.ErrorCodeConflict, http.StatusConflict, "the resource already exists or changed", "data sudah ada atau telah berubah"},
+	{enums.ErrorCodePayloadTooLarge, http.StatusRequestEntityTooLarge, "the request body is too large", "isi permintaan terlalu besar"},
+	{enums.ErrorCodeUnprocessable, http.StatusUnprocessableEntity, "the request cannot be processed", "permintaan tidak dapat diproses"},
+	{enums.ErrorCodeTooManyRequests, http.StatusTooManyRequests, "too many requests, try again later", "terlalu banyak permintaan, coba lagi nanti"},
+	{enums.ErrorCodeInternal, http.StatusInternalServerError, "internal server error", "terjadi kesalahan pada server"},
+	{enums.ErrorCodeDatabase, http.StatusInternalServerError, "internal server error", "terjadi kesalahan pada server"},
+	{enums.ErrorCodeBadGateway, http.StatusBadGateway, "an upstream service failed", "layanan eksternal gagal merespons"},
+	{enums.ErrorCodeServiceUnavailable, http.StatusServiceUnavailable, "the service is unavailable", "layanan tidak tersedia"},
+
+	{enums.ErrorCodeAuthenticationRequired, http.StatusUnauthorized, "authentication required", "autentikasi diperlukan"},
+	{enums.ErrorCodeAccessDenied, http.StatusForbidden, "you are not allowed to do this", "anda tidak memiliki izin untuk melakukan ini"},
+	{enums.ErrorCodeInvalidCredentials, http.StatusUnauthorized, "invalid username or password", "nama pengguna atau kata sandi salah"},
+	{enums.ErrorCodeAccountLocked, http.StatusTooManyRequests, "too many failed attempts, try again in {retryAfter} seconds", "terlalu banyak percobaan gagal, coba lagi dalam {retryAfter} detik"},
+	{enums.ErrorCodeAccountDisabled, http.StatusForbidden, "the account is disabled", "akun dinonaktifkan"},
+	{enums.ErrorCodeUserNotFound, http.StatusNotFound, "user not found", "pengguna tidak ditemukan"},
+	{enums.ErrorCodeInvalidRefreshToken, http.StatusUnauthorized, "invalid refresh token", "refresh token tidak valid"},
+	{enums.ErrorCodeSessionNotFound, http.StatusNotFound, "session not found", "sesi tidak ditemukan"},
+	{enums.ErrorCodeMfaUnavailable, http.StatusServiceUnavailable, "two-factor authentication is not configured", "autentikasi dua faktor belum dikonfigurasi"},
+	{enums.ErrorCodeMfaAlreadyEnabled, http.StatusConflict, "two-factor authentication is already enabled", "autentikasi dua faktor sudah aktif"},
+	{enums.ErrorCodeMfaNotEnrolled, http.StatusBadRequest, "two-factor authentication is not enrolled", "autentikasi dua faktor belum didaftarkan"},
+	{enums.ErrorCodeMfaNotEnabled, http.StatusBadRequest, "two-factor authentication is not enabled", "autentikasi dua faktor belum aktif"},
+	{enums.ErrorCodeInvalidMfaCode, http.StatusUnauthorized, "invalid two-factor code", "kode dua faktor salah"},
+	{enums.ErrorCodeInvalidMfaToken, http.StatusUnauthorized, "invalid or expired mfa token", "token mfa tidak valid atau kedaluwarsa"},
+	{enums.ErrorCodePasswordPolicy, http.StatusBadRequest, "the password does not meet the policy", "kata sandi tidak memenuhi kebijakan"},
+	{enums.ErrorCodeInvalidPasswordResetToken, http.StatusBadRequest, "invalid or expired password reset token", "token reset kata sandi tidak valid atau kedaluwarsa"},
+	{enums.ErrorCodePasswordResetUnavailable, http.StatusServiceUnavailable, "password reset is not configured", "reset kata sandi belum dikonfigurasi"},
+	{enums.ErrorCodeApiKeyNotFound, http.StatusNotFound, "api key not found", "api key tidak ditemukan"},
+	{enums.ErrorCodeInvalidRateLimitTier, http.StatusBadRequest, "invalid rate limit tier", "tingkat rate limit tidak valid"},
+	{enums.ErrorCodeApiKeyExpirationPassed, http.StatusBadRequest, "api key expiration must be in the future", "masa berlaku api key harus di masa depan"},
+	{enums.ErrorCodeSsoUnavailable, http.StatusServiceUnavailable, "single sign-on is not configured", "single sign-on belum dikonfigurasi"},
+	{enums.ErrorCodeInvalidSsoFlow, http.StatusBadRequest, "invalid or expired single sign-on attempt", "percobaan single sign-on tidak valid atau kedaluwarsa"},
+	{enums.ErrorCodeSsoFailed, http.StatusUnauthorized, "single sign-on failed", "single sign-on gagal"},
+	{enums.ErrorCodeSsoUnknownUser, http.StatusForbidden, "no admin account is linked to this identity", "tidak ada akun admin yang terhubung dengan identitas ini"},
+	{enums.ErrorCodeSsoNoRole, http.StatusForbidden, "the identity provider grants no admin role", "penyedia identitas tidak memberikan peran admin"},
+	{enums.ErrorCodeSsoEmailConflict, http.StatusConflict, "an admin with this email already exists", "admin dengan email ini sudah ada"},
+}
+
+var catalogIndex = func() map[enums.CodeError]CatalogEntry {
+	index := make(map[enums.CodeError]CatalogEntry, len(catalog))
+	for _, entry := range catalog {
+		index[entry.Code] = entry
+	}
+	return index
+}()
+
+// Catalog returns every entry of the error catalog.
+func Catalog() []CatalogEntry {
+	return append([]CatalogEntry(nil), catalog...)
+}
+
+// Lookup returns the catalog entry of a code.
+func Lookup(code enums.CodeError) (CatalogEntry, bool) {
+	entry, ok := catalogIndex[code]
+	return entry, ok
+}
@@ -0,0 +1,95 @@
+package error
+
+import (
+	"application/app/enums"
+	"application/app/web"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	seen := make(map[enums.CodeError]bool)
+	for _, entry := range Catalog() {
+		if seen[entry.Code] {
+			t.Errorf("code %s is listed twice", entry.Code)
+		}
+		seen[entry.Code] = true
+
+		if entry.En == "" || entry.Id == "" {
+			t.Errorf("code %s misses a translation", entry.Code)
+		}
+		if entry.Status < http.StatusBadRequest {
+			t.Errorf("code %s has status %d", entry.Code, entry.Status)
+		}
+	}
+
+	// every status maps to a code of the catalog
+	for status := http.StatusBadRequest; status <= http.StatusNetworkAuthenticationRequired; status++ {
+		if code := enums.ErrorCodeForStatus(status); !seen[code] {
+			t.Errorf("ErrorCodeForStatus(%d) = %s, not in the catalog", status, code)
+		}
+	}
+}
+
+func TestErrorResponseIsLocalized(t *testing.T) {
+	errLocked := New(enums.ErrorCodeAccountLocked, "too many failed attempts")
+
+	tests := []struct {
+		name        string
+		err         error
+		header      http.Header
+		wantMessage string
+		wantCode    enums.CodeError
+	}{
+		{
+			name:        "coded error in English",
+			err:         NewErrorTrace(New(enums.ErrorCodeUserNotFound, "user 42 not found"), "find user").Status(http.StatusNotFound),
+			wantMessage: "user not found",
+			wantCode:    enums.ErrorCodeUserNotFound,
+		},
+		{
+			name:        "coded error in Indonesian",
+			err:         NewErrorTrace(New(enums.ErrorCodeUserNotFound, "user 42 not found"), "find user").Status(http.StatusNotFound),
+			header:      http.Header{"Accept-Language": {"id-ID,id;q=0.9,en;q=0.8"}},
+			wantMessage: "pengguna tidak ditemukan",
+			wantCode:    enums.ErrorCodeUserNotFound,
+		},
+		{
+			name:        "wrapped coded error",
+			err:         NewErrorTrace(fmt.Errorf("login: %w", errLocked), "login").Status(http.StatusTooManyRequests).WithParams(map[string]any{"retryAfter": 30}),
+			header:      http.Header{"Accept-Language": {"id"}},
+			wantMessage: "terlalu banyak percobaan gagal, coba lagi dalam 30 detik",
+			wantCode:    enums.ErrorCodeAccountLocked,
+		},
+		{
+			name:        "client error without a code keeps its message",
+			err:         NewErrorTrace(errors.New("order is already paid"), "pay order").Status(http.StatusConflict),
+			header:      http.Header{"Accept-Language": {"id"}},
+			wantMessage: "order is already paid",
+			wantCode:    enums.ErrorCodeConflict,
+		},
+		{
+			name:        "server error hides its message",
+			err:         NewErrorTrace(errors.New("pq: connection refused"), "find user"),
+			header:      http.Header{"Accept-Language": {"id"}},
+			wantMessage: "terjadi kesalahan pada server",
+			wantCode:    enums.ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response web.ResponseWeb
+			if err := json.Unmarshal(respond(t, tt.err, tt.header).Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+
+			if response.Message != tt.wantMessage || response.Code != tt.wantCode.String() {
+				t.Errorf("response = %+v, want %q %q", response, tt.wantMessage, tt.wantCode)
+			}
+		})
+	}
+}
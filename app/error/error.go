@@ -1,22 +1,58 @@
 package error
 
 import (
+	"application/app/enums"
 	"application/app/web"
+	"application/pkg/i18n"
+	"application/pkg/requestid"
+	"application/pkg/validation"
 	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 )
 
+// internalErrorMessage replaces the message of errors that are not an
+// ErrorTrace, which may carry details not meant for clients.
+const internalErrorMessage = "internal server error"
+
 // ErrorTrace represents a detailed error trace with context and status code
 type ErrorTrace struct {
-	Err        error  // Original error
-	Message    string // Human-readable message
-	Context    string // Where the error occurred
-	StatusCode int    // HTTP status code
-	Details    any    // Additional error details
+	Err        error           // Original error
+	Message    string          // Human-readable message
+	Context    string          // Where the error occurred
+	StatusCode int             // HTTP status code
+	Code       enums.CodeError // Machine-readable code, derived from the error or the status code when empty
+	Params     map[string]any  // Values of the placeholders of the catalog message
+	Details    any             // Additional error details
+}
+
+// CodedError is a sentinel error carrying its code of the catalog. Services
+// declare their errors with New so that the code follows them through wrapping.
+type CodedError struct {
+	Code    enums.CodeError
+	Message string
+}
+
+// New creates a sentinel error with a code of the catalog.
+func New(code enums.CodeError, message string) *CodedError {
+	return &CodedError{Code: code, Message: message}
+}
+
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+func (e *CodedError) ErrorCode() enums.CodeError {
+	return e.Code
+}
+
+// coder is implemented by errors that know their code, such as CodedError
+// and repositories.Error.
+type coder interface {
+	ErrorCode() enums.CodeError
 }
 
 // NewErrorTrace creates a new ErrorTrace with context
@@ -48,42 +84,138 @@ func (e *ErrorTrace) Unwrap() error {
 	return e.Err
 }
 
-// WithStatusCode sets the HTTP status code and returns the ErrorTrace for chaining
+// Status sets the HTTP status code and returns the ErrorTrace for chaining
 func (e *ErrorTrace) Status(code int) *ErrorTrace {
 	e.StatusCode = code
 	return e
 }
 
+// WithCode sets the machine-readable code and returns the ErrorTrace for chaining
+func (e *ErrorTrace) WithCode(code enums.CodeError) *ErrorTrace {
+	e.Code = code
+	return e
+}
+
+// WithParams sets the values of the placeholders of the catalog message and returns the ErrorTrace for chaining
+func (e *ErrorTrace) WithParams(params map[string]any) *ErrorTrace {
+	e.Params = params
+	return e
+}
+
 // WithDetails adds additional error details and returns the ErrorTrace for chaining
 func (e *ErrorTrace) WithDetails(details any) *ErrorTrace {
 	e.Details = details
 	return e
 }
 
-// ErrorResponse handles error responses in a consistent way
-func ErrorResponse(ctx *gin.Context, err error) {
-	// Log the error with context
-	log.Error().Err(err).Msg("request error")
+// ErrorCode returns the code sent to the client.
+func (e *ErrorTrace) ErrorCode() enums.CodeError {
+	if code, ok := e.ownCode(); ok {
+		return code
+	}
+
+	return enums.ErrorCodeForStatus(e.StatusCode)
+}
+
+// ownCode is the code set on the trace or carried by the error.
+func (e *ErrorTrace) ownCode() (enums.CodeError, bool) {
+	if e.Code != "" {
+		return e.Code, true
+	}
+
+	var coded coder
+	if errors.As(e.Err, &coded) {
+		return coded.ErrorCode(), true
+	}
+
+	return "", false
+}
 
+// localize returns the trace as reported to the client. Errors with their own
+// code, and server errors, get the message of the catalog in the language of
+// the client so that internal details never leak. A binding error of a bad
+// request is reported per field under the errors detail.
+func localize(ctx *gin.Context, trace *ErrorTrace) *ErrorTrace {
+	locale := i18n.FromContext(ctx)
+	reported := *trace
+
+	if trace.StatusCode == http.StatusBadRequest && trace.Details == nil {
+		if fields, ok := validation.FieldErrors(trace.Err, locale); ok {
+			reported.Details = map[string]any{"errors": fields}
+			if reported.Code == "" {
+				reported.Code = enums.ErrorCodeValidationFailed
+			}
+		}
+	}
+
+	code, coded := reported.ownCode()
+	if !coded && reported.StatusCode < http.StatusInternalServerError {
+		return &reported
+	}
+	if !coded {
+		code = reported.ErrorCode()
+	}
+
+	if entry, ok := Lookup(code); ok {
+		reported.Message = entry.Message(locale, reported.Params)
+	}
+
+	return &reported
+}
+
+// AsErrorTrace returns the ErrorTrace of err. Any other error becomes an
+// internal server error whose message is not disclosed.
+func AsErrorTrace(err error) *ErrorTrace {
 	var trace *ErrorTrace
 	if errors.As(err, &trace) {
-		response := web.ResponseWeb{
-			Success: false,
-			Message: trace.Err.Error(),
-		}
+		return trace
+	}
 
-		// Include details if they exist
-		if trace.Details != nil {
-			response.Data = trace.Details
-		}
+	if err == nil {
+		err = errors.New(internalErrorMessage)
+	}
 
-		ctx.JSON(trace.StatusCode, response)
+	return &ErrorTrace{
+		Err:        err,
+		Message:    internalErrorMessage,
+		StatusCode: http.StatusInternalServerError,
+		Code:       enums.ErrorCodeInternal,
+	}
+}
+
+// ErrorResponse handles error responses in a consistent way. It is called by
+// the controllers and by the error middleware for errors added with ctx.Error.
+// Clients asking for application/problem+json get RFC 7807 problem details.
+func ErrorResponse(ctx *gin.Context, err error) {
+	trace := localize(ctx, AsErrorTrace(err))
+
+	// Log the error with context
+	level := zerolog.WarnLevel
+	if trace.StatusCode >= http.StatusInternalServerError {
+		level = zerolog.ErrorLevel
+	}
+	requestid.Logger(ctx).WithLevel(level).Err(err).
+		Int("status", trace.StatusCode).
+		Str("code", trace.ErrorCode().String()).
+		Str("path", ctx.Request.URL.Path).
+		Msg("request error")
+
+	if wantsProblem(ctx) {
+		ctx.Header("Content-Type", ProblemContentType)
+		ctx.AbortWithStatusJSON(trace.StatusCode, NewProblem(ctx, trace))
 		return
 	}
 
-	// Fallback for non-ErrorTrace errors
-	ctx.JSON(http.StatusInternalServerError, web.ResponseWeb{
+	response := web.ResponseWeb{
 		Success: false,
-		Message: trace.Err.Error(),
-	})
+		Message: trace.Message,
+		Code:    trace.ErrorCode().String(),
+	}
+
+	// Include details if they exist
+	if trace.Details != nil {
+		response.Data = trace.Details
+	}
+
+	ctx.AbortWithStatusJSON(trace.StatusCode, response)
 }
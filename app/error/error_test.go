@@ -0,0 +1,139 @@
+package error
+
+import (
+	"application/app/enums"
+	"application/app/web"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respond answers a request to /orders with ErrorResponse of err.
+func respond(t *testing.T, err error, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/orders", func(ctx *gin.Context) {
+		ErrorResponse(ctx, err)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestErrorTraceErrorCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		trace *ErrorTrace
+		want  enums.CodeError
+	}{
+		{name: "default status", trace: NewErrorTrace(errors.New("boom"), "test"), want: enums.ErrorCodeInternal},
+		{name: "status", trace: NewErrorTrace(errors.New("missing"), "test").Status(http.StatusNotFound), want: enums.ErrorCodeNotFound},
+		{name: "other client status", trace: NewErrorTrace(errors.New("gone"), "test").Status(http.StatusGone), want: enums.ErrorCodeBadRequest},
+		{name: "explicit code", trace: NewErrorTrace(errors.New("locked"), "test").Status(http.StatusTooManyRequests).WithCode(enums.ErrorCodeAccountLocked), want: enums.ErrorCodeAccountLocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.trace.ErrorCode(); got != tt.want {
+				t.Errorf("ErrorCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorTraceWrapsTheError(t *testing.T) {
+	cause := errors.New("record not found")
+	trace := NewErrorTrace(cause, "find user").Status(http.StatusNotFound)
+
+	if !errors.Is(trace, cause) {
+		t.Error("errors.Is(trace, cause) = false, want true")
+	}
+	if got, want := trace.Error(), "find user: record not found"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	wrapped := errors.Join(errors.New("outer"), trace)
+	if got := AsErrorTrace(wrapped); got != trace {
+		t.Errorf("AsErrorTrace() = %v, want the wrapped trace", got)
+	}
+}
+
+func TestAsErrorTraceHidesOtherErrors(t *testing.T) {
+	trace := AsErrorTrace(errors.New("pq: connection refused"))
+
+	if trace.StatusCode != http.StatusInternalServerError || trace.Message != internalErrorMessage || trace.ErrorCode() != enums.ErrorCodeInternal {
+		t.Errorf("AsErrorTrace() = %+v, want a generic internal error", trace)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+		wantCode    enums.CodeError
+	}{
+		{
+			name:        "error trace",
+			err:         NewErrorTrace(errors.New("order is already paid"), "pay order").Status(http.StatusConflict),
+			wantStatus:  http.StatusConflict,
+			wantMessage: "order is already paid",
+			wantCode:    enums.ErrorCodeConflict,
+		},
+		{
+			name:        "other error",
+			err:         errors.New("pq: connection refused"),
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: internalErrorMessage,
+			wantCode:    enums.ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := respond(t, tt.err, nil)
+
+			var response web.ResponseWeb
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if response.Success || response.Message != tt.wantMessage || response.Code != tt.wantCode.String() {
+				t.Errorf("response = %+v, want message %q and code %q", response, tt.wantMessage, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestErrorResponseDetails(t *testing.T) {
+	trace := NewErrorTrace(errors.New("order is closed"), "pay order").Status(http.StatusConflict).
+		WithDetails(map[string]any{"orderId": "42"})
+
+	var response struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(respond(t, trace, nil).Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Data["orderId"] != "42" {
+		t.Errorf("data = %v, want the details", response.Data)
+	}
+}
@@ -0,0 +1,184 @@
+package error
+
+import (
+	"application/pkg/requestid"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+	jsonContentType    = "application/json"
+
+	// ProblemTypeBlank is the type of problems without a documentation page, see RFC 7807 section 4.2.
+	ProblemTypeBlank = "about:blank"
+
+	// RequestIdHeader carries the id of the request, reported as traceId.
+	RequestIdHeader = requestid.Header
+)
+
+// ProblemConfig selects when errors are answered as RFC 7807 problem details.
+type ProblemConfig struct {
+	// Always answers every error as problem details, regardless of the Accept header.
+	Always bool
+	// TypeBaseUrl prefixes the code of the error to build the type URI, e.g.
+	// https://docs.example.com/errors/ gives https://docs.example.com/errors/not-found.
+	// Without it the type is about:blank.
+	TypeBaseUrl string
+}
+
+var (
+	problemConfig   ProblemConfig
+	problemConfigMu sync.RWMutex
+)
+
+// SetProblemConfig changes when and how problem details are answered.
+func SetProblemConfig(config ProblemConfig) {
+	problemConfigMu.Lock()
+	defer problemConfigMu.Unlock()
+
+	problemConfig = config
+}
+
+func currentProblemConfig() ProblemConfig {
+	problemConfigMu.RLock()
+	defer problemConfigMu.RUnlock()
+
+	return problemConfig
+}
+
+// Problem is an RFC 7807 problem details object. Extensions are written as
+// top-level members next to the standard ones.
+type Problem struct {
+	Type       string         `json:"type"`
+	Title      string         `json:"title"`
+	Status     int            `json:"status"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Code       string         `json:"code"`
+	TraceId    string         `json:"traceId,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+
+	standard, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return standard, err
+	}
+
+	merged := make(map[string]any, len(p.Extensions)+8)
+	for name, value := range p.Extensions {
+		merged[name] = value
+	}
+
+	// the standard members win over extensions of the same name
+	var members map[string]any
+	if err := json.Unmarshal(standard, &members); err != nil {
+		return nil, err
+	}
+	for name, value := range members {
+		merged[name] = value
+	}
+
+	return json.Marshal(merged)
+}
+
+// NewProblem builds the problem details of an error trace. Details that are
+// a JSON object become extension members, other details are kept under details.
+func NewProblem(ctx *gin.Context, trace *ErrorTrace) Problem {
+	code := trace.ErrorCode().String()
+
+	problem := Problem{
+		Type:     ProblemTypeBlank,
+		Title:    http.StatusText(trace.StatusCode),
+		Status:   trace.StatusCode,
+		Detail:   trace.Message,
+		Instance: ctx.Request.URL.Path,
+		Code:     code,
+		TraceId:  RequestId(ctx),
+	}
+
+	if base := currentProblemConfig().TypeBaseUrl; base != "" {
+		problem.Type = base + strings.ReplaceAll(strings.ToLower(code), "_", "-")
+	}
+
+	if trace.Details != nil {
+		problem.Extensions = extensionMembers(trace.Details)
+	}
+
+	return problem
+}
+
+func extensionMembers(details any) map[string]any {
+	payload, err := json.Marshal(details)
+	if err != nil {
+		return nil
+	}
+
+	var members map[string]any
+	if err := json.Unmarshal(payload, &members); err != nil {
+		return map[string]any{"details": details}
+	}
+
+	return members
+}
+
+// wantsProblem tells whether the error is answered as problem details: always
+// when configured, otherwise when the client prefers application/problem+json
+// over application/json.
+func wantsProblem(ctx *gin.Context) bool {
+	if currentProblemConfig().Always {
+		return true
+	}
+
+	accept := ctx.GetHeader("Accept")
+	if accept == "" {
+		return false
+	}
+
+	problem, plain := 0.0, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case ProblemContentType:
+			problem = max(problem, quality)
+		case jsonContentType:
+			plain = max(plain, quality)
+		}
+	}
+
+	return problem > 0 && problem >= plain
+}
+
+// RequestId returns the id the request middleware stored in the context, or
+// the one the client sent.
+func RequestId(ctx *gin.Context) string {
+	if id := requestid.FromContext(ctx.Request.Context()); id != "" {
+		return id
+	}
+
+	if id := ctx.Writer.Header().Get(RequestIdHeader); id != "" {
+		return id
+	}
+
+	return ctx.GetHeader(RequestIdHeader)
+}
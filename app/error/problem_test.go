@@ -0,0 +1,155 @@
+package error
+
+import (
+	"application/pkg/requestid"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setProblemConfig(t *testing.T, config ProblemConfig) {
+	t.Helper()
+
+	SetProblemConfig(config)
+	t.Cleanup(func() { SetProblemConfig(ProblemConfig{}) })
+}
+
+func TestErrorResponseNegotiatesProblems(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		always bool
+		want   bool
+	}{
+		{name: "no accept", want: false},
+		{name: "json", accept: "application/json", want: false},
+		{name: "problem", accept: "application/problem+json", want: true},
+		{name: "problem preferred", accept: "application/json;q=0.5, application/problem+json", want: true},
+		{name: "json preferred", accept: "application/problem+json;q=0.4, application/json", want: false},
+		{name: "same quality", accept: "application/json, application/problem+json", want: true},
+		{name: "problem refused", accept: "application/problem+json;q=0", want: false},
+		{name: "invalid quality", accept: "application/problem+json;q=high", want: false},
+		{name: "always", accept: "application/json", always: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setProblemConfig(t, ProblemConfig{Always: tt.always})
+
+			header := http.Header{}
+			if tt.accept != "" {
+				header.Set("Accept", tt.accept)
+			}
+
+			recorder := respond(t, NewErrorTrace(errors.New("order not found"), "find order").Status(http.StatusNotFound), header)
+
+			if got := recorder.Header().Get("Content-Type") == ProblemContentType; got != tt.want {
+				t.Errorf("problem response = %v (%s), want %v", got, recorder.Header().Get("Content-Type"), tt.want)
+			}
+		})
+	}
+}
+
+func TestProblem(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ProblemConfig
+		trace   *ErrorTrace
+		want    map[string]any
+		missing []string
+	}{
+		{
+			name:  "blank type",
+			trace: NewErrorTrace(errors.New("order not found"), "find order").Status(http.StatusNotFound),
+			want: map[string]any{
+				"type":     ProblemTypeBlank,
+				"title":    "Not Found",
+				"status":   float64(http.StatusNotFound),
+				"detail":   "order not found",
+				"instance": "/orders",
+				"code":     "NOT_FOUND",
+				"traceId":  "request-1",
+			},
+		},
+		{
+			name:   "type from the code",
+			config: ProblemConfig{TypeBaseUrl: "https://docs.example.com/errors/"},
+			trace:  NewErrorTrace(errors.New("order changed"), "pay order").Status(http.StatusUnprocessableEntity),
+			want: map[string]any{
+				"type": "https://docs.example.com/errors/unprocessable-entity",
+				"code": "UNPROCESSABLE_ENTITY",
+			},
+		},
+		{
+			name:  "object details are members",
+			trace: NewErrorTrace(errors.New("order is closed"), "pay order").Status(http.StatusConflict).WithDetails(map[string]any{"orderId": "42", "status": "closed"}),
+			want: map[string]any{
+				"orderId": "42",
+				// the standard member wins
+				"status": float64(http.StatusConflict),
+			},
+		},
+		{
+			name:  "other details",
+			trace: NewErrorTrace(errors.New("orders are closed"), "pay orders").Status(http.StatusConflict).WithDetails([]string{"41", "42"}),
+			want: map[string]any{
+				"details": []any{"41", "42"},
+			},
+			missing: []string{"data"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setProblemConfig(t, tt.config)
+
+			header := http.Header{}
+			header.Set("Accept", ProblemContentType)
+			header.Set(RequestIdHeader, "request-1")
+
+			recorder := respond(t, tt.trace, header)
+
+			var problem map[string]any
+			if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+
+			if recorder.Code != tt.trace.StatusCode {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.trace.StatusCode)
+			}
+			for name, value := range tt.want {
+				got, _ := json.Marshal(problem[name])
+				want, _ := json.Marshal(value)
+				if string(got) != string(want) {
+					t.Errorf("problem %s = %s, want %s", name, got, want)
+				}
+			}
+			for _, name := range tt.missing {
+				if _, ok := problem[name]; ok {
+					t.Errorf("problem has %s", name)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestIdPrefersTheContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/orders", nil)
+	ctx.Request.Header.Set(RequestIdHeader, "sent-by-the-client")
+
+	if got := RequestId(ctx); got != "sent-by-the-client" {
+		t.Errorf("RequestId() = %q, want the header", got)
+	}
+
+	ctx.Request = ctx.Request.WithContext(requestid.NewContext(ctx.Request.Context(), "request-1"))
+	if got := RequestId(ctx); got != "request-1" {
+		t.Errorf("RequestId() = %q, want the id of the context", got)
+	}
+}
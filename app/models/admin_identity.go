@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// AdminIdentity links an admin user to the subject of an external identity
+// provider. Provisioned is set when the admin was created by its first login,
+// its role then follows the identity provider.
+type AdminIdentity struct {
+	Id          int64      `gorm:"column:id;primaryKey"`
+	UserId      int64      `gorm:"column:user_id"`
+	Issuer      string     `gorm:"column:issuer"`
+	Subject     string     `gorm:"column:subject"`
+	Email       string     `gorm:"column:email"`
+	Provisioned bool       `gorm:"column:provisioned"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (AdminIdentity) TableName() string {
+	return "admin_identities"
+}
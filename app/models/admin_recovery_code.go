@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type AdminRecoveryCode struct {
+	Id        int64      `gorm:"column:id;primaryKey"`
+	UserId    int64      `gorm:"column:user_id"`
+	CodeHash  string     `gorm:"column:code_hash"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (AdminRecoveryCode) TableName() string {
+	return "admin_recovery_codes"
+}
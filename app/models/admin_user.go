@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AdminUser struct {
+	Id          int64      `gorm:"column:id;primaryKey"`
+	Username    string     `gorm:"column:username"`
+	Email       string     `gorm:"column:email"`
+	Name        string     `gorm:"column:name"`
+	Password    string     `gorm:"column:password"`
+	Role        string     `gorm:"column:role"`
+	IsActive    bool       `gorm:"column:is_active"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at"`
+	// Locale is the language of the messages sent to the admin, empty to
+	// follow the request.
+	Locale string `gorm:"column:locale"`
+	// TotpSecret is encrypted. It is pending until TotpEnabledAt is set.
+	TotpSecret       *string        `gorm:"column:totp_secret"`
+	TotpEnabledAt    *time.Time     `gorm:"column:totp_enabled_at"`
+	TotpLastUsedStep int64          `gorm:"column:totp_last_used_step"`
+	CreatedAt        time.Time      `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time      `gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt        gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+func (AdminUser) TableName() string {
+	return "admin_users"
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ApiKey is a long-lived partner credential. Only the lookup prefix and the
+// SHA-256 hash of the key are stored. The rate limit tier is metadata, no limit
+// is enforced by it.
+type ApiKey struct {
+	Id            int64      `gorm:"column:id;primaryKey"`
+	Name          string     `gorm:"column:name"`
+	Prefix        string     `gorm:"column:prefix"`
+	KeyHash       string     `gorm:"column:key_hash"`
+	Scopes        StringList `gorm:"column:scopes;type:jsonb"`
+	RateLimitTier string     `gorm:"column:rate_limit_tier"`
+	ExpiresAt     *time.Time `gorm:"column:expires_at"`
+	LastUsedAt    *time.Time `gorm:"column:last_used_at"`
+	RevokedAt     *time.Time `gorm:"column:revoked_at"`
+	CreatedBy     *int64     `gorm:"column:created_by"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (ApiKey) TableName() string {
+	return "api_keys"
+}
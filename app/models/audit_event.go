@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type AuditEvent struct {
+	Id        int64     `gorm:"column:id;primaryKey"`
+	Event     string    `gorm:"column:event"`
+	Actor     *string   `gorm:"column:actor"`
+	IpAddress *string   `gorm:"column:ip_address"`
+	Metadata  *string   `gorm:"column:metadata;type:jsonb"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
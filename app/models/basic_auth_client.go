@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// BasicAuthClient is a service allowed to call the API with basic auth.
+type BasicAuthClient struct {
+	Id         int64      `gorm:"column:id;primaryKey"`
+	Name       string     `gorm:"column:name"`
+	SecretHash string     `gorm:"column:secret_hash"`
+	Scopes     StringList `gorm:"column:scopes;type:jsonb"`
+	Routes     StringList `gorm:"column:routes;type:jsonb"`
+	IsEnabled  bool       `gorm:"column:is_enabled"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (BasicAuthClient) TableName() string {
+	return "basic_auth_clients"
+}
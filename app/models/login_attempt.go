@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// LoginAttempt counts the consecutive failed logins of an account or an IP address.
+type LoginAttempt struct {
+	Scope        string     `gorm:"column:scope;primaryKey"`
+	Key          string     `gorm:"column:key;primaryKey"`
+	Failures     int        `gorm:"column:failures"`
+	LockedUntil  *time.Time `gorm:"column:locked_until"`
+	LastFailedAt time.Time  `gorm:"column:last_failed_at"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
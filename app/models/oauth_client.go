@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// OAuthClient is a machine client exchanging its credentials for access tokens.
+type OAuthClient struct {
+	Id         int64      `gorm:"column:id;primaryKey"`
+	ClientId   string     `gorm:"column:client_id"`
+	Name       string     `gorm:"column:name"`
+	SecretHash string     `gorm:"column:secret_hash"`
+	Scopes     StringList `gorm:"column:scopes;type:jsonb"`
+	IsEnabled  bool       `gorm:"column:is_enabled"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
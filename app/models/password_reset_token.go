@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// PasswordResetToken is a single-use token sent by mail. Only its SHA-256 hash is stored.
+type PasswordResetToken struct {
+	Id          int64      `gorm:"column:id;primaryKey"`
+	UserId      int64      `gorm:"column:user_id"`
+	TokenHash   string     `gorm:"column:token_hash"`
+	ExpiresAt   time.Time  `gorm:"column:expires_at"`
+	UsedAt      *time.Time `gorm:"column:used_at"`
+	RequestedIp string     `gorm:"column:requested_ip"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// RefreshToken is a single link of a refresh token family. Only the SHA-256
+// hash of the opaque token is stored.
+type RefreshToken struct {
+	Id               int64      `gorm:"column:id;primaryKey"`
+	FamilyId         string     `gorm:"column:family_id"`
+	ParentId         *int64     `gorm:"column:parent_id"`
+	UserId           int64      `gorm:"column:user_id"`
+	Subject          string     `gorm:"column:subject"`
+	TokenHash        string     `gorm:"column:token_hash"`
+	UserAgent        string     `gorm:"column:user_agent"`
+	IpAddress        string     `gorm:"column:ip_address"`
+	SessionStartedAt time.Time  `gorm:"column:session_started_at"`
+	ExpiresAt        time.Time  `gorm:"column:expires_at"`
+	UsedAt           *time.Time `gorm:"column:used_at"`
+	RevokedAt        *time.Time `gorm:"column:revoked_at"`
+	RevokedReason    *string    `gorm:"column:revoked_reason"`
+	CreatedAt        time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
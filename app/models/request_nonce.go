@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RequestNonce is a nonce of a signed request, kept until its timestamp can
+// no longer be accepted so that the request cannot be replayed on any instance.
+type RequestNonce struct {
+	ClientId  string    `gorm:"column:client_id;primaryKey"`
+	Nonce     string    `gorm:"column:nonce;primaryKey"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+}
+
+func (RequestNonce) TableName() string {
+	return "request_nonces"
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type Role struct {
+	Id          int64         `gorm:"column:id;primaryKey"`
+	Code        string        `gorm:"column:code"`
+	Name        string        `gorm:"column:name"`
+	Description *string       `gorm:"column:description"`
+	Permissions []*Permission `gorm:"many2many:role_permissions"`
+	CreatedAt   time.Time     `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time     `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+type Permission struct {
+	Id          int64     `gorm:"column:id;primaryKey"`
+	Code        string    `gorm:"column:code"`
+	Description *string   `gorm:"column:description"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (Permission) TableName() string {
+	return "permissions"
+}
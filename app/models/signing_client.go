@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// SigningClient is a partner signing its requests with a shared HMAC secret.
+// The secret is stored encrypted because it is needed to verify signatures.
+type SigningClient struct {
+	Id               int64      `gorm:"column:id;primaryKey"`
+	ClientId         string     `gorm:"column:client_id"`
+	SecretCiphertext string     `gorm:"column:secret_ciphertext"`
+	Scopes           StringList `gorm:"column:scopes;type:jsonb"`
+	IsEnabled        bool       `gorm:"column:is_enabled"`
+	CreatedAt        time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (SigningClient) TableName() string {
+	return "signing_clients"
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// StringList is a list of strings stored in a JSONB column.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+
+	value, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(value), nil
+}
+
+func (l *StringList) Scan(src any) error {
+	var data []byte
+	switch value := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", src)
+	}
+
+	return json.Unmarshal(data, (*[]string)(l))
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// TokenRevocation denies a single access token by its jti, or every access
+// token of a user issued up to RevokedAt when Jti is empty.
+type TokenRevocation struct {
+	Id        int64     `gorm:"column:id;primaryKey"`
+	Jti       *string   `gorm:"column:jti"`
+	UserId    *int64    `gorm:"column:user_id"`
+	Reason    string    `gorm:"column:reason"`
+	RevokedAt time.Time `gorm:"column:revoked_at"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+}
+
+func (TokenRevocation) TableName() string {
+	return "token_revocations"
+}
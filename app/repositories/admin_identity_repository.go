@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (rc *RepositoryContext) FindAdminIdentity(ctx context.Context, issuer string, subject string) (*models.AdminIdentity, error) {
+	identity := new(models.AdminIdentity)
+
+	err := rc.db.WithContext(ctx).Where("issuer = ? AND subject = ?", issuer, subject).First(identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newError("find admin identity", err.Error())
+	}
+
+	return identity, nil
+}
+
+func (rc *RepositoryContext) CreateAdminIdentity(ctx context.Context, identity *models.AdminIdentity) error {
+	if err := rc.db.WithContext(ctx).Create(identity).Error; err != nil {
+		return newError("create admin identity", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RepositoryContext) UpdateAdminIdentityLastLogin(ctx context.Context, id int64, email string, lastLoginAt time.Time) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.AdminIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"email": email, "last_login_at": lastLoginAt}).Error
+	if err != nil {
+		return newError("update admin identity last login", err.Error())
+	}
+
+	return nil
+}
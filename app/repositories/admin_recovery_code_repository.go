@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"time"
+)
+
+// ReplaceAdminRecoveryCodes drops the previous codes of the user.
+func (rc *RepositoryContext) ReplaceAdminRecoveryCodes(ctx context.Context, userId int64, codes []*models.AdminRecoveryCode) error {
+	db := rc.db.WithContext(ctx)
+
+	if err := db.Where("user_id = ?", userId).Delete(&models.AdminRecoveryCode{}).Error; err != nil {
+		return newError("delete admin recovery codes", err.Error())
+	}
+
+	if len(codes) == 0 {
+		return nil
+	}
+
+	if err := db.Create(codes).Error; err != nil {
+		return newError("create admin recovery codes", err.Error())
+	}
+
+	return nil
+}
+
+// UseAdminRecoveryCode marks an unused code as used and reports whether it existed.
+func (rc *RepositoryContext) UseAdminRecoveryCode(ctx context.Context, userId int64, codeHash string, usedAt time.Time) (bool, error) {
+	result := rc.db.WithContext(ctx).
+		Model(&models.AdminRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, newError("use admin recovery code", result.Error.Error())
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (rc *RepositoryContext) CountUnusedAdminRecoveryCodes(ctx context.Context, userId int64) (int64, error) {
+	var count int64
+
+	err := rc.db.WithContext(ctx).
+		Model(&models.AdminRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userId).
+		Count(&count).Error
+	if err != nil {
+		return 0, newError("count admin recovery codes", err.Error())
+	}
+
+	return count, nil
+}
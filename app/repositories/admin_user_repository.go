@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FindAdminUserByUsernameOrEmail matches the identifier case-insensitively against the username and the email.
+func (rc *RepositoryContext) FindAdminUserByUsernameOrEmail(ctx context.Context, identifier string) (*models.AdminUser, error) {
+	user := new(models.AdminUser)
+
+	err := rc.db.WithContext(ctx).
+		Where("LOWER(username) = LOWER(?) OR LOWER(email) = LOWER(?)", identifier, identifier).
+		First(user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newError("find admin user", err.Error())
+	}
+
+	return user, nil
+}
+
+func (rc *RepositoryContext) FindAdminUserById(ctx context.Context, id int64) (*models.AdminUser, error) {
+	user := new(models.AdminUser)
+
+	err := rc.db.WithContext(ctx).Where("id = ?", id).First(user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newError("find admin user", err.Error())
+	}
+
+	return user, nil
+}
+
+func (rc *RepositoryContext) CreateAdminUser(ctx context.Context, user *models.AdminUser) error {
+	if err := rc.db.WithContext(ctx).Create(user).Error; err != nil {
+		return newError("create admin user", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RepositoryContext) UpdateAdminUserPassword(ctx context.Context, id int64, password string) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.AdminUser{}).
+		Where("id = ?", id).
+		Update("password", password).Error
+	if err != nil {
+		return newError("update admin user password", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RepositoryContext) UpdateAdminUserRole(ctx context.Context, id int64, role string) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.AdminUser{}).
+		Where("id = ?", id).
+		Update("role", role).Error
+	if err != nil {
+		return newError("update admin user role", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RepositoryContext) UpdateAdminUserLocale(ctx context.Context, id int64, locale string) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.AdminUser{}).
+		Where("id = ?", id).
+		Update("locale", locale).Error
+	if err != nil {
+		return newError("update admin user locale", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RepositoryContext) UpdateAdminUserLastLogin(ctx context.Context, id int64, lastLoginAt time.Time) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.AdminUser{}).
+		Where("id = ?", id).
+		Update("last_login_at", lastLoginAt).Error
+	if err != nil {
+		return newError("update admin user last login", err.Error())
+	}
+
+	return nil
+}
+
+// UpdateAdminUserTotp stores a pending secret, or clears two-factor authentication when secret is nil.
+func (rc *RepositoryContext) UpdateAdminUserTotp(ctx context.Context, id int64, secret *string, enabledAt *time.Time) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.AdminUser{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"totp_secret":         secret,
+			"totp_enabled_at":     enabledAt,
+			"totp_last_used_step": 0,
+		}).Error
+	if err != nil {
+		return newError("update admin user totp", err.Error())
+	}
+
+	return nil
+}
+
+// UpdateAdminUserTotpStep records the period of the last accepted code. It
+// reports false when a code of the same or a later period was accepted meanwhile.
+func (rc *RepositoryContext) UpdateAdminUserTotpStep(ctx context.Context, id int64, step int64) (bool, error) {
+	result := rc.db.WithContext(ctx).
+		Model(&models.AdminUser{}).
+		Where("id = ? AND totp_last_used_step < ?", id, step).
+		Update("totp_last_used_step", step)
+	if result.Error != nil {
+		return false, newError("update admin user totp step", result.Error.Error())
+	}
+
+	return result.RowsAffected > 0, nil
+}
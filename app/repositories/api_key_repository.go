@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (rc *RepositoryContext) CreateApiKey(ctx context.Context, key *models.ApiKey) error {
+	if err := rc.db.WithContext(ctx).Create(key).Error; err != nil {
+		return newError("create api key", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RepositoryContext) FindApiKeyByPrefix(ctx context.Context, prefix string) (*models.ApiKey, error) {
+	key := new(models.ApiKey)
+
+	err := rc.db.WithContext(ctx).Where("prefix = ?", prefix).First(key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newError("find api key", err.Error())
+	}
+
+	return key, nil
+}
+
+func (rc *RepositoryContext) FindApiKeys(ctx context.Context) ([]*models.ApiKey, error) {
+	var keys []*models.ApiKey
+
+	if err := rc.db.WithContext(ctx).Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, newError("find api keys", err.Error())
+	}
+
+	return keys, nil
+}
+
+// RevokeApiKey returns the key, or nil when it does not exist or was already revoked.
+func (rc *RepositoryContext) RevokeApiKey(ctx context.Context, id int64, revokedAt time.Time) (*models.ApiKey, error) {
+	key := new(models.ApiKey)
+
+	result := rc.db.WithContext(ctx).
+		Model(key).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return nil, newError("revoke api key", result.Error.Error())
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return key, nil
+}
+
+func (rc *RepositoryContext) UpdateApiKeyLastUsed(ctx context.Context, id int64, usedAt time.Time) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.ApiKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+	if err != nil {
+		return newError("update api key last used", err.Error())
+	}
+
+	return nil
+}
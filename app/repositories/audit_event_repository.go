@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+)
+
+func (rc *RepositoryContext) CreateAuditEvent(ctx context.Context, event *models.AuditEvent) error {
+	if err := rc.db.WithContext(ctx).Create(event).Error; err != nil {
+		return newError("create audit event", err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func (rc *RepositoryContext) FindBasicAuthClientByName(ctx context.Context, name string) (*models.BasicAuthClient, error) {
+	client := new(models.BasicAuthClient)
+
+	err := rc.db.WithContext(ctx).Where("LOWER(name) = LOWER(?)", name).First(client).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newError("find basic auth client", err.Error())
+	}
+
+	return client, nil
+}
+
+func (rc *RepositoryContext) FindBasicAuthClients(ctx context.Context) ([]*models.BasicAuthClient, error) {
+	var clients []*models.BasicAuthClient
+
+	if err := rc.db.WithContext(ctx).Order("name").Find(&clients).Error; err != nil {
+		return nil, newError("find basic auth clients", err.Error())
+	}
+
+	return clients, nil
+}
+
+func (rc *RepositoryContext) CreateBasicAuthClient(ctx context.Context, client *models.BasicAuthClient) error {
+	if err := rc.db.WithContext(ctx).Create(client).Error; err != nil {
+		return newError("create basic auth client", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RepositoryContext) UpdateBasicAuthClientEnabled(ctx context.Context, name string, enabled bool) (int64, error) {
+	result := rc.db.WithContext(ctx).
+		Model(&models.BasicAuthClient{}).
+		Where("LOWER(name) = LOWER(?)", name).
+		Update("is_enabled", enabled)
+	if result.Error != nil {
+		return 0, newError("update basic auth client", result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
+}
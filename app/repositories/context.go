@@ -19,6 +19,12 @@ type RepositoryContext struct {
 	*Adapter
 }
 
+// NewRepositoryContext runs the queries on an open connection, such as the
+// in-memory database of the tests.
+func NewRepositoryContext(ctx context.Context, db *gorm.DB, cfg *config.Config) *RepositoryContext {
+	return &RepositoryContext{ctx: ctx, db: db, cfg: cfg}
+}
+
 func (rc *RepositoryContext) ReleaseTx(tx *gorm.DB, err error) error {
 	if err != nil {
 		if errRollback := tx.Rollback().Error; errRollback != nil {
@@ -35,6 +41,16 @@ func (rc *RepositoryContext) ReleaseTx(tx *gorm.DB, err error) error {
 	return nil
 }
 
+// WithTx returns a copy of the repository context running its queries on the transaction.
+func (rc *RepositoryContext) WithTx(tx *gorm.DB) *RepositoryContext {
+	return &RepositoryContext{
+		ctx:     rc.ctx,
+		db:      tx,
+		cfg:     rc.cfg,
+		Adapter: rc.Adapter,
+	}
+}
+
 type transactionFn func(tx *gorm.DB) error
 
 func (rc *RepositoryContext) WithTransaction(callback transactionFn) error {
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"application/app/enums"
 	"fmt"
 	"strings"
 )
@@ -23,6 +24,11 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("repository: %s", e.Message)
 }
 
+// ErrorCode keeps the details of database errors away from clients.
+func (e *Error) ErrorCode() enums.CodeError {
+	return enums.ErrorCodeDatabase
+}
+
 func newError(context string, reason string) error {
 	return &Error{
 		Message: context,
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (rc *RepositoryContext) FindLoginAttempt(ctx context.Context, scope string, key string) (*models.LoginAttempt, error) {
+	attempt := new(models.LoginAttempt)
+
+	err := rc.db.WithContext(ctx).Where("scope = ? AND key = ?", scope, key).First(attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newError("find login attempt", err.Error())
+	}
+
+	return attempt, nil
+}
+
+// IncrementLoginFailure counts a failed login and returns the consecutive failures.
+// The counter restarts when the previous failure happened before resetBefore.
+func (rc *RepositoryContext) IncrementLoginFailure(ctx context.Context, scope string, key string, now time.Time, resetBefore time.Time) (int, error) {
+	var failures int
+
+	err := rc.db.WithContext(ctx).Raw(`
+		INSERT INTO login_attempts (scope, key, failures, last_failed_at)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT (scope, key) DO UPDATE
+		SET failures       = CASE WHEN login_attempts.last_failed_at < ? THEN 1 ELSE login_attempts.failures + 1 END,
+		    last_failed_at = EXCLUDED.last_failed_at
+		RETURNING failures`,
+		scope, key, now, resetBefore,
+	).Scan(&failures).Error
+	if err != nil {
+		return 0, newError("increment login failure", err.Error())
+	}
+
+	return failures, nil
+}
+
+func (rc *RepositoryContext) LockLoginAttempt(ctx context.Context, scope string, key string, lockedUntil time.Time) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.LoginAttempt{}).
+		Where("scope = ? AND key = ?", scope, key).
+		Update("locked_until", lockedUntil).Error
+	if err != nil {
+		return newError("lock login attempt", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RepositoryContext) DeleteLoginAttempt(ctx context.Context, scope string, key string) (int64, error) {
+	result := rc.db.WithContext(ctx).Where("scope = ? AND key = ?", scope, key).Delete(&models.LoginAttempt{})
+	if result.Error != nil {
+		return 0, newError("delete login attempt", result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
+}
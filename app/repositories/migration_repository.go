@@ -0,0 +1,19 @@
+package repositories
+
+import "context"
+
+// MigrationVersion returns the version of the last migration applied by
+// golang-migrate and whether it failed halfway.
+func (rc *RepositoryContext) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	var row struct {
+		Version uint
+		Dirty   bool
+	}
+
+	err := rc.db.WithContext(ctx).Raw(`SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&row).Error
+	if err != nil {
+		return 0, false, newError("find migration version", err.Error())
+	}
+
+	return row.Version, row.Dirty, nil
+}
@@ -0,0 +1,27 @@
+package repositories_test
+
+import (
+	"application/app/repositories/repositorytest"
+	"context"
+	"testing"
+)
+
+func TestMigrationVersion(t *testing.T) {
+	ctx := context.Background()
+	repo, db := repositorytest.Open(t)
+
+	if err := db.Exec(`CREATE TABLE schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES (14, true)`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	version, dirty, err := repo.MigrationVersion(ctx)
+	if err != nil {
+		t.Fatalf("MigrationVersion() error = %v", err)
+	}
+	if version != 14 || !dirty {
+		t.Errorf("MigrationVersion() = %d, %v, want 14, true", version, dirty)
+	}
+}
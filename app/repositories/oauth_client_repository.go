@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func (rc *RepositoryContext) FindOAuthClientByClientId(ctx context.Context, clientId string) (*models.OAuthClient, error) {
+	client := new(models.OAuthClient)
+
+	err := rc.db.WithContext(ctx).Where("client_id = ?", clientId).First(client).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newError("find oauth client", err.Error())
+	}
+
+	return client, nil
+}
+
+func (rc *RepositoryContext) FindOAuthClients(ctx context.Context) ([]*models.OAuthClient, error) {
+	var clients []*models.OAuthClient
+
+	if err := rc.db.WithContext(ctx).Order("name").Find(&clients).Error; err != nil {
+		return nil, newError("find oauth clients", err.Error())
+	}
+
+	return clients, nil
+}
+
+func (rc *RepositoryContext) CreateOAuthClient(ctx context.Context, client *models.OAuthClient) error {
+	if err := rc.db.WithContext(ctx).Create(client).Error; err != nil {
+		return newError("create oauth client", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RepositoryContext) UpdateOAuthClientEnabled(ctx context.Context, clientId string, enabled bool) (int64, error) {
+	result := rc.db.WithContext(ctx).
+		Model(&models.OAuthClient{}).
+		Where("client_id = ?", clientId).
+		Update("is_enabled", enabled)
+	if result.Error != nil {
+		return 0, newError("update oauth client", result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (rc *RepositoryContext) CreatePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	if err := rc.db.WithContext(ctx).Create(token).Error; err != nil {
+		return newError("create password reset token", err.Error())
+	}
+
+	return nil
+}
+
+// FindPasswordResetTokenByHashForUpdate locks the token until the transaction ends.
+func (rc *RepositoryContext) FindPasswordResetTokenByHashForUpdate(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	token := new(models.PasswordResetToken)
+
+	err := rc.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("token_hash = ?", tokenHash).
+		First(token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newError("find password reset token", err.Error())
+	}
+
+	return token, nil
+}
+
+// InvalidatePasswordResetTokens marks every unused token of the user as used.
+func (rc *RepositoryContext) InvalidatePasswordResetTokens(ctx context.Context, userId int64, usedAt time.Time) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userId).
+		Update("used_at", usedAt).Error
+	if err != nil {
+		return newError("invalidate password reset tokens", err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (rc *RepositoryContext) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	if err := rc.db.WithContext(ctx).Create(token).Error; err != nil {
+		return newError("create refresh token", err.Error())
+	}
+
+	return nil
+}
+
+// FindRefreshTokenByHashForUpdate locks the token row until the transaction ends.
+func (rc *RepositoryContext) FindRefreshTokenByHashForUpdate(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	token := new(models.RefreshToken)
+
+	err := rc.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("token_hash = ?", hash).
+		First(token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newError("find refresh token", err.Error())
+	}
+
+	return token, nil
+}
+
+func (rc *RepositoryContext) MarkRefreshTokenUsed(ctx context.Context, id int64, usedAt time.Time) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("id = ?", id).
+		Update("used_at", usedAt).Error
+	if err != nil {
+		return newError("mark refresh token used", err.Error())
+	}
+
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every token of the family that is not revoked yet.
+func (rc *RepositoryContext) RevokeRefreshTokenFamily(ctx context.Context, familyId string, reason string) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+	if err != nil {
+		return newError("revoke refresh token family", err.Error())
+	}
+
+	return nil
+}
+
+// RevokeUserRefreshTokenFamily revokes a family only when it belongs to the user.
+func (rc *RepositoryContext) RevokeUserRefreshTokenFamily(ctx context.Context, userId int64, familyId string, reason string) (int64, error) {
+	result := rc.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userId, familyId).
+		Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason})
+	if result.Error != nil {
+		return 0, newError("revoke refresh token family", result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
+}
+
+// FindRefreshTokenFamilySince returns the tokens of the family created since the time.
+func (rc *RepositoryContext) FindRefreshTokenFamilySince(ctx context.Context, familyId string, since time.Time) ([]*models.RefreshToken, error) {
+	var tokens []*models.RefreshToken
+
+	err := rc.db.WithContext(ctx).
+		Where("family_id = ? AND created_at > ?", familyId, since).
+		Find(&tokens).Error
+	if err != nil {
+		return nil, newError("find refresh token family", err.Error())
+	}
+
+	return tokens, nil
+}
+
+// FindActiveRefreshTokensByUserId returns the latest token of every session of the user.
+func (rc *RepositoryContext) FindActiveRefreshTokensByUserId(ctx context.Context, userId int64) ([]*models.RefreshToken, error) {
+	var tokens []*models.RefreshToken
+
+	err := rc.db.WithContext(ctx).
+		Where("user_id = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", userId, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, newError("find active refresh tokens", err.Error())
+	}
+
+	return tokens, nil
+}
+
+// RevokeUserRefreshTokens revokes every session of the user.
+func (rc *RepositoryContext) RevokeUserRefreshTokens(ctx context.Context, userId int64, reason string) error {
+	err := rc.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+	if err != nil {
+		return newError("revoke user refresh tokens", err.Error())
+	}
+
+	return nil
+}
@@ -34,6 +34,7 @@ func NewRepository(cfg *config.Config) (*Repository, error) {
 		MaxOpenConn:     &maxOpenConn,
 		MaxConnLifetime: &maxConnLifetime,
 		AppMode:         cfg.AppMode,
+		SlowThreshold:   cfg.DatabaseSlowThreshold,
 	})
 	if err != nil {
 		log.Error().Msg(fmt.Sprintf("failed init database with error = [%v]", err))
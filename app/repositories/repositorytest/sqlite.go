@@ -0,0 +1,48 @@
+// Package repositorytest opens an in-memory database for the tests of the
+// services and middlewares using the repositories.
+package repositorytest
+
+import (
+	"application/app/repositories"
+	"application/config"
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var databases atomic.Int64
+
+// Open creates an empty in-memory SQLite database with the tables of the
+// models. It is closed when the test ends. The connection is returned too, to
+// arrange rows the repositories have no method for.
+func Open(t testing.TB, models ...any) (*repositories.RepositoryContext, *gorm.DB) {
+	t.Helper()
+
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	dsn := fmt.Sprintf("file:%s_%d?mode=memory&cache=shared&_foreign_keys=on", name, databases.Add(1))
+
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard, TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	// one connection keeps the in-memory database alive and serializes the transactions
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("failed to create test tables: %v", err)
+	}
+
+	return repositories.NewRepositoryContext(context.Background(), db, &config.Config{}), db
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"time"
+)
+
+// UseRequestNonce records the nonce of a client. It reports false when the
+// nonce is already recorded and not expired, i.e. the request is a replay.
+func (rc *RepositoryContext) UseRequestNonce(ctx context.Context, clientId string, nonce string, expiresAt time.Time, now time.Time) (bool, error) {
+	result := rc.db.WithContext(ctx).Exec(`
+		INSERT INTO request_nonces (client_id, nonce, expires_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (client_id, nonce) DO UPDATE
+		SET expires_at = EXCLUDED.expires_at
+		WHERE request_nonces.expires_at <= ?`,
+		clientId, nonce, expiresAt, now,
+	)
+	if result.Error != nil {
+		return false, newError("use request nonce", result.Error.Error())
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (rc *RepositoryContext) DeleteExpiredRequestNonces(ctx context.Context, now time.Time) (int64, error) {
+	result := rc.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.RequestNonce{})
+	if result.Error != nil {
+		return 0, newError("delete expired request nonces", result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,47 @@
+package repositories_test
+
+import (
+	"application/app/models"
+	"application/app/repositories/repositorytest"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestUseRequestNonce(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := repositorytest.Open(t, &models.RequestNonce{})
+	now := time.Now()
+
+	use := func(clientId string, nonce string, expiresAt time.Time, now time.Time) bool {
+		t.Helper()
+
+		fresh, err := repo.UseRequestNonce(ctx, clientId, nonce, expiresAt, now)
+		if err != nil {
+			t.Fatalf("UseRequestNonce() error = %v", err)
+		}
+
+		return fresh
+	}
+
+	if !use("acme", "nonce", now.Add(time.Minute), now) {
+		t.Fatal("first use of the nonce is a replay")
+	}
+	if use("acme", "nonce", now.Add(time.Minute), now) {
+		t.Error("second use of the nonce is fresh")
+	}
+	if !use("other", "nonce", now.Add(time.Minute), now) {
+		t.Error("nonce of another client is a replay")
+	}
+
+	// an expired nonce may be used again
+	later := now.Add(2 * time.Minute)
+	if !use("acme", "nonce", later.Add(time.Minute), later) {
+		t.Error("use of an expired nonce is a replay")
+	}
+
+	deleted, err := repo.DeleteExpiredRequestNonces(ctx, later)
+	if err != nil || deleted != 1 {
+		t.Errorf("DeleteExpiredRequestNonces() = %d, %v, want the nonce of the other client", deleted, err)
+	}
+}
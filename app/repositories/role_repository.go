@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+)
+
+// FindPermissionCodesByRoleCodes returns the distinct permissions granted to any of the roles.
+func (rc *RepositoryContext) FindPermissionCodesByRoleCodes(ctx context.Context, roles []string) ([]string, error) {
+	var codes []string
+
+	if len(roles) == 0 {
+		return codes, nil
+	}
+
+	err := rc.db.WithContext(ctx).
+		Table("permissions p").
+		Joins("JOIN role_permissions rp ON rp.permission_id = p.id").
+		Joins("JOIN roles r ON r.id = rp.role_id").
+		Where("r.code IN ?", roles).
+		Distinct().
+		Pluck("p.code", &codes).Error
+	if err != nil {
+		return nil, newError("find permissions by roles", err.Error())
+	}
+
+	return codes, nil
+}
+
+// FindRolePermissionPairs returns every grant as "role:permission", in order.
+func (rc *RepositoryContext) FindRolePermissionPairs(ctx context.Context) ([]string, error) {
+	var pairs []string
+
+	err := rc.db.WithContext(ctx).
+		Table("role_permissions rp").
+		Joins("JOIN roles r ON r.id = rp.role_id").
+		Joins("JOIN permissions p ON p.id = rp.permission_id").
+		Order("r.code, p.code").
+		Pluck("r.code || ':' || p.code", &pairs).Error
+	if err != nil {
+		return nil, newError("find role permissions", err.Error())
+	}
+
+	return pairs, nil
+}
+
+func (rc *RepositoryContext) FindRoles(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+
+	if err := rc.db.WithContext(ctx).Preload("Permissions").Order("id").Find(&roles).Error; err != nil {
+		return nil, newError("find roles", err.Error())
+	}
+
+	return roles, nil
+}
@@ -0,0 +1,44 @@
+package repositories_test
+
+import (
+	"application/app/models"
+	"application/app/repositories/repositorytest"
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestRolePermissionQueries(t *testing.T) {
+	ctx := context.Background()
+	repo, db := repositorytest.Open(t, &models.Role{}, &models.Permission{})
+
+	read := &models.Permission{Code: "users:read"}
+	write := &models.Permission{Code: "users:write"}
+	audit := &models.Permission{Code: "audit:read"}
+
+	roles := []*models.Role{
+		{Code: "ADMIN", Name: "Admin", Permissions: []*models.Permission{read, write}},
+		{Code: "AUDITOR", Name: "Auditor", Permissions: []*models.Permission{audit, read}},
+	}
+	if err := db.Create(roles).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	codes, err := repo.FindPermissionCodesByRoleCodes(ctx, []string{"ADMIN", "AUDITOR"})
+	if err != nil {
+		t.Fatalf("FindPermissionCodesByRoleCodes() error = %v", err)
+	}
+	slices.Sort(codes)
+	if want := []string{"audit:read", "users:read", "users:write"}; !slices.Equal(codes, want) {
+		t.Errorf("FindPermissionCodesByRoleCodes() = %v, want %v", codes, want)
+	}
+
+	pairs, err := repo.FindRolePermissionPairs(ctx)
+	if err != nil {
+		t.Fatalf("FindRolePermissionPairs() error = %v", err)
+	}
+	want := []string{"ADMIN:users:read", "ADMIN:users:write", "AUDITOR:audit:read", "AUDITOR:users:read"}
+	if !slices.Equal(pairs, want) {
+		t.Errorf("FindRolePermissionPairs() = %v, want %v", pairs, want)
+	}
+}
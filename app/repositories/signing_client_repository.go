@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func (rc *RepositoryContext) FindSigningClientByClientId(ctx context.Context, clientId string) (*models.SigningClient, error) {
+	client := new(models.SigningClient)
+
+	err := rc.db.WithContext(ctx).Where("client_id = ?", clientId).First(client).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newError("find signing client", err.Error())
+	}
+
+	return client, nil
+}
+
+func (rc *RepositoryContext) CreateSigningClient(ctx context.Context, client *models.SigningClient) error {
+	if err := rc.db.WithContext(ctx).Create(client).Error; err != nil {
+		return newError("create signing client", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RepositoryContext) UpdateSigningClientEnabled(ctx context.Context, clientId string, enabled bool) (int64, error) {
+	result := rc.db.WithContext(ctx).
+		Model(&models.SigningClient{}).
+		Where("client_id = ?", clientId).
+		Update("is_enabled", enabled)
+	if result.Error != nil {
+		return 0, newError("update signing client", result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
+}
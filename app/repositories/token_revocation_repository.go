@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"application/app/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+func (rc *RepositoryContext) CreateTokenRevocation(ctx context.Context, revocation *models.TokenRevocation) error {
+	err := rc.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(revocation).Error
+	if err != nil {
+		return newError("create token revocation", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RepositoryContext) FindActiveTokenRevocations(ctx context.Context, now time.Time) ([]*models.TokenRevocation, error) {
+	var revocations []*models.TokenRevocation
+
+	if err := rc.db.WithContext(ctx).Where("expires_at > ?", now).Find(&revocations).Error; err != nil {
+		return nil, newError("find active token revocations", err.Error())
+	}
+
+	return revocations, nil
+}
+
+func (rc *RepositoryContext) DeleteExpiredTokenRevocations(ctx context.Context, now time.Time) (int64, error) {
+	result := rc.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.TokenRevocation{})
+	if result.Error != nil {
+		return 0, newError("delete expired token revocations", result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"application/app/enums"
+	apperror "application/app/error"
+	"application/app/models"
+	"application/app/web"
+	"application/pkg/apikey"
+	"application/pkg/audit"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrApiKeyNotFound         = apperror.New(enums.ErrorCodeApiKeyNotFound, "api key not found")
+	ErrInvalidRateLimitTier   = apperror.New(enums.ErrorCodeInvalidRateLimitTier, "invalid rate limit tier")
+	ErrApiKeyExpirationPassed = apperror.New(enums.ErrorCodeApiKeyExpirationPassed, "api key expiration must be in the future")
+)
+
+// CreateApiKey generates a key. The key is only returned here, it cannot be read back.
+func (s *Service) CreateApiKey(request *web.CreateApiKeyRequest, createdBy *int64, actor string) (*web.CreatedApiKeyResponse, error) {
+	tier := enums.RateLimitTierStandard
+	if request.RateLimitTier != "" {
+		tier = enums.CodeRateLimitTier(strings.ToUpper(request.RateLimitTier))
+	}
+
+	if !tier.IsValid() {
+		return nil, apperror.NewErrorTrace(fmt.Errorf("%w: %s", ErrInvalidRateLimitTier, request.RateLimitTier), "create api key").Status(http.StatusBadRequest)
+	}
+
+	if request.ExpiredAt != nil && !request.ExpiredAt.After(time.Now()) {
+		return nil, apperror.NewErrorTrace(ErrApiKeyExpirationPassed, "create api key").Status(http.StatusBadRequest)
+	}
+
+	value, prefix, hash := apikey.Generate()
+
+	key := &models.ApiKey{
+		Name:          strings.TrimSpace(request.Name),
+		Prefix:        prefix,
+		KeyHash:       hash,
+		Scopes:        request.Scopes,
+		RateLimitTier: tier.String(),
+		ExpiresAt:     request.ExpiredAt,
+		CreatedBy:     createdBy,
+	}
+
+	if err := s.repository.CreateApiKey(s.ctx, key); err != nil {
+		return nil, apperror.NewErrorTrace(err, "create api key")
+	}
+
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:     enums.AuditEventApiKeyCreated,
+		Actor:    actor,
+		Metadata: map[string]any{"api_key_id": key.Id, "prefix": key.Prefix, "scopes": key.Scopes},
+	})
+
+	return &web.CreatedApiKeyResponse{
+		ApiKeyResponse: *newApiKeyResponse(key),
+		Key:            value,
+	}, nil
+}
+
+func (s *Service) ListApiKeys() ([]*web.ApiKeyResponse, error) {
+	keys, err := s.repository.FindApiKeys(s.ctx)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "list api keys")
+	}
+
+	response := make([]*web.ApiKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, newApiKeyResponse(key))
+	}
+
+	return response, nil
+}
+
+func (s *Service) RevokeApiKey(id int64, actor string) error {
+	key, err := s.repository.RevokeApiKey(s.ctx, id, time.Now())
+	if err != nil {
+		return apperror.NewErrorTrace(err, "revoke api key")
+	}
+
+	if key == nil {
+		return apperror.NewErrorTrace(ErrApiKeyNotFound, "revoke api key").Status(http.StatusNotFound)
+	}
+
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:     enums.AuditEventApiKeyRevoked,
+		Actor:    actor,
+		Metadata: map[string]any{"api_key_id": key.Id, "prefix": key.Prefix},
+	})
+
+	return nil
+}
+
+func newApiKeyResponse(key *models.ApiKey) *web.ApiKeyResponse {
+	scopes := []string(key.Scopes)
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return &web.ApiKeyResponse{
+		Id:            key.Id,
+		Name:          key.Name,
+		Prefix:        key.Prefix,
+		Scopes:        scopes,
+		RateLimitTier: key.RateLimitTier,
+		ExpiredAt:     key.ExpiresAt,
+		LastUsedAt:    key.LastUsedAt,
+		RevokedAt:     key.RevokedAt,
+		CreatedAt:     key.CreatedAt,
+	}
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"application/app/enums"
+	apperror "application/app/error"
+	"application/app/models"
+	"application/app/web"
+	"application/pkg/audit"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/lockout"
+	"application/pkg/util"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidCredentials = apperror.New(enums.ErrorCodeInvalidCredentials, "invalid username or password")
+	ErrUserNotFound       = apperror.New(enums.ErrorCodeUserNotFound, "user not found")
+)
+
+// Login verifies the credentials of an admin user and starts a new session.
+// Accounts with two-factor authentication get an MFA challenge instead, to be
+// completed with LoginWithMfa. Failed attempts are counted per account and per
+// IP address, and both are locked for a growing delay once too many failed.
+func (s *Service) Login(request *web.LoginRequest, userAgent string, ipAddress string) (*web.Session, *web.MfaChallenge, error) {
+	identifier := strings.ToLower(strings.TrimSpace(request.Username))
+
+	user, err := s.repository.FindAdminUserByUsernameOrEmail(s.ctx, identifier)
+	if err != nil {
+		return nil, nil, apperror.NewErrorTrace(err, "login")
+	}
+
+	// count the attempts of the account whether it was named by username or email
+	account := identifier
+	if user != nil {
+		account = loginAccount(user)
+	}
+
+	if err := s.deps.Lockout.Check(s.ctx, account, ipAddress); err != nil {
+		return nil, nil, s.lockedLogin(err, account, ipAddress)
+	}
+
+	if user == nil {
+		// spend the same time as a wrong password so usernames cannot be enumerated
+		_ = util.ComparePassword(util.DummyPasswordHash(), request.Password)
+		return nil, nil, s.failedLogin(account, ipAddress, nil)
+	}
+
+	if err := util.ComparePassword(user.Password, request.Password); err != nil || !user.IsActive {
+		return nil, nil, s.failedLogin(account, ipAddress, &user.Id)
+	}
+
+	s.rehashPassword(user, request.Password)
+
+	if user.TotpEnabledAt != nil {
+		challenge, err := s.issueMfaChallenge(user)
+		return nil, challenge, err
+	}
+
+	session, err := s.completeLogin(user, account, userAgent, ipAddress)
+	return session, nil, err
+}
+
+// completeLogin starts the session of an admin whose credentials were verified.
+func (s *Service) completeLogin(user *models.AdminUser, account string, userAgent string, ipAddress string) (*web.Session, error) {
+	if err := s.deps.Lockout.Success(s.ctx, account); err != nil {
+		log.Error().Err(err).Int64("user_id", user.Id).Msg("[login] failed to reset failed attempts")
+	}
+
+	if err := s.repository.UpdateAdminUserLastLogin(s.ctx, user.Id, time.Now()); err != nil {
+		log.Error().Err(err).Int64("user_id", user.Id).Msg("[login] failed to update last login")
+	}
+
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:      enums.AuditEventLoginSucceeded,
+		Actor:     account,
+		IpAddress: ipAddress,
+		Metadata:  map[string]any{"user_id": user.Id},
+	})
+
+	return s.CreateSession(&SessionPayload{
+		UserId:    user.Id,
+		Subject:   user.Username,
+		Roles:     []string{user.Role},
+		Locale:    user.Locale,
+		UserAgent: userAgent,
+		IpAddress: ipAddress,
+	})
+}
+
+// UnlockAccount clears the failed login attempts of an admin account or an IP address.
+func (s *Service) UnlockAccount(scope string, key string, actor string) (bool, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+
+	unlocked, err := s.deps.Lockout.Unlock(s.ctx, scope, key)
+	if err != nil {
+		return false, apperror.NewErrorTrace(err, "unlock account")
+	}
+
+	if unlocked {
+		s.deps.Audit.Record(s.ctx, audit.Event{
+			Name:     enums.AuditEventAccountUnlocked,
+			Actor:    actor,
+			Metadata: map[string]any{"scope": scope, "key": key},
+		})
+	}
+
+	return unlocked, nil
+}
+
+func (s *Service) failedLogin(account string, ipAddress string, userId *int64) error {
+	metadata := map[string]any{}
+	if userId != nil {
+		metadata["user_id"] = *userId
+	}
+
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:      enums.AuditEventLoginFailed,
+		Actor:     account,
+		IpAddress: ipAddress,
+		Metadata:  metadata,
+	})
+
+	s.countFailure(account, ipAddress)
+
+	return apperror.NewErrorTrace(ErrInvalidCredentials, "login").Status(http.StatusUnauthorized)
+}
+
+// countFailure counts a failed password or two-factor attempt towards the lockout.
+func (s *Service) countFailure(account string, ipAddress string) {
+	locked, err := s.deps.Lockout.Failure(s.ctx, account, ipAddress)
+	if err != nil {
+		log.Error().Err(err).Str("ip_address", ipAddress).Msg("[login] failed to count failed attempt")
+	}
+
+	for _, lock := range locked {
+		recordLocked(s.ctx, s.deps.Audit, lock, account, ipAddress)
+	}
+}
+
+// loginAccount is the lockout key of an admin account.
+func loginAccount(user *models.AdminUser) string {
+	return strings.ToLower(user.Username)
+}
+
+func (s *Service) lockedLogin(err error, account string, ipAddress string) error {
+	var locked *lockout.LockedError
+	if !errors.As(err, &locked) {
+		return apperror.NewErrorTrace(err, "login")
+	}
+
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:      enums.AuditEventLockedLoginDenied,
+		Actor:     account,
+		IpAddress: ipAddress,
+		Metadata:  map[string]any{"scope": locked.Scope, "locked_until": locked.Until},
+	})
+
+	return apperror.NewErrorTrace(locked, "login").Status(http.StatusTooManyRequests).
+		WithCode(enums.ErrorCodeAccountLocked).
+		WithParams(map[string]any{"retryAfter": locked.RetryAfter()})
+}
+
+// recordLocked audits an account or an IP address that just got locked.
+func recordLocked(ctx context.Context, recorder *audit.Recorder, locked *lockout.LockedError, account string, ipAddress string) {
+	event := enums.AuditEventAccountLocked
+	if locked.Scope == lockout.ScopeIp {
+		event = enums.AuditEventIpAddressLocked
+	}
+
+	recorder.Record(ctx, audit.Event{
+		Name:      event,
+		Actor:     account,
+		IpAddress: ipAddress,
+		Metadata:  map[string]any{"locked_until": locked.Until},
+	})
+}
+
+// Me returns the profile of the token owner with its effective permissions.
+func (s *Service) Me(claims *pkgjwt.JwtResponse) (*web.AdminUserResponse, error) {
+	user, err := s.repository.FindAdminUserById(s.ctx, claims.Id)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "me")
+	}
+
+	if user == nil {
+		return nil, apperror.NewErrorTrace(ErrUserNotFound, "me").Status(http.StatusNotFound)
+	}
+
+	permissions, err := s.deps.Policy.Permissions(s.ctx, claims)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "me")
+	}
+
+	return newAdminUserResponse(user, permissions), nil
+}
+
+// UpdateLocale stores the language of the messages sent to the admin. An empty
+// locale follows the request again. Access tokens carry it from the next refresh.
+func (s *Service) UpdateLocale(userId int64, request *web.UpdateLocaleRequest) error {
+	locale := strings.ToLower(request.Locale)
+
+	if err := s.repository.UpdateAdminUserLocale(s.ctx, userId, locale); err != nil {
+		return apperror.NewErrorTrace(err, "update locale")
+	}
+
+	return nil
+}
+
+func newAdminUserResponse(user *models.AdminUser, permissions []string) *web.AdminUserResponse {
+	return &web.AdminUserResponse{
+		Id:          user.Id,
+		Username:    user.Username,
+		Email:       user.Email,
+		Name:        user.Name,
+		Role:        user.Role,
+		Permissions: permissions,
+		Locale:      user.Locale,
+		LastLoginAt: user.LastLoginAt,
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"application/app/enums"
+	apperror "application/app/error"
+	"application/app/web"
+	pkgjwt "application/pkg/jwt"
+	"net/http"
+)
+
+var ErrForbidden = apperror.New(enums.ErrorCodeAccessDenied, "forbidden")
+
+// Authorize checks permissions from inside a service, for decisions that
+// cannot be expressed by the route middleware alone.
+func (s *Service) Authorize(claims *pkgjwt.JwtResponse, permissions ...enums.CodePermission) error {
+	allowed, err := s.deps.Policy.Can(s.ctx, claims, permissions...)
+	if err != nil {
+		return apperror.NewErrorTrace(err, "authorize")
+	}
+
+	if !allowed {
+		return apperror.NewErrorTrace(ErrForbidden, "authorize").Status(http.StatusForbidden)
+	}
+
+	return nil
+}
+
+func (s *Service) ListRoles() ([]*web.RoleResponse, error) {
+	roles, err := s.repository.FindRoles(s.ctx)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "list roles")
+	}
+
+	response := make([]*web.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		permissions := make([]string, 0, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			permissions = append(permissions, permission.Code)
+		}
+
+		response = append(response, &web.RoleResponse{
+			Code:        role.Code,
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: permissions,
+		})
+	}
+
+	return response, nil
+}
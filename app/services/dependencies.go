@@ -0,0 +1,37 @@
+package services
+
+import (
+	"application/pkg/apikey"
+	"application/pkg/audit"
+	"application/pkg/basicauth"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/lockout"
+	"application/pkg/mailer"
+	"application/pkg/oauth"
+	"application/pkg/oidc"
+	"application/pkg/password"
+	"application/pkg/rbac"
+	"application/pkg/revocation"
+	"application/pkg/secretbox"
+	"application/pkg/signature"
+	"application/pkg/worker"
+)
+
+// Dependencies are the long-lived components shared by every service instance.
+type Dependencies struct {
+	Jwt          *pkgjwt.JwtAdapter
+	Revocation   *revocation.Store
+	Policy       *rbac.Policy
+	Lockout      *lockout.Guard
+	ResetLockout *lockout.Guard
+	Audit        *audit.Recorder
+	BasicClients *basicauth.Store
+	ApiKeys      *apikey.Store
+	Signatures   *signature.Verifier
+	SecretBox    *secretbox.Box
+	Passwords    *password.Policy
+	Mailer       mailer.Mailer
+	ResetMails   *worker.Pool
+	OAuthClients *oauth.Store
+	Oidc         *oidc.Provider
+}
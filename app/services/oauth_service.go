@@ -0,0 +1,188 @@
+package services
+
+import (
+	"application/app/enums"
+	apperror "application/app/error"
+	"application/app/models"
+	"application/app/web"
+	"application/pkg/audit"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/oauth"
+	"application/pkg/rbac"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultOAuthTokenLifetime = time.Hour
+
+	// oauthAccountPrefix keeps client ids apart from admin usernames in the lockout counters.
+	oauthAccountPrefix = "oauth:"
+)
+
+// ClientCredentials are the credentials an OAuth2 client presented, either
+// with HTTP Basic or in the form.
+type ClientCredentials struct {
+	ClientId     string
+	ClientSecret string
+}
+
+// IssueClientToken implements the client credentials grant of RFC 6749
+// section 4.4. Without a requested scope the token carries every scope of the client.
+func (s *Service) IssueClientToken(request *web.TokenRequest, credentials *ClientCredentials, ipAddress string) (*web.TokenResponse, error) {
+	if request.GrantType == "" {
+		return nil, oauthError(oauth.ErrInvalidRequest, "issue client token")
+	}
+
+	if request.GrantType != oauth.GrantTypeClientCredentials {
+		return nil, oauthError(oauth.ErrUnsupportedGrantType, "issue client token")
+	}
+
+	client, err := s.authenticateOAuthClient(credentials, ipAddress, "issue client token")
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, err := oauth.GrantScopes(client, request.Scope)
+	if err != nil {
+		return nil, oauthError(err, "issue client token")
+	}
+
+	lifetime := s.config.OAuthTokenLifetime
+	if lifetime <= 0 {
+		lifetime = DefaultOAuthTokenLifetime
+	}
+
+	token, err := s.deps.Jwt.IssueClientJwt(&pkgjwt.IssueClientJwtPayload{
+		ClientId: client.ClientId,
+		Scopes:   scopes,
+		Lifetime: lifetime,
+	})
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "issue client token")
+	}
+
+	return &web.TokenResponse{
+		AccessToken: token.Token,
+		TokenType:   pkgjwt.TokenTypeBearer,
+		ExpiresIn:   int64(lifetime.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// IntrospectToken implements RFC 7662 for the client and admin access tokens
+// issued here. Only clients granted the oauth:introspect scope may call it.
+func (s *Service) IntrospectToken(request *web.IntrospectionRequest, credentials *ClientCredentials, ipAddress string) (*web.IntrospectionResponse, error) {
+	client, err := s.authenticateOAuthClient(credentials, ipAddress, "introspect token")
+	if err != nil {
+		return nil, err
+	}
+
+	if !rbac.Matches(client.Scopes, oauth.ScopeIntrospect) {
+		return nil, oauthError(oauth.ErrInsufficientScope, "introspect token")
+	}
+
+	if request.Token == "" {
+		return nil, oauthError(oauth.ErrInvalidRequest, "introspect token")
+	}
+
+	inactive := &web.IntrospectionResponse{Active: false}
+
+	if pkgjwt.TokenUse(request.Token) == pkgjwt.TokenUseClientCredentials {
+		claims, err := s.deps.Jwt.VerifyClientJwt(request.Token)
+		if err != nil || s.deps.Revocation.IsRevoked(claims.Jti, 0, claims.IssuedAt) {
+			return inactive, nil
+		}
+
+		owner, err := s.deps.OAuthClients.ActiveClient(s.ctx, claims.ClientId)
+		if err != nil {
+			return nil, apperror.NewErrorTrace(err, "introspect token")
+		}
+
+		if owner == nil {
+			return inactive, nil
+		}
+
+		return &web.IntrospectionResponse{
+			Active:    true,
+			Scope:     strings.Join(claims.Scopes, " "),
+			ClientId:  claims.ClientId,
+			TokenType: pkgjwt.TokenTypeBearer,
+			Exp:       claims.Exp,
+			Iat:       claims.Iat,
+			Nbf:       claims.Nbf,
+			Sub:       claims.Sub,
+			Aud:       claims.Aud,
+			Iss:       claims.Iss,
+			Jti:       claims.Jti,
+		}, nil
+	}
+
+	claims, err := s.deps.Jwt.VerifyJwt(request.Token)
+	if err != nil || s.deps.Revocation.IsRevoked(claims.Jti, claims.Id, claims.IssuedAt) {
+		return inactive, nil
+	}
+
+	return &web.IntrospectionResponse{
+		Active:    true,
+		Username:  claims.Sub,
+		TokenType: pkgjwt.TokenTypeBearer,
+		Exp:       claims.Exp,
+		Iat:       claims.Iat,
+		Sub:       claims.Sub,
+		Iss:       s.deps.Jwt.Issuer,
+		Jti:       claims.Jti,
+	}, nil
+}
+
+// authenticateOAuthClient authenticates the client behind the brute-force guard.
+func (s *Service) authenticateOAuthClient(credentials *ClientCredentials, ipAddress string, context string) (*models.OAuthClient, error) {
+	if credentials == nil || credentials.ClientId == "" {
+		return nil, oauthError(oauth.ErrInvalidClient, context)
+	}
+
+	account := oauthAccountPrefix + credentials.ClientId
+
+	if err := s.deps.Lockout.Check(s.ctx, account, ipAddress); err != nil {
+		return nil, s.lockedLogin(err, account, ipAddress)
+	}
+
+	client, err := s.deps.OAuthClients.Authenticate(s.ctx, credentials.ClientId, credentials.ClientSecret)
+
+	var oauthErr *oauth.Error
+	if errors.As(err, &oauthErr) {
+		s.deps.Audit.Record(s.ctx, audit.Event{
+			Name:      enums.AuditEventOAuthClientFailed,
+			Actor:     account,
+			IpAddress: ipAddress,
+			Metadata:  map[string]any{"reason": oauthErr.Code},
+		})
+		s.countFailure(account, ipAddress)
+
+		return nil, oauthError(err, context)
+	}
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, context)
+	}
+
+	if err := s.deps.Lockout.Success(s.ctx, account); err != nil {
+		log.Error().Err(err).Str("client_id", client.ClientId).Msg("[oauth] failed to reset failed attempts")
+	}
+
+	return client, nil
+}
+
+// oauthError carries the status code of an RFC 6749 error.
+func oauthError(err error, context string) error {
+	trace := apperror.NewErrorTrace(err, context)
+
+	var oauthErr *oauth.Error
+	if errors.As(err, &oauthErr) {
+		trace.Status(oauthErr.StatusCode)
+	}
+
+	return trace
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"application/app/enums"
+	apperror "application/app/error"
+	"application/app/models"
+	"application/app/web"
+	"application/pkg/audit"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/oidc"
+	"application/pkg/util"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultOidcFlowLifetime = 10 * time.Minute
+	DefaultOidcRoleClaim    = "groups"
+
+	RevokeReasonOidcFlowUsed = "oidc_flow_used"
+
+	oidcUsernameMaxLength = 64
+	// oidcPasswordLength is the length of the random password of provisioned admins.
+	oidcPasswordLength = 48
+)
+
+var (
+	ErrOidcUnavailable     = apperror.New(enums.ErrorCodeSsoUnavailable, "single sign-on is not configured")
+	ErrInvalidOidcFlow     = apperror.New(enums.ErrorCodeInvalidSsoFlow, "invalid or expired single sign-on attempt")
+	ErrOidcLoginFailed     = apperror.New(enums.ErrorCodeSsoFailed, "single sign-on failed")
+	ErrOidcUnknownUser     = apperror.New(enums.ErrorCodeSsoUnknownUser, "no admin account is linked to this identity")
+	ErrOidcNoRole          = apperror.New(enums.ErrorCodeSsoNoRole, "the identity provider grants no admin role")
+	ErrOidcEmailConflict   = apperror.New(enums.ErrorCodeSsoEmailConflict, "an admin with this email already exists")
+	ErrOidcAccountDisabled = apperror.New(enums.ErrorCodeAccountDisabled, "the admin account is disabled")
+
+	oidcUsernameInvalid = regexp.MustCompile(`[^a-z0-9._-]+`)
+)
+
+// StartOidcLogin returns the authorization URL of the identity provider and
+// the flow token the browser must send back to OidcLogin. The PKCE code
+// verifier only travels encrypted inside the signed flow token.
+func (s *Service) StartOidcLogin() (*web.OidcAuthorization, error) {
+	if s.deps.Oidc == nil || s.deps.SecretBox == nil {
+		return nil, apperror.NewErrorTrace(ErrOidcUnavailable, "start oidc login").Status(http.StatusServiceUnavailable)
+	}
+
+	state, nonce, verifier := oidc.RandomValue(), oidc.RandomValue(), oidc.RandomValue()
+
+	authorizationUrl, err := s.deps.Oidc.AuthCodeUrl(s.ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "start oidc login").Status(http.StatusBadGateway)
+	}
+
+	sealedVerifier, err := s.deps.SecretBox.Seal([]byte(verifier))
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "start oidc login")
+	}
+
+	lifetime := s.config.OidcFlowLifetime
+	if lifetime <= 0 {
+		lifetime = DefaultOidcFlowLifetime
+	}
+
+	flow, err := pkgjwt.IssueClaims(s.deps.Jwt, &pkgjwt.Claims[pkgjwt.OidcFlowClaims]{
+		RegisteredClaims: pkgjwt.RegisteredClaims{Subject: pkgjwt.TokenUseOidcFlow},
+		Custom: pkgjwt.OidcFlowClaims{
+			State:        state,
+			Nonce:        nonce,
+			CodeVerifier: sealedVerifier,
+			Use:          pkgjwt.TokenUseOidcFlow,
+		},
+	}, lifetime)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "start oidc login")
+	}
+
+	return &web.OidcAuthorization{
+		AuthorizationUrl: authorizationUrl,
+		FlowToken:        flow.Token,
+		ExpiredAt:        flow.ExpiredAt,
+	}, nil
+}
+
+// OidcLogin completes the authorization code flow: the code is exchanged with
+// the PKCE verifier, the ID token is verified and its subject is mapped to a
+// local admin, provisioned on the fly when enabled. A flow token is accepted
+// once. The role of provisioned admins follows the groups granted by the
+// identity provider. Admins with two-factor authentication get an MFA
+// challenge as with a password login, since the identity provider may not
+// have asked for a second factor.
+func (s *Service) OidcLogin(request *web.OidcCallbackRequest, userAgent string, ipAddress string) (*web.Session, *web.MfaChallenge, error) {
+	if s.deps.Oidc == nil || s.deps.SecretBox == nil {
+		return nil, nil, apperror.NewErrorTrace(ErrOidcUnavailable, "oidc login").Status(http.StatusServiceUnavailable)
+	}
+
+	flow, err := pkgjwt.VerifyClaims[pkgjwt.OidcFlowClaims](s.deps.Jwt, request.FlowToken)
+	if err != nil || flow.Custom.Use != pkgjwt.TokenUseOidcFlow ||
+		subtle.ConstantTimeCompare([]byte(flow.Custom.State), []byte(request.State)) != 1 ||
+		s.deps.Revocation.IsRevoked(flow.Id, 0, flow.IssuedAtTime()) {
+		return nil, nil, apperror.NewErrorTrace(ErrInvalidOidcFlow, "oidc login").Status(http.StatusBadRequest)
+	}
+
+	verifier, err := s.deps.SecretBox.Open(flow.Custom.CodeVerifier)
+	if err != nil {
+		return nil, nil, apperror.NewErrorTrace(ErrInvalidOidcFlow, "oidc login").Status(http.StatusBadRequest)
+	}
+
+	// the flow is single use, even when the exchange fails
+	if err := s.deps.Revocation.RevokeToken(s.ctx, flow.Id, 0, time.Unix(flow.ExpiresAt, 0), RevokeReasonOidcFlowUsed); err != nil {
+		return nil, nil, apperror.NewErrorTrace(err, "oidc login")
+	}
+
+	token, err := s.deps.Oidc.Exchange(s.ctx, request.Code, string(verifier))
+	if err != nil {
+		return nil, nil, s.failedOidcLogin(err, "", ipAddress, http.StatusUnauthorized)
+	}
+
+	claims, err := s.deps.Oidc.VerifyIdToken(s.ctx, token.IdToken, flow.Custom.Nonce)
+	if err != nil {
+		return nil, nil, s.failedOidcLogin(err, "", ipAddress, http.StatusUnauthorized)
+	}
+
+	role := s.oidcRole(claims)
+	if role == "" {
+		return nil, nil, s.failedOidcLogin(ErrOidcNoRole, claims.Subject, ipAddress, http.StatusForbidden)
+	}
+
+	user, identity, err := s.oidcUser(claims, role, ipAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !user.IsActive {
+		return nil, nil, s.failedOidcLogin(ErrOidcAccountDisabled, claims.Subject, ipAddress, http.StatusForbidden)
+	}
+
+	// admins created or linked by hand keep the role given in the admin panel
+	if identity.Provisioned && user.Role != role {
+		if err := s.repository.UpdateAdminUserRole(s.ctx, user.Id, role); err != nil {
+			return nil, nil, apperror.NewErrorTrace(err, "oidc login")
+		}
+
+		s.deps.Audit.Record(s.ctx, audit.Event{
+			Name:      enums.AuditEventOidcRoleChanged,
+			Actor:     loginAccount(user),
+			IpAddress: ipAddress,
+			Metadata:  map[string]any{"user_id": user.Id, "from": user.Role, "to": role},
+		})
+		user.Role = role
+	}
+
+	if user.TotpEnabledAt != nil {
+		challenge, err := s.issueMfaChallenge(user)
+		return nil, challenge, err
+	}
+
+	session, err := s.completeLogin(user, loginAccount(user), userAgent, ipAddress)
+	return session, nil, err
+}
+
+// oidcUser returns the admin linked to the subject and its identity. Unknown
+// subjects are linked by verified email or provisioned when enabled.
+func (s *Service) oidcUser(claims *oidc.IdTokenClaims, role string, ipAddress string) (*models.AdminUser, *models.AdminIdentity, error) {
+	issuer := strings.TrimSuffix(s.config.OidcIssuerUrl, "/")
+	now := time.Now()
+
+	identity, err := s.repository.FindAdminIdentity(s.ctx, issuer, claims.Subject)
+	if err != nil {
+		return nil, nil, apperror.NewErrorTrace(err, "oidc login")
+	}
+
+	if identity != nil {
+		user, err := s.repository.FindAdminUserById(s.ctx, identity.UserId)
+		if err != nil {
+			return nil, nil, apperror.NewErrorTrace(err, "oidc login")
+		}
+
+		if user == nil {
+			return nil, nil, s.failedOidcLogin(ErrOidcUnknownUser, claims.Subject, ipAddress, http.StatusForbidden)
+		}
+
+		if err := s.repository.UpdateAdminIdentityLastLogin(s.ctx, identity.Id, claims.Email, now); err != nil {
+			log.Error().Err(err).Int64("user_id", user.Id).Msg("[oidc login] failed to update identity")
+		}
+
+		return user, identity, nil
+	}
+
+	var user *models.AdminUser
+	if claims.Email != "" {
+		user, err = s.repository.FindAdminUserByUsernameOrEmail(s.ctx, claims.Email)
+		if err != nil {
+			return nil, nil, apperror.NewErrorTrace(err, "oidc login")
+		}
+	}
+
+	event := enums.AuditEventOidcIdentityLinked
+	provisioned := false
+
+	switch {
+	case user != nil && s.config.OidcLinkByEmail && bool(claims.EmailVerified) && strings.EqualFold(user.Email, claims.Email):
+		// the admin signs in with the identity provider from now on
+	case user != nil:
+		return nil, nil, s.failedOidcLogin(ErrOidcEmailConflict, claims.Subject, ipAddress, http.StatusConflict)
+	case s.config.OidcJitProvisioning:
+		if user, err = s.provisionOidcUser(claims, role); err != nil {
+			return nil, nil, err
+		}
+		event = enums.AuditEventOidcUserProvisioned
+		provisioned = true
+	default:
+		return nil, nil, s.failedOidcLogin(ErrOidcUnknownUser, claims.Subject, ipAddress, http.StatusForbidden)
+	}
+
+	identity = &models.AdminIdentity{
+		UserId:      user.Id,
+		Issuer:      issuer,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		Provisioned: provisioned,
+		LastLoginAt: &now,
+	}
+	if err := s.repository.CreateAdminIdentity(s.ctx, identity); err != nil {
+		return nil, nil, apperror.NewErrorTrace(err, "oidc login")
+	}
+
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:      event,
+		Actor:     loginAccount(user),
+		IpAddress: ipAddress,
+		Metadata:  map[string]any{"user_id": user.Id, "issuer": issuer, "subject": claims.Subject},
+	})
+
+	return user, identity, nil
+}
+
+// provisionOidcUser creates the admin of a first login. Its password is random
+// and never shown, so it can only sign in with the identity provider.
+func (s *Service) provisionOidcUser(claims *oidc.IdTokenClaims, role string) (*models.AdminUser, error) {
+	username, err := s.oidcUsername(claims)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "oidc login")
+	}
+
+	hash, err := util.HashPassword(util.GenerateRandomString(oidcPasswordLength))
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "oidc login")
+	}
+
+	email := claims.Email
+	if email == "" {
+		email = username + "@localhost"
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = username
+	}
+
+	user := &models.AdminUser{
+		Username: username,
+		Email:    email,
+		Name:     name,
+		Password: hash,
+		Role:     role,
+		IsActive: true,
+	}
+
+	if err := s.repository.CreateAdminUser(s.ctx, user); err != nil {
+		return nil, apperror.NewErrorTrace(err, "oidc login")
+	}
+
+	return user, nil
+}
+
+// oidcUsername derives a free username from the preferred username or the email.
+func (s *Service) oidcUsername(claims *oidc.IdTokenClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	base = strings.Trim(oidcUsernameInvalid.ReplaceAllString(strings.ToLower(base), "-"), "-")
+	if base == "" {
+		base = "sso"
+	}
+	if len(base) > oidcUsernameMaxLength-7 {
+		base = base[:oidcUsernameMaxLength-7]
+	}
+
+	username := base
+	for range 5 {
+		existing, err := s.repository.FindAdminUserByUsernameOrEmail(s.ctx, username)
+		if err != nil {
+			return "", err
+		}
+
+		if existing == nil {
+			return username, nil
+		}
+
+		username = base + "-" + util.GenerateRandomAlphaNumericString(6)
+	}
+
+	return "", fmt.Errorf("no free username for %q", base)
+}
+
+// oidcRole maps the groups of the role claim to the highest admin role, or
+// falls back to the default role.
+func (s *Service) oidcRole(claims *oidc.IdTokenClaims) string {
+	claim := s.config.OidcRoleClaim
+	if claim == "" {
+		claim = DefaultOidcRoleClaim
+	}
+
+	var groups []string
+	switch value := claims.Claims[claim].(type) {
+	case string:
+		groups = strings.Fields(value)
+	case []any:
+		for _, item := range value {
+			if group, ok := item.(string); ok {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	var granted []enums.CodeAdminRole
+	for _, group := range groups {
+		if role := enums.CodeAdminRole(s.config.OidcRoleMapping[group]); role.IsValid() {
+			granted = append(granted, role)
+		}
+	}
+
+	// AdminRoles is ordered from the most to the least privileged role
+	for _, role := range enums.AdminRoles() {
+		if slices.Contains(granted, role) {
+			return role.String()
+		}
+	}
+
+	if role := enums.CodeAdminRole(s.config.OidcDefaultRole); role.IsValid() {
+		return role.String()
+	}
+
+	return ""
+}
+
+func (s *Service) failedOidcLogin(err error, subject string, ipAddress string, status int) error {
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:      enums.AuditEventOidcLoginFailed,
+		Actor:     subject,
+		IpAddress: ipAddress,
+		Metadata:  map[string]any{"reason": err.Error()},
+	})
+
+	// provider and token errors are only logged, the caller gets a generic error
+	if status == http.StatusUnauthorized {
+		log.Warn().Err(err).Str("ip_address", ipAddress).Msg("[oidc login] failed")
+		err = ErrOidcLoginFailed
+	}
+
+	return apperror.NewErrorTrace(err, "oidc login").Status(status)
+}
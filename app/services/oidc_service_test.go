@@ -0,0 +1,304 @@
+package services
+
+import (
+	"application/app/models"
+	"application/app/web"
+	"application/config"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/oidc"
+	"application/pkg/secretbox"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testIdp approves every authorization for its claims and checks the PKCE
+// verifier of the last authorization URL handed to it.
+type testIdp struct {
+	server *httptest.Server
+	signer *pkgjwt.JwtAdapter
+
+	mu        sync.Mutex
+	claims    map[string]any
+	challenge string
+	nonce     string
+}
+
+func newTestIdp(t *testing.T) *testIdp {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := pkgjwt.NewAsymmetricKey(&privateKey.PublicKey, privateKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := pkgjwt.NewKeySet()
+	keys.SetSigningKey(key)
+
+	idp := &testIdp{claims: map[string]any{"sub": "subject-1"}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		writeIdpJson(w, http.StatusOK, oidc.Metadata{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JwksUri:               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, _ *http.Request) {
+		writeIdpJson(w, http.StatusOK, keys.JWKS())
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+
+		if r.PostForm.Get("code") != "code" || oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != idp.challenge {
+			writeIdpJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		custom := map[string]any{"nonce": idp.nonce}
+		for name, value := range idp.claims {
+			if name != "sub" {
+				custom[name] = value
+			}
+		}
+
+		idToken, err := pkgjwt.IssueClaims(idp.signer, &pkgjwt.Claims[map[string]any]{
+			RegisteredClaims: pkgjwt.RegisteredClaims{Subject: idp.claims["sub"].(string)},
+			Custom:           custom,
+		}, time.Minute)
+		if err != nil {
+			writeIdpJson(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+			return
+		}
+
+		writeIdpJson(w, http.StatusOK, oidc.TokenResponse{AccessToken: "access", TokenType: "Bearer", IdToken: idToken.Token})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	idp.signer = &pkgjwt.JwtAdapter{Issuer: idp.server.URL, Audience: []string{"admin-console"}, Keys: keys}
+
+	return idp
+}
+
+func writeIdpJson(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newOidcTestService(t *testing.T, idp *testIdp, cfg *config.Config) *Service {
+	t.Helper()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	box, err := secretbox.NewBox(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.OidcIssuerUrl = idp.server.URL
+	if cfg.OidcRoleMapping == nil {
+		cfg.OidcRoleMapping = map[string]string{"admins": "ADMIN", "support": "OPERATOR"}
+	}
+
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerUrl:   idp.server.URL,
+		ClientId:    "admin-console",
+		RedirectUrl: "https://admin.example.com/sso",
+	}, idp.server.Client())
+
+	service, _ := newTestService(t, cfg, &Dependencies{Oidc: provider, SecretBox: box},
+		&models.AdminUser{}, &models.AdminIdentity{}, &models.RefreshToken{})
+
+	return service
+}
+
+// startOidcLogin starts a login and lets the identity provider approve it,
+// returning the callback request of the browser.
+func startOidcLogin(t *testing.T, service *Service, idp *testIdp) *web.OidcCallbackRequest {
+	t.Helper()
+
+	authorization, err := service.StartOidcLogin()
+	if err != nil {
+		t.Fatalf("StartOidcLogin() error = %v", err)
+	}
+
+	authorizationUrl, err := url.Parse(authorization.AuthorizationUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := authorizationUrl.Query()
+
+	idp.mu.Lock()
+	idp.challenge = query.Get("code_challenge")
+	idp.nonce = query.Get("nonce")
+	idp.mu.Unlock()
+
+	return &web.OidcCallbackRequest{Code: "code", State: query.Get("state"), FlowToken: authorization.FlowToken}
+}
+
+func TestOidcLoginProvisionsAdmins(t *testing.T) {
+	idp := newTestIdp(t)
+	idp.claims = map[string]any{"sub": "subject-1", "email": "jane@example.com", "preferred_username": "Jane.Doe", "groups": []string{"support"}}
+	service := newOidcTestService(t, idp, &config.Config{OidcJitProvisioning: true})
+
+	session, challenge, err := service.OidcLogin(startOidcLogin(t, service, idp), "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("OidcLogin() error = %v", err)
+	}
+	if session == nil || challenge != nil {
+		t.Fatalf("OidcLogin() = %v, %v, want a session", session, challenge)
+	}
+
+	user, err := service.repository.FindAdminUserByUsernameOrEmail(service.ctx, "jane.doe")
+	if err != nil || user == nil {
+		t.Fatalf("provisioned admin = %v, %v", user, err)
+	}
+	if user.Role != "OPERATOR" || user.Email != "jane@example.com" {
+		t.Errorf("provisioned admin = %+v", user)
+	}
+
+	identity, err := service.repository.FindAdminIdentity(service.ctx, idp.server.URL, "subject-1")
+	if err != nil || identity == nil || !identity.Provisioned || identity.UserId != user.Id {
+		t.Errorf("identity = %+v, %v, want a provisioned identity of the admin", identity, err)
+	}
+}
+
+func TestOidcLoginRejectsUnknownIdentities(t *testing.T) {
+	idp := newTestIdp(t)
+	idp.claims = map[string]any{"sub": "subject-1", "groups": []string{"admins"}}
+	service := newOidcTestService(t, idp, &config.Config{})
+
+	if _, _, err := service.OidcLogin(startOidcLogin(t, service, idp), "test", "127.0.0.1"); !errors.Is(err, ErrOidcUnknownUser) {
+		t.Errorf("OidcLogin() error = %v, want %v", err, ErrOidcUnknownUser)
+	}
+
+	idp.claims = map[string]any{"sub": "subject-1", "groups": []string{"unmapped"}}
+	if _, _, err := service.OidcLogin(startOidcLogin(t, service, idp), "test", "127.0.0.1"); !errors.Is(err, ErrOidcNoRole) {
+		t.Errorf("OidcLogin(no role) error = %v, want %v", err, ErrOidcNoRole)
+	}
+}
+
+func TestOidcFlowIsSingleUse(t *testing.T) {
+	idp := newTestIdp(t)
+	idp.claims = map[string]any{"sub": "subject-1", "groups": []string{"admins"}}
+	service := newOidcTestService(t, idp, &config.Config{OidcJitProvisioning: true})
+
+	request := startOidcLogin(t, service, idp)
+
+	if _, _, err := service.OidcLogin(request, "test", "127.0.0.1"); err != nil {
+		t.Fatalf("OidcLogin() error = %v", err)
+	}
+
+	if _, _, err := service.OidcLogin(request, "test", "127.0.0.1"); !errors.Is(err, ErrInvalidOidcFlow) {
+		t.Errorf("OidcLogin(replayed flow) error = %v, want %v", err, ErrInvalidOidcFlow)
+	}
+
+	tampered := *startOidcLogin(t, service, idp)
+	tampered.State = "other"
+	if _, _, err := service.OidcLogin(&tampered, "test", "127.0.0.1"); !errors.Is(err, ErrInvalidOidcFlow) {
+		t.Errorf("OidcLogin(other state) error = %v, want %v", err, ErrInvalidOidcFlow)
+	}
+}
+
+func TestOidcFlowTokenHidesTheCodeVerifier(t *testing.T) {
+	idp := newTestIdp(t)
+	service := newOidcTestService(t, idp, &config.Config{})
+
+	authorization, err := service.StartOidcLogin()
+	if err != nil {
+		t.Fatalf("StartOidcLogin() error = %v", err)
+	}
+
+	flow, err := pkgjwt.VerifyClaims[pkgjwt.OidcFlowClaims](service.deps.Jwt, authorization.FlowToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorizationUrl, err := url.Parse(authorization.AuthorizationUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the code verifier of the token must not match the challenge sent to the provider
+	if oidc.CodeChallenge(flow.Custom.CodeVerifier) == authorizationUrl.Query().Get("code_challenge") {
+		t.Error("flow token carries the code verifier in clear")
+	}
+
+	verifier, err := service.deps.SecretBox.Open(flow.Custom.CodeVerifier)
+	if err != nil || oidc.CodeChallenge(string(verifier)) != authorizationUrl.Query().Get("code_challenge") {
+		t.Errorf("sealed code verifier = %q, %v, want the verifier of the challenge", verifier, err)
+	}
+}
+
+func TestOidcLoginSyncsTheRoleOfProvisionedAdmins(t *testing.T) {
+	idp := newTestIdp(t)
+	service := newOidcTestService(t, idp, &config.Config{OidcJitProvisioning: true, OidcLinkByEmail: true})
+
+	linked := &models.AdminUser{Username: "linked", Email: "linked@example.com", Role: "VIEWER", IsActive: true}
+	if err := service.repository.CreateAdminUser(service.ctx, linked); err != nil {
+		t.Fatal(err)
+	}
+
+	login := func(claims map[string]any) {
+		t.Helper()
+
+		idp.claims = claims
+		if _, _, err := service.OidcLogin(startOidcLogin(t, service, idp), "test", "127.0.0.1"); err != nil {
+			t.Fatalf("OidcLogin() error = %v", err)
+		}
+	}
+
+	role := func(username string) string {
+		t.Helper()
+
+		user, err := service.repository.FindAdminUserByUsernameOrEmail(service.ctx, username)
+		if err != nil || user == nil {
+			t.Fatalf("admin %q = %v, %v", username, user, err)
+		}
+		return user.Role
+	}
+
+	login(map[string]any{"sub": "provisioned", "preferred_username": "provisioned", "groups": []string{"support"}})
+	login(map[string]any{"sub": "provisioned", "groups": []string{"admins"}})
+	if got := role("provisioned"); got != "ADMIN" {
+		t.Errorf("role of the provisioned admin = %q, want %q", got, "ADMIN")
+	}
+
+	login(map[string]any{"sub": "linked", "email": "linked@example.com", "email_verified": true, "groups": []string{"admins"}})
+	login(map[string]any{"sub": "linked", "groups": []string{"admins"}})
+	if got := role("linked"); got != "VIEWER" {
+		t.Errorf("role of the linked admin = %q, want %q", got, "VIEWER")
+	}
+}
+
+func TestStartOidcLoginWithoutSecretBox(t *testing.T) {
+	idp := newTestIdp(t)
+	service := newOidcTestService(t, idp, &config.Config{})
+	service.deps.SecretBox = nil
+
+	if _, err := service.StartOidcLogin(); !errors.Is(err, ErrOidcUnavailable) {
+		t.Errorf("StartOidcLogin() error = %v, want %v", err, ErrOidcUnavailable)
+	}
+}
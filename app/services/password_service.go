@@ -0,0 +1,265 @@
+package services
+
+import (
+	"application/app/enums"
+	apperror "application/app/error"
+	"application/app/models"
+	"application/app/web"
+	"application/pkg/audit"
+	"application/pkg/lockout"
+	"application/pkg/mailer"
+	"application/pkg/password"
+	"application/pkg/util"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPasswordResetTokenLifetime = 30 * time.Minute
+	DefaultPasswordResetThreshold     = 3
+	DefaultPasswordResetIpThreshold   = 10
+
+	// PasswordResetLockoutPrefix keeps the reset request counters apart from the failed logins.
+	PasswordResetLockoutPrefix = "reset_"
+
+	passwordResetTokenLength = 48
+)
+
+var (
+	ErrInvalidPasswordResetToken = apperror.New(enums.ErrorCodeInvalidPasswordResetToken, "invalid or expired password reset token")
+	ErrPasswordResetUnavailable  = apperror.New(enums.ErrorCodePasswordResetUnavailable, "password reset is not configured")
+)
+
+// ForgotPassword mails a single-use reset link to the admin. It answers the
+// same way and in the same time whether or not the account exists, so that
+// usernames cannot be enumerated: the account lookup, the token and the mail
+// are handled in the background by the reset mail workers. Requests are
+// throttled per username or email and per IP address.
+func (s *Service) ForgotPassword(request *web.ForgotPasswordRequest, ipAddress string) error {
+	if s.deps.Mailer == nil || s.deps.ResetMails == nil || s.config.PasswordResetUrl == "" {
+		return apperror.NewErrorTrace(ErrPasswordResetUnavailable, "forgot password").Status(http.StatusServiceUnavailable)
+	}
+
+	identifier := strings.ToLower(strings.TrimSpace(request.Username))
+
+	var locked *lockout.LockedError
+	if err := s.deps.ResetLockout.Check(s.ctx, identifier, ipAddress); errors.As(err, &locked) {
+		return apperror.NewErrorTrace(locked, "forgot password").Status(http.StatusTooManyRequests).
+			WithCode(enums.ErrorCodeAccountLocked).
+			WithParams(map[string]any{"retryAfter": locked.RetryAfter()})
+	} else if err != nil {
+		return apperror.NewErrorTrace(err, "forgot password")
+	}
+
+	// every request counts, the counter only goes back to zero after the window
+	if _, err := s.deps.ResetLockout.Failure(s.ctx, identifier, ipAddress); err != nil {
+		log.Error().Err(err).Str("ip_address", ipAddress).Msg("[forgot password] failed to count request")
+	}
+
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:      enums.AuditEventPasswordResetRequested,
+		Actor:     identifier,
+		IpAddress: ipAddress,
+	})
+
+	err := s.deps.ResetMails.Submit(func(ctx context.Context) {
+		s.sendPasswordReset(ctx, identifier, ipAddress)
+	})
+	if err != nil {
+		// answer as usual, a full queue must not tell whether the account exists
+		log.Warn().Err(err).Str("ip_address", ipAddress).Msg("[forgot password] reset request dropped")
+	}
+
+	return nil
+}
+
+// sendPasswordReset creates the reset token of the account and mails it. It
+// runs on a reset mail worker after the response, so errors are only logged.
+func (s *Service) sendPasswordReset(ctx context.Context, identifier string, ipAddress string) {
+	user, err := s.repository.FindAdminUserByUsernameOrEmail(ctx, identifier)
+	if err != nil {
+		log.Error().Err(err).Msg("[forgot password] failed to find admin user")
+		return
+	}
+
+	if user == nil || !user.IsActive || user.Email == "" {
+		return
+	}
+
+	now := time.Now()
+	token := util.GenerateRandomString(passwordResetTokenLength)
+
+	// a new link replaces the links sent before
+	err = s.repository.WithTransaction(func(tx *gorm.DB) error {
+		repo := s.repository.WithTx(tx)
+
+		if err := repo.InvalidatePasswordResetTokens(ctx, user.Id, now); err != nil {
+			return err
+		}
+
+		return repo.CreatePasswordResetToken(ctx, &models.PasswordResetToken{
+			UserId:      user.Id,
+			TokenHash:   hashPasswordResetToken(token),
+			ExpiresAt:   now.Add(s.passwordResetTokenLifetime()),
+			RequestedIp: ipAddress,
+		})
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", user.Id).Msg("[forgot password] failed to create reset token")
+		return
+	}
+
+	message, err := s.passwordResetMessage(user, token)
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", user.Id).Msg("[forgot password] failed to build reset mail")
+		return
+	}
+
+	if err := s.deps.Mailer.Send(ctx, message); err != nil {
+		log.Error().Err(err).Int64("user_id", user.Id).Msg("[forgot password] failed to send reset mail")
+	}
+}
+
+// ResetPassword sets a new password with a reset token. The token is consumed,
+// every session of the admin is revoked and the failed logins are cleared.
+func (s *Service) ResetPassword(request *web.ResetPasswordRequest, ipAddress string) error {
+	var user *models.AdminUser
+
+	err := s.repository.WithTransaction(func(tx *gorm.DB) error {
+		repo := s.repository.WithTx(tx)
+
+		token, err := repo.FindPasswordResetTokenByHashForUpdate(s.ctx, hashPasswordResetToken(request.Token))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if token == nil || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+			return ErrInvalidPasswordResetToken
+		}
+
+		user, err = repo.FindAdminUserById(s.ctx, token.UserId)
+		if err != nil {
+			return err
+		}
+
+		if user == nil || !user.IsActive {
+			return ErrInvalidPasswordResetToken
+		}
+
+		if err := s.deps.Passwords.Validate(request.Password, user.Username, user.Email); err != nil {
+			return err
+		}
+
+		hash, err := util.HashPassword(request.Password)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.UpdateAdminUserPassword(s.ctx, user.Id, hash); err != nil {
+			return err
+		}
+
+		return repo.InvalidatePasswordResetTokens(s.ctx, user.Id, now)
+	})
+
+	var policyErr *password.PolicyError
+	switch {
+	case errors.As(err, &policyErr):
+		return apperror.NewErrorTrace(err, "reset password").
+			Status(http.StatusBadRequest).
+			WithCode(enums.ErrorCodePasswordPolicy).
+			WithDetails(map[string]any{"violations": policyErr.Violations})
+	case errors.Is(err, ErrInvalidPasswordResetToken):
+		return apperror.NewErrorTrace(err, "reset password").Status(http.StatusBadRequest)
+	case err != nil:
+		return apperror.NewErrorTrace(err, "reset password")
+	}
+
+	if err := s.RevokeUserTokens(user.Id, RevokeReasonPasswordReset); err != nil {
+		log.Error().Err(err).Int64("user_id", user.Id).Msg("[reset password] failed to revoke sessions")
+	}
+
+	if err := s.deps.Lockout.Success(s.ctx, loginAccount(user)); err != nil {
+		log.Error().Err(err).Int64("user_id", user.Id).Msg("[reset password] failed to reset failed attempts")
+	}
+
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:      enums.AuditEventPasswordReset,
+		Actor:     loginAccount(user),
+		IpAddress: ipAddress,
+		Metadata:  map[string]any{"user_id": user.Id},
+	})
+
+	return nil
+}
+
+// rehashPassword upgrades the stored hash of a verified password made with
+// bcrypt or with other argon2id parameters than the current ones.
+func (s *Service) rehashPassword(user *models.AdminUser, plain string) {
+	if !util.PasswordNeedsRehash(user.Password) {
+		return
+	}
+
+	hash, err := util.HashPassword(plain)
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", user.Id).Msg("[login] failed to rehash password")
+		return
+	}
+
+	if err := s.repository.UpdateAdminUserPassword(s.ctx, user.Id, hash); err != nil {
+		log.Error().Err(err).Int64("user_id", user.Id).Msg("[login] failed to store rehashed password")
+		return
+	}
+
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:     enums.AuditEventPasswordRehashed,
+		Actor:    loginAccount(user),
+		Metadata: map[string]any{"user_id": user.Id},
+	})
+}
+
+func (s *Service) passwordResetMessage(user *models.AdminUser, token string) (*mailer.Message, error) {
+	link, err := url.Parse(s.config.PasswordResetUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid password reset url: %w", err)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	lifetime := s.passwordResetTokenLifetime()
+
+	return &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nOpen the link below to choose a new password. It can be used once and expires in %s.\n\n%s\n\nIf you did not ask to reset your password you can ignore this mail.\n",
+			user.Name, lifetime, link.String(),
+		),
+	}, nil
+}
+
+func (s *Service) passwordResetTokenLifetime() time.Duration {
+	if s.config.PasswordResetTokenLifetime > 0 {
+		return s.config.PasswordResetTokenLifetime
+	}
+
+	return DefaultPasswordResetTokenLifetime
+}
+
+func hashPasswordResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
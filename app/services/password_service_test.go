@@ -0,0 +1,213 @@
+package services
+
+import (
+	"application/app/enums"
+	"application/app/models"
+	"application/app/web"
+	"application/config"
+	"application/pkg/lockout"
+	"application/pkg/mailer"
+	"application/pkg/password"
+	"application/pkg/util"
+	"application/pkg/worker"
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// memoryMailer hands the sent messages to the test.
+type memoryMailer struct {
+	sent chan *mailer.Message
+}
+
+func (m *memoryMailer) Send(_ context.Context, message *mailer.Message) error {
+	m.sent <- message
+	return nil
+}
+
+func newPasswordTestService(t *testing.T, resetMails *worker.Pool) (*Service, *gorm.DB, *memoryMailer, *models.AdminUser) {
+	t.Helper()
+
+	util.SetPasswordHashParams(util.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1})
+	t.Cleanup(func() { util.SetPasswordHashParams(util.DefaultArgon2idParams) })
+
+	policy, err := password.NewPolicy(password.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mail := &memoryMailer{sent: make(chan *mailer.Message, 10)}
+
+	service, db := newTestService(t, &config.Config{PasswordResetUrl: "https://admin.example.com/reset"}, &Dependencies{
+		Passwords:  policy,
+		Mailer:     mail,
+		ResetMails: resetMails,
+	}, &models.AdminUser{}, &models.RefreshToken{}, &models.PasswordResetToken{})
+	service.deps.ResetLockout = lockout.NewGuard(service.repository, lockout.Config{Threshold: 3, Prefix: PasswordResetLockoutPrefix})
+
+	hash, err := util.HashPassword("Correct-Horse-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.AdminUser{Username: "admin", Email: "admin@example.com", Name: "Admin", Password: hash, Role: "ADMIN", IsActive: true}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return service, db, mail, user
+}
+
+func startedPool(t *testing.T) *worker.Pool {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	pool := worker.NewPool(1, 10)
+	pool.Start(ctx)
+
+	return pool
+}
+
+// resetToken waits for the reset mail and returns the token of its link.
+func resetToken(t *testing.T, mail *memoryMailer) string {
+	t.Helper()
+
+	select {
+	case message := <-mail.sent:
+		for _, field := range strings.Fields(message.Body) {
+			if link, err := url.Parse(field); err == nil && link.Host == "admin.example.com" {
+				return link.Query().Get("token")
+			}
+		}
+		t.Fatalf("reset mail without a link: %s", message.Body)
+	case <-time.After(5 * time.Second):
+		t.Fatal("reset mail not sent")
+	}
+
+	return ""
+}
+
+func TestForgotAndResetPassword(t *testing.T) {
+	service, db, mail, user := newPasswordTestService(t, startedPool(t))
+
+	if err := service.ForgotPassword(&web.ForgotPasswordRequest{Username: " Admin@Example.com "}, "127.0.0.1"); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	token := resetToken(t, mail)
+
+	err := service.ResetPassword(&web.ResetPasswordRequest{Token: token, Password: "admin"}, "127.0.0.1")
+	var policyErr *password.PolicyError
+	if !errors.As(err, &policyErr) || !hasErrorCode(err, enums.ErrorCodePasswordPolicy) {
+		t.Fatalf("ResetPassword() with a weak password error = %v, want a policy violation", err)
+	}
+
+	if err := service.ResetPassword(&web.ResetPasswordRequest{Token: token, Password: "Battery-Staple-9"}, "127.0.0.1"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+
+	var stored models.AdminUser
+	if err := db.First(&stored, user.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := util.ComparePassword(stored.Password, "Battery-Staple-9"); err != nil {
+		t.Errorf("new password does not match: %v", err)
+	}
+
+	if err := service.ResetPassword(&web.ResetPasswordRequest{Token: token, Password: "Another-Staple-9"}, "127.0.0.1"); !errors.Is(err, ErrInvalidPasswordResetToken) {
+		t.Errorf("ResetPassword() with a used token error = %v, want %v", err, ErrInvalidPasswordResetToken)
+	}
+}
+
+func TestForgotPasswordOfAnUnknownAccount(t *testing.T) {
+	service, _, mail, _ := newPasswordTestService(t, startedPool(t))
+
+	if err := service.ForgotPassword(&web.ForgotPasswordRequest{Username: "nobody"}, "127.0.0.1"); err != nil {
+		t.Fatalf("ForgotPassword() error = %v, want the same answer as for an account", err)
+	}
+
+	select {
+	case message := <-mail.sent:
+		t.Errorf("mail sent to %s for an unknown account", message.To)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestForgotPasswordDropsRequestsWhenTheQueueIsFull(t *testing.T) {
+	// the workers are not started, so the queue keeps the first request
+	service, _, _, _ := newPasswordTestService(t, worker.NewPool(1, 1))
+
+	for _, ipAddress := range []string{"10.0.0.1", "10.0.0.2"} {
+		if err := service.ForgotPassword(&web.ForgotPasswordRequest{Username: "admin"}, ipAddress); err != nil {
+			t.Errorf("ForgotPassword() error = %v, want the usual answer", err)
+		}
+	}
+
+	if err := service.deps.ResetMails.Submit(func(context.Context) {}); !errors.Is(err, worker.ErrQueueFull) {
+		t.Errorf("Submit() error = %v, want the queue still full", err)
+	}
+}
+
+func TestForgotPasswordIsThrottled(t *testing.T) {
+	service, _, _, _ := newPasswordTestService(t, startedPool(t))
+
+	for range 3 {
+		if err := service.ForgotPassword(&web.ForgotPasswordRequest{Username: "admin"}, "127.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := service.ForgotPassword(&web.ForgotPasswordRequest{Username: "admin"}, "127.0.0.1"); !errors.Is(err, lockout.ErrLocked) {
+		t.Errorf("ForgotPassword() error = %v, want %v", err, lockout.ErrLocked)
+	}
+}
+
+func TestLoginRehashesThePassword(t *testing.T) {
+	service, db, _, user := newPasswordTestService(t, nil)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("Correct-Horse-7"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(user).Update("password", string(legacy)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := service.Login(&web.LoginRequest{Username: "admin", Password: "Correct-Horse-7"}, "agent", "127.0.0.1"); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	var stored models.AdminUser
+	if err := db.First(&stored, user.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !util.IsArgon2idHash(stored.Password) || util.PasswordNeedsRehash(stored.Password) {
+		t.Errorf("password hash = %q, want an argon2id hash with the current parameters", stored.Password)
+	}
+
+	var events int64
+	if err := db.Model(&models.AuditEvent{}).Where("event = ?", enums.AuditEventPasswordRehashed.String()).Count(&events).Error; err != nil || events != 1 {
+		t.Errorf("rehash audit events = %d, %v, want 1", events, err)
+	}
+
+	// the upgraded hash verifies and is kept on the next login
+	if _, _, err := service.Login(&web.LoginRequest{Username: "admin", Password: "Correct-Horse-7"}, "agent", "127.0.0.1"); err != nil {
+		t.Fatalf("Login() after the rehash error = %v", err)
+	}
+	var again models.AdminUser
+	if err := db.First(&again, user.Id).Error; err != nil || again.Password != stored.Password {
+		t.Errorf("password hash changed on the second login")
+	}
+}
+
+func hasErrorCode(err error, code enums.CodeError) bool {
+	var coded interface{ ErrorCode() enums.CodeError }
+	return errors.As(err, &coded) && coded.ErrorCode() == code
+}
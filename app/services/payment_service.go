@@ -0,0 +1,17 @@
+package services
+
+import (
+	"application/app/enums"
+	"application/app/web"
+	"application/pkg/audit"
+)
+
+// ReceivePaymentCallback records the payment status reported by a partner.
+func (s *Service) ReceivePaymentCallback(client string, ipAddress string, request *web.PaymentCallbackRequest) {
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:      enums.AuditEventPaymentCallbackReceived,
+		Actor:     client,
+		IpAddress: ipAddress,
+		Metadata:  map[string]any{"payment_id": request.PaymentId, "status": request.Status, "reference": request.Reference},
+	})
+}
@@ -10,9 +10,10 @@ type Service struct {
 	ctx        context.Context
 	config     *config.Config
 	repository *repositories.RepositoryContext
+	deps       *Dependencies
 }
 
-func NewService(ctx context.Context, r *repositories.RepositoryContext, cfg *config.Config) *Service {
+func NewService(ctx context.Context, r *repositories.RepositoryContext, cfg *config.Config, deps *Dependencies) *Service {
 
-	return &Service{ctx: ctx, config: cfg, repository: r}
+	return &Service{ctx: ctx, config: cfg, repository: r, deps: deps}
 }
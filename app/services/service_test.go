@@ -0,0 +1,46 @@
+package services
+
+import (
+	"application/app/models"
+	"application/app/repositories/repositorytest"
+	"application/config"
+	"application/pkg/audit"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/lockout"
+	"application/pkg/revocation"
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// newTestService creates a service on an in-memory database with the tables
+// of the models. Dependencies left nil get a JWT adapter signing with a test
+// secret, and a revocation store, an audit recorder and a lockout guard with
+// the default limits on the database.
+func newTestService(t *testing.T, cfg *config.Config, deps *Dependencies, tables ...any) (*Service, *gorm.DB) {
+	t.Helper()
+
+	rc, db := repositorytest.Open(t, append(tables, &models.TokenRevocation{}, &models.AuditEvent{}, &models.LoginAttempt{})...)
+
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	if deps == nil {
+		deps = &Dependencies{}
+	}
+	if deps.Jwt == nil {
+		deps.Jwt = pkgjwt.NewJwtAdapter("test", "secret")
+	}
+	if deps.Revocation == nil {
+		deps.Revocation = revocation.NewStore(rc, 0, 0)
+	}
+	if deps.Audit == nil {
+		deps.Audit = audit.NewRecorder(rc)
+	}
+	if deps.Lockout == nil {
+		deps.Lockout = lockout.NewGuard(rc, lockout.Config{})
+	}
+
+	return NewService(context.Background(), rc, cfg, deps), db
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"application/app/enums"
+	apperror "application/app/error"
+	"application/app/models"
+	"application/app/web"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/util"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultAccessTokenLifetime  = 15 * time.Minute
+	DefaultRefreshTokenLifetime = 720 * time.Hour
+
+	refreshTokenLength = 48
+
+	RevokeReasonReuseDetected = "reuse_detected"
+	RevokeReasonUserRevoked   = "user_revoked"
+	RevokeReasonLogout        = "logout"
+	RevokeReasonLogoutAll     = "logout_all"
+	RevokeReasonAdminRevoked  = "admin_revoked"
+	RevokeReasonUserDisabled  = "user_disabled"
+	RevokeReasonPasswordReset = "password_reset"
+)
+
+var (
+	ErrInvalidRefreshToken = apperror.New(enums.ErrorCodeInvalidRefreshToken, "invalid refresh token")
+	ErrSessionNotFound     = apperror.New(enums.ErrorCodeSessionNotFound, "session not found")
+)
+
+// SessionPayload identifies the user a new session is created for.
+type SessionPayload struct {
+	UserId    int64
+	Subject   string
+	Roles     []string
+	Locale    string
+	UserAgent string
+	IpAddress string
+}
+
+// CreateSession starts a new refresh token family and issues its first access token.
+func (s *Service) CreateSession(payload *SessionPayload) (*web.Session, error) {
+	now := time.Now()
+
+	refreshToken, record := s.newRefreshToken(now)
+	record.FamilyId = uuid.New().String()
+	record.UserId = payload.UserId
+	record.Subject = payload.Subject
+	record.UserAgent = payload.UserAgent
+	record.IpAddress = payload.IpAddress
+	record.SessionStartedAt = now
+
+	if err := s.repository.CreateRefreshToken(s.ctx, record); err != nil {
+		return nil, apperror.NewErrorTrace(err, "create session")
+	}
+
+	return s.issueSession(record, refreshToken, payload.Roles, payload.Locale)
+}
+
+// RefreshSession exchanges a refresh token for a new access and refresh token.
+// Presenting a refresh token that was already exchanged revokes the whole family.
+func (s *Service) RefreshSession(refreshToken string, userAgent string, ipAddress string) (*web.Session, error) {
+	var (
+		next      *models.RefreshToken
+		nextToken string
+		reused    *models.RefreshToken
+	)
+
+	err := s.repository.WithTransaction(func(tx *gorm.DB) error {
+		repo := s.repository.WithTx(tx)
+
+		current, err := repo.FindRefreshTokenByHashForUpdate(s.ctx, hashRefreshToken(refreshToken))
+		if err != nil {
+			return err
+		}
+
+		if current == nil || current.RevokedAt != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		if current.UsedAt != nil {
+			reused = current
+			return repo.RevokeRefreshTokenFamily(s.ctx, current.FamilyId, RevokeReasonReuseDetected)
+		}
+
+		now := time.Now()
+		if !current.ExpiresAt.After(now) {
+			return ErrInvalidRefreshToken
+		}
+
+		if err := repo.MarkRefreshTokenUsed(s.ctx, current.Id, now); err != nil {
+			return err
+		}
+
+		nextToken, next = s.newRefreshToken(now)
+		next.FamilyId = current.FamilyId
+		next.ParentId = &current.Id
+		next.UserId = current.UserId
+		next.Subject = current.Subject
+		next.UserAgent = userAgent
+		next.IpAddress = ipAddress
+		next.SessionStartedAt = current.SessionStartedAt
+
+		return repo.CreateRefreshToken(s.ctx, next)
+	})
+
+	if reused != nil {
+		log.Warn().
+			Str("family_id", reused.FamilyId).
+			Int64("user_id", reused.UserId).
+			Str("ip_address", ipAddress).
+			Msg("refresh token reuse detected, session revoked")
+		if err := s.revokeSessionAccessTokens(reused.UserId, reused.FamilyId, RevokeReasonReuseDetected); err != nil {
+			log.Error().Err(err).Str("family_id", reused.FamilyId).Msg("failed to revoke access tokens of reused session")
+		}
+		err = ErrInvalidRefreshToken
+	}
+
+	if errors.Is(err, ErrInvalidRefreshToken) {
+		return nil, apperror.NewErrorTrace(err, "refresh session").Status(http.StatusUnauthorized)
+	}
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "refresh session")
+	}
+
+	// roles and the locale may have changed since the session started
+	user, err := s.repository.FindAdminUserById(s.ctx, next.UserId)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "refresh session")
+	}
+
+	if user == nil || !user.IsActive {
+		if err := s.repository.RevokeRefreshTokenFamily(s.ctx, next.FamilyId, RevokeReasonUserDisabled); err != nil {
+			log.Error().Err(err).Str("family_id", next.FamilyId).Msg("failed to revoke session of disabled user")
+		}
+		return nil, apperror.NewErrorTrace(ErrInvalidRefreshToken, "refresh session").Status(http.StatusUnauthorized)
+	}
+
+	return s.issueSession(next, nextToken, []string{user.Role}, user.Locale)
+}
+
+// ListSessions returns the active sessions of the user.
+func (s *Service) ListSessions(userId int64, currentSessionId string) ([]*web.SessionInfo, error) {
+	tokens, err := s.repository.FindActiveRefreshTokensByUserId(s.ctx, userId)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "list sessions")
+	}
+
+	sessions := make([]*web.SessionInfo, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, &web.SessionInfo{
+			Id:         token.FamilyId,
+			UserAgent:  token.UserAgent,
+			IpAddress:  token.IpAddress,
+			StartedAt:  token.SessionStartedAt,
+			LastUsedAt: token.CreatedAt,
+			ExpiredAt:  token.ExpiresAt,
+			Current:    token.FamilyId == currentSessionId,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of the user and denies the access tokens
+// issued for it.
+func (s *Service) RevokeSession(userId int64, sessionId string) error {
+	affected, err := s.repository.RevokeUserRefreshTokenFamily(s.ctx, userId, sessionId, RevokeReasonUserRevoked)
+	if err != nil {
+		return apperror.NewErrorTrace(err, "revoke session")
+	}
+
+	if affected == 0 {
+		return apperror.NewErrorTrace(ErrSessionNotFound, "revoke session").Status(http.StatusNotFound)
+	}
+
+	if err := s.revokeSessionAccessTokens(userId, sessionId, RevokeReasonUserRevoked); err != nil {
+		return apperror.NewErrorTrace(err, "revoke session")
+	}
+
+	return nil
+}
+
+// revokeSessionAccessTokens denies the access tokens of the session that may
+// not have expired yet. Every refresh token issued one access token whose jti
+// is derived from it, so the tokens are found without storing their jti.
+func (s *Service) revokeSessionAccessTokens(userId int64, sessionId string, reason string) error {
+	// tokens are issued right after their refresh token is stored
+	lifetime := s.accessTokenLifetime() + s.config.JwtClockSkew + time.Minute
+
+	records, err := s.repository.FindRefreshTokenFamilySince(s.ctx, sessionId, time.Now().Add(-lifetime))
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if err := s.deps.Revocation.RevokeToken(s.ctx, accessTokenId(record), userId, record.CreatedAt.Add(lifetime), reason); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) issueSession(record *models.RefreshToken, refreshToken string, roles []string, locale string) (*web.Session, error) {
+	session, err := s.deps.Jwt.IssueJwt(&pkgjwt.IssueJwtPayload{
+		TokenId:   accessTokenId(record),
+		Id:        record.UserId,
+		Subject:   record.Subject,
+		SessionId: record.FamilyId,
+		Roles:     roles,
+		Locale:    locale,
+		Lifetime:  s.accessTokenLifetime(),
+	})
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "issue session")
+	}
+
+	session.RefreshToken = refreshToken
+	session.RefreshExpiredAt = record.ExpiresAt.Unix()
+
+	return session, nil
+}
+
+func (s *Service) newRefreshToken(now time.Time) (string, *models.RefreshToken) {
+	token := util.GenerateRandomString(refreshTokenLength)
+
+	return token, &models.RefreshToken{
+		TokenHash: hashRefreshToken(token),
+		ExpiresAt: now.Add(s.refreshTokenLifetime()),
+	}
+}
+
+// accessTokenLifetime prefers the lifetime in minutes. JWT_EXPIRE keeps its
+// original meaning in hours for the deployments still setting it.
+func (s *Service) accessTokenLifetime() time.Duration {
+	switch {
+	case s.config.JwtAccessExpireMinutes > 0:
+		return time.Duration(s.config.JwtAccessExpireMinutes) * time.Minute
+	case s.config.JwtExpire > 0:
+		return time.Duration(s.config.JwtExpire) * time.Hour
+	}
+
+	return DefaultAccessTokenLifetime
+}
+
+func (s *Service) refreshTokenLifetime() time.Duration {
+	if s.config.JwtRefreshExpire <= 0 {
+		return DefaultRefreshTokenLifetime
+	}
+
+	return time.Duration(s.config.JwtRefreshExpire) * time.Hour
+}
+
+// accessTokenId is the jti of the access token issued with the refresh token.
+func accessTokenId(record *models.RefreshToken) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(record.FamilyId+"/"+strconv.FormatInt(record.Id, 10))).String()
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Logout revokes the access token and the session it belongs to, together with
+// the other access tokens issued for the session.
+func (s *Service) Logout(claims *pkgjwt.JwtResponse) error {
+	if claims.Jti != "" {
+		if err := s.deps.Revocation.RevokeToken(s.ctx, claims.Jti, claims.Id, time.Unix(claims.Exp, 0), RevokeReasonLogout); err != nil {
+			return apperror.NewErrorTrace(err, "logout")
+		}
+	}
+
+	if claims.Sid != "" {
+		if err := s.repository.RevokeRefreshTokenFamily(s.ctx, claims.Sid, RevokeReasonLogout); err != nil {
+			return apperror.NewErrorTrace(err, "logout")
+		}
+
+		if err := s.revokeSessionAccessTokens(claims.Id, claims.Sid, RevokeReasonLogout); err != nil {
+			return apperror.NewErrorTrace(err, "logout")
+		}
+	}
+
+	return nil
+}
+
+// RevokeUserTokens logs the user out of every device: all access tokens issued
+// so far are denied and all sessions are revoked.
+func (s *Service) RevokeUserTokens(userId int64, reason string) error {
+	if err := s.deps.Revocation.RevokeUser(s.ctx, userId, s.accessTokenLifetime()+s.config.JwtClockSkew, reason); err != nil {
+		return apperror.NewErrorTrace(err, "revoke user tokens")
+	}
+
+	if err := s.repository.RevokeUserRefreshTokens(s.ctx, userId, reason); err != nil {
+		return apperror.NewErrorTrace(err, "revoke user tokens")
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"application/app/models"
+	"application/app/web"
+	"application/config"
+	pkgjwt "application/pkg/jwt"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func newSessionTestService(t *testing.T) (*Service, *gorm.DB, *models.AdminUser) {
+	t.Helper()
+
+	service, db := newTestService(t, nil, nil, &models.AdminUser{}, &models.RefreshToken{})
+
+	user := &models.AdminUser{Username: "admin", Email: "admin@example.com", Role: "ADMIN", IsActive: true}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return service, db, user
+}
+
+func createTestSession(t *testing.T, service *Service, user *models.AdminUser) string {
+	t.Helper()
+
+	session, err := service.CreateSession(&SessionPayload{UserId: user.Id, Subject: user.Username, Roles: []string{user.Role}})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	return session.RefreshToken
+}
+
+func TestRefreshSessionRotatesTheRefreshToken(t *testing.T) {
+	service, _, user := newSessionTestService(t)
+
+	first, err := service.CreateSession(&SessionPayload{UserId: user.Id, Subject: user.Username, Roles: []string{user.Role}})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	claims, err := service.deps.Jwt.VerifyJwt(first.Token)
+	if err != nil {
+		t.Fatalf("VerifyJwt() error = %v", err)
+	}
+
+	second, err := service.RefreshSession(first.RefreshToken, "agent", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RefreshSession() error = %v", err)
+	}
+
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Errorf("refresh token was not rotated")
+	}
+
+	refreshed, err := service.deps.Jwt.VerifyJwt(second.Token)
+	if err != nil {
+		t.Fatalf("VerifyJwt() error = %v", err)
+	}
+	if refreshed.Sid != claims.Sid || refreshed.Sid == "" {
+		t.Errorf("sid = %q, want the session %q", refreshed.Sid, claims.Sid)
+	}
+
+	if _, err := service.RefreshSession(second.RefreshToken, "agent", "127.0.0.1"); err != nil {
+		t.Errorf("RefreshSession() with the rotated token error = %v", err)
+	}
+}
+
+func TestRefreshSessionReuseRevokesTheFamily(t *testing.T) {
+	service, _, user := newSessionTestService(t)
+
+	first := createTestSession(t, service, user)
+	other := createTestSession(t, service, user)
+
+	second, err := service.RefreshSession(first, "agent", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RefreshSession() error = %v", err)
+	}
+
+	// a stolen token played after its owner refreshed
+	if _, err := service.RefreshSession(first, "thief", "10.0.0.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("reused RefreshSession() error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+
+	if _, err := service.RefreshSession(second.RefreshToken, "agent", "127.0.0.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("RefreshSession() after reuse error = %v, want the family revoked", err)
+	}
+	if claims := verifyTestTokens(t, service, second.Token); !isRevoked(service, claims[0]) {
+		t.Error("access token of the reused session is not revoked")
+	}
+
+	if _, err := service.RefreshSession(other, "agent", "127.0.0.1"); err != nil {
+		t.Errorf("RefreshSession() of another session error = %v, want it untouched", err)
+	}
+}
+
+func TestRefreshSessionRejectsInvalidTokens(t *testing.T) {
+	service, db, user := newSessionTestService(t)
+
+	expired := createTestSession(t, service, user)
+	err := db.Model(&models.RefreshToken{}).Where("token_hash = ?", hashRefreshToken(expired)).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"unknown": "unknown-token",
+		"expired": expired,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := service.RefreshSession(token, "agent", "127.0.0.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Errorf("RefreshSession() error = %v, want %v", err, ErrInvalidRefreshToken)
+			}
+		})
+	}
+}
+
+func TestRevokeSessionRevokesItsAccessTokens(t *testing.T) {
+	service, _, user := newSessionTestService(t)
+
+	first, err := service.CreateSession(&SessionPayload{UserId: user.Id, Subject: user.Username, Roles: []string{user.Role}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := service.RefreshSession(first.RefreshToken, "agent", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := service.CreateSession(&SessionPayload{UserId: user.Id, Subject: user.Username, Roles: []string{user.Role}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := verifyTestTokens(t, service, first.Token, second.Token, other.Token)
+
+	if err := service.RevokeSession(user.Id+1, claims[0].Sid); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("RevokeSession() of another user error = %v, want %v", err, ErrSessionNotFound)
+	}
+	if isRevoked(service, claims[0]) {
+		t.Fatal("access token revoked by another user")
+	}
+
+	if err := service.RevokeSession(user.Id, claims[0].Sid); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+
+	for i, want := range []bool{true, true, false} {
+		if got := isRevoked(service, claims[i]); got != want {
+			t.Errorf("access token %d revoked = %v, want %v", i, got, want)
+		}
+	}
+
+	if _, err := service.RefreshSession(second.RefreshToken, "agent", "127.0.0.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("RefreshSession() of a revoked session error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+}
+
+func TestLogoutRevokesTheSessionAccessTokens(t *testing.T) {
+	service, _, user := newSessionTestService(t)
+
+	first, err := service.CreateSession(&SessionPayload{UserId: user.Id, Subject: user.Username, Roles: []string{user.Role}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := service.RefreshSession(first.RefreshToken, "agent", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := verifyTestTokens(t, service, first.Token, second.Token)
+
+	if err := service.Logout(claims[1]); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+
+	for i, claim := range claims {
+		if !isRevoked(service, claim) {
+			t.Errorf("access token %d of the session is not revoked", i)
+		}
+	}
+}
+
+func verifyTestTokens(t *testing.T, service *Service, tokens ...string) []*pkgjwt.JwtResponse {
+	t.Helper()
+
+	claims := make([]*pkgjwt.JwtResponse, 0, len(tokens))
+	for _, token := range tokens {
+		claim, err := service.deps.Jwt.VerifyJwt(token)
+		if err != nil {
+			t.Fatalf("VerifyJwt() error = %v", err)
+		}
+		claims = append(claims, claim)
+	}
+
+	return claims
+}
+
+func isRevoked(service *Service, claims *pkgjwt.JwtResponse) bool {
+	return service.deps.Revocation.IsRevoked(claims.Jti, claims.Id, claims.IssuedAt)
+}
+
+func TestAccessTokenLifetime(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.Config
+		want   time.Duration
+	}{
+		{"default", config.Config{}, DefaultAccessTokenLifetime},
+		{"minutes", config.Config{JwtAccessExpireMinutes: 5}, 5 * time.Minute},
+		{"hours of JWT_EXPIRE", config.Config{JwtExpire: 24}, 24 * time.Hour},
+		{"minutes win over hours", config.Config{JwtExpire: 24, JwtAccessExpireMinutes: 30}, 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{config: &tt.config}
+
+			if got := service.accessTokenLifetime(); got != tt.want {
+				t.Errorf("accessTokenLifetime() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateLocaleIsCarriedByTheNextRefresh(t *testing.T) {
+	service, _, user := newSessionTestService(t)
+	refreshToken := createTestSession(t, service, user)
+
+	if err := service.UpdateLocale(user.Id, &web.UpdateLocaleRequest{Locale: "ID"}); err != nil {
+		t.Fatalf("UpdateLocale() error = %v", err)
+	}
+
+	session, err := service.RefreshSession(refreshToken, "agent", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RefreshSession() error = %v", err)
+	}
+	if claims := verifyTestTokens(t, service, session.Token)[0]; claims.Locale != "id" {
+		t.Errorf("locale = %q, want %q", claims.Locale, "id")
+	}
+
+	// an empty locale follows the request again
+	if err := service.UpdateLocale(user.Id, &web.UpdateLocaleRequest{}); err != nil {
+		t.Fatalf("UpdateLocale() error = %v", err)
+	}
+
+	session, err = service.RefreshSession(session.RefreshToken, "agent", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RefreshSession() error = %v", err)
+	}
+	if claims := verifyTestTokens(t, service, session.Token)[0]; claims.Locale != "" {
+		t.Errorf("locale = %q, want none", claims.Locale)
+	}
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"application/app/enums"
+	apperror "application/app/error"
+	"application/app/models"
+	"application/app/web"
+	"application/pkg/audit"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/mfa"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultMfaChallengeLifetime = 5 * time.Minute
+
+	RevokeReasonMfaChallengeUsed = "mfa_challenge_used"
+	defaultTotpIssuer            = "Application"
+)
+
+var (
+	ErrTwoFactorUnavailable    = apperror.New(enums.ErrorCodeMfaUnavailable, "two-factor authentication is not configured")
+	ErrTwoFactorAlreadyEnabled = apperror.New(enums.ErrorCodeMfaAlreadyEnabled, "two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = apperror.New(enums.ErrorCodeMfaNotEnrolled, "two-factor authentication is not enrolled")
+	ErrTwoFactorNotEnabled     = apperror.New(enums.ErrorCodeMfaNotEnabled, "two-factor authentication is not enabled")
+	ErrInvalidMfaCode          = apperror.New(enums.ErrorCodeInvalidMfaCode, "invalid two-factor code")
+	ErrInvalidMfaToken         = apperror.New(enums.ErrorCodeInvalidMfaToken, "invalid or expired mfa token")
+)
+
+// EnrollTwoFactor generates a pending TOTP secret. It is enabled once a code
+// generated from it is confirmed.
+func (s *Service) EnrollTwoFactor(claims *pkgjwt.JwtResponse) (*web.TwoFactorEnrollment, error) {
+	user, err := s.twoFactorUser(claims.Id, "enroll two-factor")
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TotpEnabledAt != nil {
+		return nil, apperror.NewErrorTrace(ErrTwoFactorAlreadyEnabled, "enroll two-factor").Status(http.StatusConflict)
+	}
+
+	issuer := s.config.Application
+	if issuer == "" {
+		issuer = defaultTotpIssuer
+	}
+
+	enrollment, err := mfa.Enroll(issuer, user.Username)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "enroll two-factor")
+	}
+
+	secret, err := s.deps.SecretBox.Seal([]byte(enrollment.Secret))
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "enroll two-factor")
+	}
+
+	if err := s.repository.UpdateAdminUserTotp(s.ctx, user.Id, &secret, nil); err != nil {
+		return nil, apperror.NewErrorTrace(err, "enroll two-factor")
+	}
+
+	return &web.TwoFactorEnrollment{
+		Secret:     enrollment.Secret,
+		OtpauthUri: enrollment.Uri,
+		QrCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(enrollment.QrCode),
+	}, nil
+}
+
+// ConfirmTwoFactor enables the pending secret and returns the recovery codes.
+func (s *Service) ConfirmTwoFactor(claims *pkgjwt.JwtResponse, code string) (*web.RecoveryCodesResponse, error) {
+	user, err := s.twoFactorUser(claims.Id, "confirm two-factor")
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TotpEnabledAt != nil {
+		return nil, apperror.NewErrorTrace(ErrTwoFactorAlreadyEnabled, "confirm two-factor").Status(http.StatusConflict)
+	}
+
+	if user.TotpSecret == nil {
+		return nil, apperror.NewErrorTrace(ErrTwoFactorNotEnrolled, "confirm two-factor").Status(http.StatusBadRequest)
+	}
+
+	secret, err := s.deps.SecretBox.Open(*user.TotpSecret)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "confirm two-factor")
+	}
+
+	now := time.Now()
+	step, ok := mfa.Validate(string(secret), code, now, 0)
+	if !ok {
+		return nil, apperror.NewErrorTrace(ErrInvalidMfaCode, "confirm two-factor").Status(http.StatusBadRequest)
+	}
+
+	if err := s.repository.UpdateAdminUserTotp(s.ctx, user.Id, user.TotpSecret, &now); err != nil {
+		return nil, apperror.NewErrorTrace(err, "confirm two-factor")
+	}
+
+	if _, err := s.repository.UpdateAdminUserTotpStep(s.ctx, user.Id, step); err != nil {
+		return nil, apperror.NewErrorTrace(err, "confirm two-factor")
+	}
+
+	codes, err := s.replaceRecoveryCodes(user.Id)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "confirm two-factor")
+	}
+
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:     enums.AuditEventMfaEnabled,
+		Actor:    user.Username,
+		Metadata: map[string]any{"user_id": user.Id},
+	})
+
+	return &web.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor requires a current TOTP or recovery code.
+func (s *Service) DisableTwoFactor(claims *pkgjwt.JwtResponse, code string, ipAddress string) error {
+	user, err := s.enabledTwoFactorUser(claims.Id, code, ipAddress, "disable two-factor")
+	if err != nil {
+		return err
+	}
+
+	if err := s.ResetTwoFactor(user.Id); err != nil {
+		return err
+	}
+
+	s.deps.Audit.Record(s.ctx, audit.Event{
+		Name:     enums.AuditEventMfaDisabled,
+		Actor:    user.Username,
+		Metadata: map[string]any{"user_id": user.Id},
+	})
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code. It requires a current TOTP or recovery code.
+func (s *Service) RegenerateRecoveryCodes(claims *pkgjwt.JwtResponse, code string, ipAddress string) (*web.RecoveryCodesResponse, error) {
+	user, err := s.enabledTwoFactorUser(claims.Id, code, ipAddress, "regenerate recovery codes")
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(user.Id)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "regenerate recovery codes")
+	}
+
+	return &web.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *Service) TwoFactorStatus(claims *pkgjwt.JwtResponse) (*web.TwoFactorStatus, error) {
+	user, err := s.repository.FindAdminUserById(s.ctx, claims.Id)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "two-factor status")
+	}
+
+	if user == nil {
+		return nil, apperror.NewErrorTrace(ErrUserNotFound, "two-factor status").Status(http.StatusNotFound)
+	}
+
+	status := &web.TwoFactorStatus{
+		Enabled:   user.TotpEnabledAt != nil,
+		EnabledAt: user.TotpEnabledAt,
+	}
+
+	if status.Enabled {
+		status.RecoveryCodesRemaining, err = s.repository.CountUnusedAdminRecoveryCodes(s.ctx, user.Id)
+		if err != nil {
+			return nil, apperror.NewErrorTrace(err, "two-factor status")
+		}
+	}
+
+	return status, nil
+}
+
+// ResetTwoFactor removes the secret and the recovery codes of the user.
+func (s *Service) ResetTwoFactor(userId int64) error {
+	if err := s.repository.UpdateAdminUserTotp(s.ctx, userId, nil, nil); err != nil {
+		return apperror.NewErrorTrace(err, "reset two-factor")
+	}
+
+	if err := s.repository.ReplaceAdminRecoveryCodes(s.ctx, userId, nil); err != nil {
+		return apperror.NewErrorTrace(err, "reset two-factor")
+	}
+
+	return nil
+}
+
+// LoginWithMfa completes a two-step login with the challenge token returned by
+// Login and a TOTP or recovery code.
+func (s *Service) LoginWithMfa(request *web.MfaLoginRequest, userAgent string, ipAddress string) (*web.Session, error) {
+	claims, err := pkgjwt.VerifyClaims[pkgjwt.MfaChallengeClaims](s.deps.Jwt, request.MfaToken)
+	if err != nil || claims.Custom.Use != pkgjwt.TokenUseMfaChallenge ||
+		s.deps.Revocation.IsRevoked(claims.Id, claims.Custom.UserId, claims.IssuedAtTime()) {
+		return nil, apperror.NewErrorTrace(ErrInvalidMfaToken, "login").Status(http.StatusUnauthorized)
+	}
+
+	user, err := s.repository.FindAdminUserById(s.ctx, claims.Custom.UserId)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "login")
+	}
+
+	if user == nil || !user.IsActive || user.TotpEnabledAt == nil {
+		return nil, apperror.NewErrorTrace(ErrInvalidMfaToken, "login").Status(http.StatusUnauthorized)
+	}
+
+	account := loginAccount(user)
+
+	if err := s.deps.Lockout.Check(s.ctx, account, ipAddress); err != nil {
+		return nil, s.lockedLogin(err, account, ipAddress)
+	}
+
+	ok, err := s.verifyTwoFactorCode(user, request.Code)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "login")
+	}
+
+	if !ok {
+		s.deps.Audit.Record(s.ctx, audit.Event{
+			Name:      enums.AuditEventMfaFailed,
+			Actor:     account,
+			IpAddress: ipAddress,
+			Metadata:  map[string]any{"user_id": user.Id},
+		})
+		s.countFailure(account, ipAddress)
+		return nil, apperror.NewErrorTrace(ErrInvalidMfaCode, "login").Status(http.StatusUnauthorized)
+	}
+
+	// the challenge is single use
+	if err := s.deps.Revocation.RevokeToken(s.ctx, claims.Id, user.Id, time.Unix(claims.ExpiresAt, 0), RevokeReasonMfaChallengeUsed); err != nil {
+		log.Error().Err(err).Int64("user_id", user.Id).Msg("[login] failed to revoke mfa challenge")
+	}
+
+	return s.completeLogin(user, account, userAgent, ipAddress)
+}
+
+// issueMfaChallenge proves that the password step succeeded.
+func (s *Service) issueMfaChallenge(user *models.AdminUser) (*web.MfaChallenge, error) {
+	lifetime := s.config.MfaChallengeLifetime
+	if lifetime <= 0 {
+		lifetime = DefaultMfaChallengeLifetime
+	}
+
+	token, err := pkgjwt.IssueClaims(s.deps.Jwt, &pkgjwt.Claims[pkgjwt.MfaChallengeClaims]{
+		RegisteredClaims: pkgjwt.RegisteredClaims{Subject: user.Username},
+		Custom: pkgjwt.MfaChallengeClaims{
+			UserId: user.Id,
+			Use:    pkgjwt.TokenUseMfaChallenge,
+		},
+	}, lifetime)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, "login")
+	}
+
+	return &web.MfaChallenge{
+		MfaRequired: true,
+		MfaToken:    token.Token,
+		ExpiredAt:   token.ExpiredAt,
+	}, nil
+}
+
+// verifyTwoFactorCode accepts a TOTP code not used before, or an unused recovery code.
+func (s *Service) verifyTwoFactorCode(user *models.AdminUser, code string) (bool, error) {
+	if user.TotpSecret == nil {
+		return false, nil
+	}
+
+	secret, err := s.deps.SecretBox.Open(*user.TotpSecret)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if step, ok := mfa.Validate(string(secret), code, now, user.TotpLastUsedStep); ok {
+		return s.repository.UpdateAdminUserTotpStep(s.ctx, user.Id, step)
+	}
+
+	used, err := s.repository.UseAdminRecoveryCode(s.ctx, user.Id, mfa.HashRecoveryCode(code), now)
+	if err != nil {
+		return false, err
+	}
+
+	if used {
+		s.deps.Audit.Record(s.ctx, audit.Event{
+			Name:     enums.AuditEventRecoveryCodeUsed,
+			Actor:    user.Username,
+			Metadata: map[string]any{"user_id": user.Id},
+		})
+	}
+
+	return used, nil
+}
+
+func (s *Service) replaceRecoveryCodes(userId int64) ([]string, error) {
+	codes := mfa.GenerateRecoveryCodes()
+
+	records := make([]*models.AdminRecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		records = append(records, &models.AdminRecoveryCode{
+			UserId:   userId,
+			CodeHash: mfa.HashRecoveryCode(code),
+		})
+	}
+
+	if err := s.repository.ReplaceAdminRecoveryCodes(s.ctx, userId, records); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// twoFactorUser loads the user, failing when secrets cannot be encrypted.
+func (s *Service) twoFactorUser(userId int64, context string) (*models.AdminUser, error) {
+	if s.deps.SecretBox == nil {
+		return nil, apperror.NewErrorTrace(ErrTwoFactorUnavailable, context).Status(http.StatusServiceUnavailable)
+	}
+
+	user, err := s.repository.FindAdminUserById(s.ctx, userId)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, context)
+	}
+
+	if user == nil {
+		return nil, apperror.NewErrorTrace(ErrUserNotFound, context).Status(http.StatusNotFound)
+	}
+
+	return user, nil
+}
+
+// enabledTwoFactorUser loads a user with two-factor enabled and checks the
+// code. Wrong codes count towards the lockout of the account like a failed
+// LoginWithMfa, so a stolen access token cannot guess them either.
+func (s *Service) enabledTwoFactorUser(userId int64, code string, ipAddress string, context string) (*models.AdminUser, error) {
+	user, err := s.twoFactorUser(userId, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TotpEnabledAt == nil {
+		return nil, apperror.NewErrorTrace(ErrTwoFactorNotEnabled, context).Status(http.StatusBadRequest)
+	}
+
+	account := loginAccount(user)
+
+	if err := s.deps.Lockout.Check(s.ctx, account, ipAddress); err != nil {
+		return nil, s.lockedLogin(err, account, ipAddress)
+	}
+
+	ok, err := s.verifyTwoFactorCode(user, code)
+	if err != nil {
+		return nil, apperror.NewErrorTrace(err, context)
+	}
+
+	if !ok {
+		s.deps.Audit.Record(s.ctx, audit.Event{
+			Name:      enums.AuditEventMfaFailed,
+			Actor:     account,
+			IpAddress: ipAddress,
+			Metadata:  map[string]any{"user_id": user.Id},
+		})
+		s.countFailure(account, ipAddress)
+		return nil, apperror.NewErrorTrace(ErrInvalidMfaCode, context).Status(http.StatusBadRequest)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"application/app/models"
+	"application/app/web"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/lockout"
+	"application/pkg/mfa"
+	"application/pkg/secretbox"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+func newTwoFactorTestService(t *testing.T, limits lockout.Config) (*Service, *models.AdminUser) {
+	t.Helper()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	box, err := secretbox.NewBox(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, db := newTestService(t, nil, &Dependencies{SecretBox: box},
+		&models.AdminUser{}, &models.AdminRecoveryCode{}, &models.RefreshToken{})
+	service.deps.Lockout = lockout.NewGuard(service.repository, limits)
+
+	user := &models.AdminUser{Username: "admin", Email: "admin@example.com", Role: "ADMIN", IsActive: true}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return service, user
+}
+
+// totpCode generates the code of an authenticator app at the time.
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	code, err := totp.GenerateCodeCustom(secret, at, totp.ValidateOpts{Period: mfa.Period, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return code
+}
+
+// enableTwoFactor enrolls and confirms the user, returning the secret and the recovery codes.
+func enableTwoFactor(t *testing.T, service *Service, user *models.AdminUser) (string, []string) {
+	t.Helper()
+
+	claims := &pkgjwt.JwtResponse{Id: user.Id}
+
+	enrollment, err := service.EnrollTwoFactor(claims)
+	if err != nil {
+		t.Fatalf("EnrollTwoFactor() error = %v", err)
+	}
+
+	codes, err := service.ConfirmTwoFactor(claims, totpCode(t, enrollment.Secret, time.Now()))
+	if err != nil {
+		t.Fatalf("ConfirmTwoFactor() error = %v", err)
+	}
+
+	return enrollment.Secret, codes.RecoveryCodes
+}
+
+func TestEnableTwoFactor(t *testing.T) {
+	service, user := newTwoFactorTestService(t, lockout.Config{})
+	claims := &pkgjwt.JwtResponse{Id: user.Id}
+
+	if _, err := service.ConfirmTwoFactor(claims, "123456"); !errors.Is(err, ErrTwoFactorNotEnrolled) {
+		t.Fatalf("ConfirmTwoFactor() before the enrollment error = %v, want %v", err, ErrTwoFactorNotEnrolled)
+	}
+
+	enrollment, err := service.EnrollTwoFactor(claims)
+	if err != nil {
+		t.Fatalf("EnrollTwoFactor() error = %v", err)
+	}
+	if enrollment.Secret == "" || enrollment.OtpauthUri == "" || enrollment.QrCode == "" {
+		t.Fatalf("enrollment = %+v", enrollment)
+	}
+
+	// the pending secret does not enable two-factor yet
+	if status, err := service.TwoFactorStatus(claims); err != nil || status.Enabled {
+		t.Fatalf("TwoFactorStatus() = %+v, %v, want disabled", status, err)
+	}
+
+	if _, err := service.ConfirmTwoFactor(claims, "000000"); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatalf("ConfirmTwoFactor() with a wrong code error = %v, want %v", err, ErrInvalidMfaCode)
+	}
+
+	codes, err := service.ConfirmTwoFactor(claims, totpCode(t, enrollment.Secret, time.Now()))
+	if err != nil {
+		t.Fatalf("ConfirmTwoFactor() error = %v", err)
+	}
+	if len(codes.RecoveryCodes) != mfa.RecoveryCodeCount {
+		t.Errorf("recovery codes = %d, want %d", len(codes.RecoveryCodes), mfa.RecoveryCodeCount)
+	}
+
+	status, err := service.TwoFactorStatus(claims)
+	if err != nil || !status.Enabled || status.RecoveryCodesRemaining != mfa.RecoveryCodeCount {
+		t.Errorf("TwoFactorStatus() = %+v, %v, want enabled with every recovery code", status, err)
+	}
+
+	if _, err := service.EnrollTwoFactor(claims); !errors.Is(err, ErrTwoFactorAlreadyEnabled) {
+		t.Errorf("EnrollTwoFactor() when enabled error = %v, want %v", err, ErrTwoFactorAlreadyEnabled)
+	}
+}
+
+func TestLoginWithMfa(t *testing.T) {
+	service, user := newTwoFactorTestService(t, lockout.Config{})
+	secret, recoveryCodes := enableTwoFactor(t, service, user)
+
+	login := func(code string) (*web.Session, error) {
+		t.Helper()
+
+		challenge, err := service.issueMfaChallenge(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return service.LoginWithMfa(&web.MfaLoginRequest{MfaToken: challenge.MfaToken, Code: code}, "agent", "127.0.0.1")
+	}
+
+	if _, err := login("000000"); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatalf("LoginWithMfa() with a wrong code error = %v, want %v", err, ErrInvalidMfaCode)
+	}
+
+	// the code of the confirmation cannot be played again
+	if _, err := login(totpCode(t, secret, time.Now())); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatalf("LoginWithMfa() with the confirmed code error = %v, want %v", err, ErrInvalidMfaCode)
+	}
+
+	next := totpCode(t, secret, time.Now().Add(mfa.Period*time.Second))
+	if session, err := login(next); err != nil || session.Token == "" {
+		t.Fatalf("LoginWithMfa() = %v, %v, want a session", session, err)
+	}
+
+	if _, err := login(recoveryCodes[0]); err != nil {
+		t.Fatalf("LoginWithMfa() with a recovery code error = %v", err)
+	}
+	if _, err := login(recoveryCodes[0]); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Errorf("LoginWithMfa() with a used recovery code error = %v, want %v", err, ErrInvalidMfaCode)
+	}
+}
+
+func TestLoginWithMfaChallengeIsSingleUse(t *testing.T) {
+	service, user := newTwoFactorTestService(t, lockout.Config{})
+	_, recoveryCodes := enableTwoFactor(t, service, user)
+
+	challenge, err := service.issueMfaChallenge(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := &web.MfaLoginRequest{MfaToken: challenge.MfaToken, Code: recoveryCodes[0]}
+	if _, err := service.LoginWithMfa(request, "agent", "127.0.0.1"); err != nil {
+		t.Fatalf("LoginWithMfa() error = %v", err)
+	}
+
+	request.Code = recoveryCodes[1]
+	if _, err := service.LoginWithMfa(request, "agent", "127.0.0.1"); !errors.Is(err, ErrInvalidMfaToken) {
+		t.Errorf("LoginWithMfa() with a used challenge error = %v, want %v", err, ErrInvalidMfaToken)
+	}
+}
+
+func TestRegenerateRecoveryCodes(t *testing.T) {
+	service, user := newTwoFactorTestService(t, lockout.Config{})
+	_, recoveryCodes := enableTwoFactor(t, service, user)
+	claims := &pkgjwt.JwtResponse{Id: user.Id}
+
+	if _, err := service.RegenerateRecoveryCodes(claims, "000000", "127.0.0.1"); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatalf("RegenerateRecoveryCodes() with a wrong code error = %v, want %v", err, ErrInvalidMfaCode)
+	}
+
+	codes, err := service.RegenerateRecoveryCodes(claims, recoveryCodes[0], "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes() error = %v", err)
+	}
+
+	if _, err := service.RegenerateRecoveryCodes(claims, recoveryCodes[1], "127.0.0.1"); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Errorf("RegenerateRecoveryCodes() with a replaced code error = %v, want %v", err, ErrInvalidMfaCode)
+	}
+	if _, err := service.RegenerateRecoveryCodes(claims, codes.RecoveryCodes[0], "127.0.0.1"); err != nil {
+		t.Errorf("RegenerateRecoveryCodes() with a new code error = %v", err)
+	}
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	service, user := newTwoFactorTestService(t, lockout.Config{})
+	secret, _ := enableTwoFactor(t, service, user)
+	claims := &pkgjwt.JwtResponse{Id: user.Id}
+
+	if err := service.DisableTwoFactor(claims, "000000", "127.0.0.1"); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatalf("DisableTwoFactor() with a wrong code error = %v, want %v", err, ErrInvalidMfaCode)
+	}
+
+	if err := service.DisableTwoFactor(claims, totpCode(t, secret, time.Now().Add(mfa.Period*time.Second)), "127.0.0.1"); err != nil {
+		t.Fatalf("DisableTwoFactor() error = %v", err)
+	}
+
+	status, err := service.TwoFactorStatus(claims)
+	if err != nil || status.Enabled || status.RecoveryCodesRemaining != 0 {
+		t.Errorf("TwoFactorStatus() = %+v, %v, want disabled", status, err)
+	}
+
+	if err := service.DisableTwoFactor(claims, "000000", "127.0.0.1"); !errors.Is(err, ErrTwoFactorNotEnabled) {
+		t.Errorf("DisableTwoFactor() when disabled error = %v, want %v", err, ErrTwoFactorNotEnabled)
+	}
+}
+
+func TestTwoFactorCodesCountTowardsTheLockout(t *testing.T) {
+	service, user := newTwoFactorTestService(t, lockout.Config{Threshold: 3, IpThreshold: 10})
+	_, recoveryCodes := enableTwoFactor(t, service, user)
+	claims := &pkgjwt.JwtResponse{Id: user.Id}
+
+	if err := service.DisableTwoFactor(claims, "000000", "127.0.0.1"); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatal(err)
+	}
+	if _, err := service.RegenerateRecoveryCodes(claims, "000000", "127.0.0.1"); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatal(err)
+	}
+
+	challenge, err := service.issueMfaChallenge(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := &web.MfaLoginRequest{MfaToken: challenge.MfaToken, Code: "000000"}
+	if _, err := service.LoginWithMfa(request, "agent", "127.0.0.1"); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatal(err)
+	}
+
+	// the account is locked even for a valid code
+	if err := service.DisableTwoFactor(claims, recoveryCodes[0], "127.0.0.1"); !errors.Is(err, lockout.ErrLocked) {
+		t.Errorf("DisableTwoFactor() of a locked account error = %v, want %v", err, lockout.ErrLocked)
+	}
+	if _, err := service.RegenerateRecoveryCodes(claims, recoveryCodes[0], "127.0.0.1"); !errors.Is(err, lockout.ErrLocked) {
+		t.Errorf("RegenerateRecoveryCodes() of a locked account error = %v, want %v", err, lockout.ErrLocked)
+	}
+	request.Code = recoveryCodes[0]
+	if _, err := service.LoginWithMfa(request, "agent", "127.0.0.1"); !errors.Is(err, lockout.ErrLocked) {
+		t.Errorf("LoginWithMfa() of a locked account error = %v, want %v", err, lockout.ErrLocked)
+	}
+}
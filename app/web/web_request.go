@@ -0,0 +1,74 @@
+package web
+
+import "time"
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type LoginRequest struct {
+	// Username accepts either the username or the email of the admin user.
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type CreateApiKeyRequest struct {
+	Name          string     `json:"name" binding:"required,max=255"`
+	Scopes        []string   `json:"scopes"`
+	RateLimitTier string     `json:"rateLimitTier" binding:"omitempty,enum=rate_limit_tier"`
+	ExpiredAt     *time.Time `json:"expiredAt" binding:"omitempty,future"`
+}
+
+// PaymentCallbackRequest is the payment status sent by a partner.
+type PaymentCallbackRequest struct {
+	PaymentId string `json:"paymentId" binding:"required"`
+	Status    string `json:"status" binding:"required"`
+	Reference string `json:"reference"`
+}
+
+type UpdateLocaleRequest struct {
+	// Locale is empty to follow the Accept-Language header of each request.
+	Locale string `json:"locale" binding:"omitempty,enum=locale"`
+}
+
+type MfaLoginRequest struct {
+	MfaToken string `json:"mfaToken" binding:"required"`
+	// Code accepts a TOTP code or a recovery code.
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	// Username accepts either the username or the email of the admin user.
+	Username string `json:"username" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// TokenRequest is the form of the OAuth2 token endpoint. The client may
+// authenticate with HTTP Basic instead of the client_id and client_secret fields.
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Scope        string `form:"scope"`
+	ClientId     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type IntrospectionRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+}
+
+// OidcCallbackRequest carries the code and state the identity provider
+// redirected with, and the flow token returned when the login started.
+type OidcCallbackRequest struct {
+	Code      string `json:"code" binding:"required"`
+	State     string `json:"state" binding:"required"`
+	FlowToken string `json:"flowToken" binding:"required"`
+}
@@ -1,9 +1,13 @@
 package web
 
+import "time"
+
 type ResponseWeb struct {
 	Message string `json:"message"`
 	Success bool   `json:"success"`
-	Data    any    `json:"data,omitempty"`
+	// Code is the machine-readable code of an error, see CodeError in ENUMS.md.
+	Code string `json:"code,omitempty"`
+	Data any    `json:"data,omitempty"`
 }
 
 type Metadata struct {
@@ -21,8 +25,21 @@ type ListResponse struct {
 }
 
 type Session struct {
-	Token     string `json:"token"`
-	ExpiredAt int64  `json:"expiredAt"`
+	TokenType        string `json:"tokenType,omitempty"`
+	Token            string `json:"token"`
+	ExpiredAt        int64  `json:"expiredAt"`
+	RefreshToken     string `json:"refreshToken,omitempty"`
+	RefreshExpiredAt int64  `json:"refreshExpiredAt,omitempty"`
+}
+
+type SessionInfo struct {
+	Id         string    `json:"id"`
+	UserAgent  string    `json:"userAgent"`
+	IpAddress  string    `json:"ipAddress"`
+	StartedAt  time.Time `json:"startedAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiredAt  time.Time `json:"expiredAt"`
+	Current    bool      `json:"current"`
 }
 
 type Health struct {
@@ -30,3 +47,102 @@ type Health struct {
 	Uptime     string `json:"uptime"`
 	AppVersion string `json:"appVersion"`
 }
+
+type RoleResponse struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type AdminUserResponse struct {
+	Id          int64      `json:"id"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	Name        string     `json:"name"`
+	Role        string     `json:"role"`
+	Permissions []string   `json:"permissions"`
+	Locale      string     `json:"locale"`
+	LastLoginAt *time.Time `json:"lastLoginAt"`
+}
+
+type ApiKeyResponse struct {
+	Id            int64      `json:"id"`
+	Name          string     `json:"name"`
+	Prefix        string     `json:"prefix"`
+	Scopes        []string   `json:"scopes"`
+	RateLimitTier string     `json:"rateLimitTier"`
+	ExpiredAt     *time.Time `json:"expiredAt"`
+	LastUsedAt    *time.Time `json:"lastUsedAt"`
+	RevokedAt     *time.Time `json:"revokedAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// CreatedApiKeyResponse is the only response carrying the key itself.
+type CreatedApiKeyResponse struct {
+	ApiKeyResponse
+	Key string `json:"key"`
+}
+
+// MfaChallenge is returned by the password step of a login when the account
+// has two-factor authentication enabled.
+type MfaChallenge struct {
+	MfaRequired bool   `json:"mfaRequired"`
+	MfaToken    string `json:"mfaToken"`
+	ExpiredAt   int64  `json:"expiredAt"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauthUri"`
+	// QrCode is a PNG data URI of the otpauth URI.
+	QrCode string `json:"qrCode"`
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabledAt"`
+	RecoveryCodesRemaining int64      `json:"recoveryCodesRemaining"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TokenResponse is the access token response of RFC 6749 section 5.1.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// OAuthErrorResponse is the error response of RFC 6749 section 5.2.
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// IntrospectionResponse is the response of RFC 7662. Inactive tokens only carry Active.
+type IntrospectionResponse struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientId  string   `json:"client_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Nbf       int64    `json:"nbf,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	Aud       []string `json:"aud,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+}
+
+// OidcAuthorization starts an OpenID Connect login. The browser is sent to
+// AuthorizationUrl and keeps FlowToken for the callback.
+type OidcAuthorization struct {
+	AuthorizationUrl string `json:"authorizationUrl"`
+	FlowToken        string `json:"flowToken"`
+	ExpiredAt        int64  `json:"expiredAt"`
+}
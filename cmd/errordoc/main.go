@@ -0,0 +1,82 @@
+// Command errordoc writes the error catalog of app/error as a Markdown table.
+// Run it with go generate ./app/error after changing the catalog.
+package main
+
+import (
+	apperror "application/app/error"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const header = `# Errors
+
+<!-- Code generated by cmd/errordoc from app/error/catalog.go. DO NOT EDIT. -->
+
+Every error response carries one of these codes in ` + "`code`" + `. The message is
+taken from this catalog in the language of the client: the ` + "`lang`" + ` query
+parameter (` + "`en`" + ` or ` + "`id`" + `), else the ` + "`Accept-Language`" + ` header, else English.
+Errors without a code of their own keep their message, except server errors.
+` + "`{name}`" + ` placeholders are filled from the error.
+
+`
+
+func main() {
+	out := flag.String("out", "ERRORS.md", "file to write")
+	flag.Parse()
+
+	entries := apperror.Catalog()
+
+	rows := [][]string{{"Code", "Status", "English", "Indonesian"}}
+	for _, entry := range entries {
+		rows = append(rows, []string{
+			"`" + entry.Code.String() + "`",
+			fmt.Sprint(entry.Status),
+			escape(entry.En),
+			escape(entry.Id),
+		})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString(header)
+	for i, row := range rows {
+		writeRow(&doc, row, widths)
+		if i == 0 {
+			writeSeparator(&doc, widths)
+		}
+	}
+
+	if err := os.WriteFile(*out, doc.Bytes(), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func writeRow(doc *bytes.Buffer, cells []string, widths []int) {
+	doc.WriteString("|")
+	for i, cell := range cells {
+		doc.WriteString(" " + cell + strings.Repeat(" ", widths[i]-len([]rune(cell))) + " |")
+	}
+	doc.WriteString("\n")
+}
+
+func writeSeparator(doc *bytes.Buffer, widths []int) {
+	doc.WriteString("|")
+	for _, width := range widths {
+		doc.WriteString(strings.Repeat("-", width+2) + "|")
+	}
+	doc.WriteString("\n")
+}
+
+func escape(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -0,0 +1,89 @@
+package init
+
+import (
+	"application/app/repositories"
+	"application/app/services"
+	"application/app/web"
+	"application/config"
+	"application/pkg/apikey"
+	"application/pkg/audit"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+func (cmd *Command) apiKeyService(cfg *config.Config) *services.Service {
+	rc, ctx := cmd.connect(cfg)
+
+	return services.NewService(ctx, rc, cfg, &services.Dependencies{
+		Audit: audit.NewRecorder(rc),
+	})
+}
+
+// CreateApiKey generates an API key and prints it once.
+func (cmd *Command) CreateApiKey(cfg *config.Config, name string) {
+	request := &web.CreateApiKeyRequest{
+		Name:          name,
+		Scopes:        splitList(*cmd.Flags.OptScopes),
+		RateLimitTier: *cmd.Flags.OptRateLimitTier,
+	}
+
+	if *cmd.Flags.OptExpiresIn > 0 {
+		expiredAt := time.Now().Add(*cmd.Flags.OptExpiresIn)
+		request.ExpiredAt = &expiredAt
+	}
+
+	key, err := cmd.apiKeyService(cfg).CreateApiKey(request, nil, "cli")
+	if err != nil {
+		fmt.Printf("failed to create api key. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("api key created. id = [%d], key = [%s]\n", key.Id, key.Key)
+
+	os.Exit(0)
+}
+
+func (cmd *Command) ListApiKeys(cfg *config.Config) {
+	keys, err := cmd.apiKeyService(cfg).ListApiKeys()
+	if err != nil {
+		fmt.Printf("failed to list api keys. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	for _, key := range keys {
+		status := "active"
+		if key.RevokedAt != nil {
+			status = "revoked"
+		} else if key.ExpiredAt != nil && key.ExpiredAt.Before(time.Now()) {
+			status = "expired"
+		}
+
+		fmt.Printf("id = [%d], name = [%s], prefix = [%s], status = [%s], tier = [%s], scopes = [%s]\n",
+			key.Id,
+			key.Name,
+			key.Prefix,
+			status,
+			key.RateLimitTier,
+			strings.Join(key.Scopes, ","),
+		)
+	}
+
+	os.Exit(0)
+}
+
+func (cmd *Command) RevokeApiKey(cfg *config.Config, id int64) {
+	if err := cmd.apiKeyService(cfg).RevokeApiKey(id, "cli"); err != nil {
+		fmt.Printf("failed to revoke api key. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("api key [%d] revoked\n", id)
+
+	os.Exit(0)
+}
+
+func newApiKeyStore(cfg *config.Config, repo *repositories.RepositoryContext) *apikey.Store {
+	return apikey.NewStore(repo, cfg.ApiKeyTouchInterval)
+}
@@ -0,0 +1,115 @@
+package init
+
+import (
+	"application/app/models"
+	"application/app/repositories"
+	"application/config"
+	"application/pkg/basicauth"
+	"application/pkg/util"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const generatedClientSecretLength = 40
+
+func newBasicClientStore(cfg *config.Config, repo *repositories.RepositoryContext) *basicauth.Store {
+	return basicauth.NewStore(repo, []byte(cfg.ClientSecretKey), cfg.BasicAuthCacheTTL).
+		WithLegacyCredentials(cfg.BasicAuthUsername, cfg.BasicAuthPassword)
+}
+
+// CreateBasicClient registers a basic auth client and prints its generated secret once.
+func (cmd *Command) CreateBasicClient(cfg *config.Config, name string) {
+	rc, ctx := cmd.connect(cfg)
+
+	secret := util.GenerateRandomString(generatedClientSecretLength)
+
+	client := &models.BasicAuthClient{
+		Name:       name,
+		SecretHash: util.HashSecret([]byte(cfg.ClientSecretKey), secret),
+		Scopes:     splitList(*cmd.Flags.OptScopes),
+		Routes:     splitList(*cmd.Flags.OptRoutes),
+		IsEnabled:  true,
+	}
+
+	if err := rc.CreateBasicAuthClient(ctx, client); err != nil {
+		fmt.Printf("failed to create basic auth client. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("basic auth client created. name = [%s], secret = [%s]\n", client.Name, secret)
+
+	os.Exit(0)
+}
+
+func (cmd *Command) SetBasicClientEnabled(cfg *config.Config, name string, enabled bool) {
+	rc, ctx := cmd.connect(cfg)
+
+	affected, err := rc.UpdateBasicAuthClientEnabled(ctx, name, enabled)
+	if err != nil {
+		fmt.Printf("failed to update basic auth client. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	if affected == 0 {
+		fmt.Printf("basic auth client [%s] not found\n", name)
+		os.Exit(1)
+	}
+
+	fmt.Printf("basic auth client [%s] enabled = [%v]\n", name, enabled)
+
+	os.Exit(0)
+}
+
+func (cmd *Command) ListBasicClients(cfg *config.Config) {
+	rc, ctx := cmd.connect(cfg)
+
+	clients, err := rc.FindBasicAuthClients(ctx)
+	if err != nil {
+		fmt.Printf("failed to list basic auth clients. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	for _, client := range clients {
+		fmt.Printf("name = [%s], enabled = [%v], scopes = [%s], routes = [%s]\n",
+			client.Name,
+			client.IsEnabled,
+			strings.Join(client.Scopes, ","),
+			strings.Join(client.Routes, ","),
+		)
+	}
+
+	os.Exit(0)
+}
+
+// connect opens the database for a command line operation, exiting on failure.
+func (cmd *Command) connect(cfg *config.Config) (*repositories.RepositoryContext, context.Context) {
+	repo, err := repositories.NewRepository(cfg)
+	if err != nil {
+		fmt.Printf("failed to read database configuration. Error = [%v]", err)
+		os.Exit(0)
+	}
+
+	ctx := context.Background()
+
+	rc, err := repo.Connected(ctx)
+	if err != nil {
+		fmt.Printf("failed to connect to database. Error = [%v]", err)
+		os.Exit(0)
+	}
+
+	return rc, ctx
+}
+
+// splitList splits a comma separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -2,9 +2,22 @@ package init
 
 import (
 	"application/api/routes"
+	"application/app/enums"
+	apperror "application/app/error"
+	"application/app/models"
 	"application/app/repositories"
+	"application/app/services"
 	"application/config"
+	"application/pkg/audit"
+	"application/pkg/logging"
+	"application/pkg/metrics"
 	"application/pkg/middleware"
+	"application/pkg/password"
+	"application/pkg/rbac"
+	"application/pkg/revocation"
+	"application/pkg/util"
+	"application/pkg/validation"
+	"application/pkg/worker"
 	"context"
 	"errors"
 	"flag"
@@ -37,8 +50,33 @@ type Flags struct {
 	CmdPrintVersion         *bool
 	OptWorkDir              *string
 	OptEnvPrefix            *string
+	OptUsername             *string
+	OptEmail                *string
 	CreateUserAdmin         *bool
 	UpdatePasswordUserAdmin *bool
+	RotateJwtKey            *bool
+	RevokeUserTokens        *int64
+	UnlockAccount           *string
+	UnlockIp                *string
+	OptScopes               *string
+	OptRoutes               *string
+	CreateBasicClient       *string
+	EnableBasicClient       *string
+	DisableBasicClient      *string
+	ListBasicClients        *bool
+	OptRateLimitTier        *string
+	OptExpiresIn            *time.Duration
+	CreateApiKey            *string
+	ListApiKeys             *bool
+	RevokeApiKey            *int64
+	CreateSigningClient     *string
+	EnableSigningClient     *string
+	DisableSigningClient    *string
+	ResetTwoFactor          *string
+	CreateOAuthClient       *string
+	EnableOAuthClient       *string
+	DisableOAuthClient      *string
+	ListOAuthClients        *bool
 }
 
 type InitVariables struct {
@@ -62,8 +100,33 @@ func NewCLI(args []string) *Command {
 			CmdPrintVersion:         flag.Bool("version", false, "Command: show version"),
 			OptWorkDir:              flag.String("dir", "", "Option: set working directory"),
 			OptEnvPrefix:            flag.String("env-prefix", "", "Option: set env prefix"),
+			OptUsername:             flag.String("username", "superadmin", "Option: username of the user admin"),
+			OptEmail:                flag.String("email", "", "Option: email of the user admin"),
 			CreateUserAdmin:         flag.Bool("create-user-admin", false, "Option: create user admin"),
 			UpdatePasswordUserAdmin: flag.Bool("update-password-user-admin", false, "Option: update user admin"),
+			RotateJwtKey:            flag.Bool("rotate-jwt-key", false, "Option: rotate jwt signing key in JWT_KEYS_DIR"),
+			RevokeUserTokens:        flag.Int64("revoke-user-tokens", 0, "Option: log out every device of the user id"),
+			UnlockAccount:           flag.String("unlock-account", "", "Option: clear the failed logins of the admin username or basic:<username>"),
+			UnlockIp:                flag.String("unlock-ip", "", "Option: clear the failed logins of the ip address"),
+			OptScopes:               flag.String("scopes", "", "Option: comma separated scopes of the client"),
+			OptRoutes:               flag.String("routes", "", "Option: comma separated routes the client may call, e.g. \"POST /api/v1/orders/*\""),
+			CreateBasicClient:       flag.String("create-basic-client", "", "Option: register a basic auth client with the name"),
+			EnableBasicClient:       flag.String("enable-basic-client", "", "Option: enable the basic auth client"),
+			DisableBasicClient:      flag.String("disable-basic-client", "", "Option: disable the basic auth client"),
+			ListBasicClients:        flag.Bool("list-basic-clients", false, "Option: list the basic auth clients"),
+			OptRateLimitTier:        flag.String("rate-limit-tier", "", "Option: rate limit tier of the api key (BASIC, STANDARD, PREMIUM, UNLIMITED)"),
+			OptExpiresIn:            flag.Duration("expires-in", 0, "Option: lifetime of the api key, e.g. 8760h (never expires by default)"),
+			CreateApiKey:            flag.String("create-api-key", "", "Option: create an api key with the name"),
+			ListApiKeys:             flag.Bool("list-api-keys", false, "Option: list the api keys"),
+			RevokeApiKey:            flag.Int64("revoke-api-key", 0, "Option: revoke the api key id"),
+			CreateSigningClient:     flag.String("create-signing-client", "", "Option: register a client signing its requests with the client id"),
+			EnableSigningClient:     flag.String("enable-signing-client", "", "Option: enable the signing client"),
+			DisableSigningClient:    flag.String("disable-signing-client", "", "Option: disable the signing client"),
+			ResetTwoFactor:          flag.String("reset-two-factor", "", "Option: remove the two-factor authentication of the admin username"),
+			CreateOAuthClient:       flag.String("create-oauth-client", "", "Option: register an oauth2 client credentials client with the name"),
+			EnableOAuthClient:       flag.String("enable-oauth-client", "", "Option: enable the oauth2 client id"),
+			DisableOAuthClient:      flag.String("disable-oauth-client", "", "Option: disable the oauth2 client id"),
+			ListOAuthClients:        flag.Bool("list-oauth-clients", false, "Option: list the oauth2 clients"),
 		},
 		args,
 		nil,
@@ -94,6 +157,7 @@ func (cmd *Command) Run(inv InitVariables) {
 	}
 
 	zerolog.SetGlobalLevel(logLevel)
+	logging.Deny(cfg.LogRedactFields...)
 
 	repo, err := repositories.NewRepository(cfg)
 	if err != nil {
@@ -102,6 +166,13 @@ func (cmd *Command) Run(inv InitVariables) {
 
 	repositoryContext := repo.Connect(context.Background())
 
+	metrics.SetBuildInfo(AppVersion, Build)
+	if version, dirty, err := repositoryContext.MigrationVersion(context.Background()); err != nil {
+		log.Warn().Err(err).Msg("failed to read the migration version")
+	} else {
+		metrics.SetMigrationVersion(version, dirty)
+	}
+
 	handler, err := InitServer(inv.StartedAt, inv.AppVersion, inv.Signature, cfg, repositoryContext)
 	if err != nil {
 		panic(fmt.Errorf("failed to init controllers. Error = [%v]", err))
@@ -120,7 +191,18 @@ func (cmd *Command) Run(inv InitVariables) {
 		}
 	}()
 
-	cmd.GracefulShutdown(server, cfg, repo)
+	servers := []*http.Server{server}
+	if metricsServer := newMetricsServer(cfg); metricsServer != nil {
+		servers = append(servers, metricsServer)
+
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal().Msg(fmt.Sprintf("Failed to start metrics server: %v", err))
+			}
+		}()
+	}
+
+	cmd.GracefulShutdown(cfg, repo, servers...)
 }
 
 func GetListenPort(i uint16) string {
@@ -142,6 +224,8 @@ func InitServer(startTime time.Time, appVersion string, signature string, cfg *c
 		gin.SetMode(gin.DebugMode)
 	}
 	e := gin.New()
+	// expose the request context, e.g. the request id, through the gin context
+	e.ContextWithFallback = true
 
 	e.Use(cors.New(cors.Config{
 		AllowOrigins:  []string{"*"},
@@ -150,18 +234,116 @@ func InitServer(startTime time.Time, appVersion string, signature string, cfg *c
 		ExposeHeaders: []string{"*"},
 	}))
 
-	e.Use(gin.Recovery())
-	e.Use(gin.Logger())
+	sink, err := newErrorSink(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Use(middleware.RequestId())
+	e.Use(middleware.Metrics())
+	e.Use(middleware.AccessLog(middleware.AccessLogConfig{
+		SkipPaths:  cfg.AccessLogSkipPaths,
+		SampleRate: cfg.AccessLogSampleRate,
+		Headers:    cfg.AccessLogHeaders,
+		Redact:     cfg.AccessLogRedact,
+	}))
+	e.Use(middleware.Recovery(sink, cfg.NodeId))
 	e.Use(middleware.ErrorHandler())
 
-	route := routes.NewRoute(startTime, appVersion, cfg, repo, e.Group("/api/v1"))
+	if err := validation.Register(); err != nil {
+		return nil, err
+	}
+
+	apperror.SetProblemConfig(apperror.ProblemConfig{
+		Always:      cfg.ErrorProblemJson,
+		TypeBaseUrl: cfg.ErrorTypeBaseUrl,
+	})
+
+	jwtAdapter, err := InitJwtAdapter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	revocations := revocation.NewStore(repo, cfg.RevocationSyncInterval, cfg.RevocationCleanupInterval)
+	if err := revocations.Start(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to load token revocations: %w", err)
+	}
+
+	policy := rbac.NewPolicy(repo, cfg.RbacCacheTTL)
+	if err := policy.Start(context.Background(), cfg.RbacRefreshInterval); err != nil {
+		return nil, fmt.Errorf("failed to load role permissions: %w", err)
+	}
+
+	lockoutGuard := newLockoutGuard(cfg, repo)
+	lockoutGuard.StartJanitor(context.Background())
+
+	resetGuard := newPasswordResetGuard(cfg, repo)
+	resetGuard.StartJanitor(context.Background())
+
+	basicClients := newBasicClientStore(cfg, repo)
+	basicClients.StartJanitor(context.Background())
+
+	apiKeys := newApiKeyStore(cfg, repo)
+	apiKeys.StartJanitor(context.Background())
+
+	oauthClients := newOAuthClientStore(cfg, repo)
+	oauthClients.StartJanitor(context.Background())
+
+	secretBox, err := newSecretBox(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := newSignatureVerifier(cfg, repo, secretBox)
+	if signatures != nil {
+		signatures.StartJanitor(context.Background())
+	}
+
+	passwords, err := initPasswords(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mail, err := newMailer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	resetMails := worker.NewPool(cfg.PasswordResetWorkers, cfg.PasswordResetQueueSize)
+	resetMails.Start(context.Background())
+
+	deps := &services.Dependencies{
+		Jwt:          jwtAdapter,
+		Revocation:   revocations,
+		Policy:       policy,
+		Lockout:      lockoutGuard,
+		ResetLockout: resetGuard,
+		Audit:        audit.NewRecorder(repo),
+		BasicClients: basicClients,
+		ApiKeys:      apiKeys,
+		Signatures:   signatures,
+		SecretBox:    secretBox,
+		Passwords:    passwords,
+		Mailer:       mail,
+		ResetMails:   resetMails,
+		OAuthClients: oauthClients,
+		Oidc:         newOidcProvider(cfg),
+	}
+
+	route := routes.NewRoute(startTime, appVersion, cfg, repo, deps, e.Group("/api/v1"))
 
 	route.RegisterCoreServicesRoutes()
+	route.RegisterWellKnownRoutes(e)
+	route.RegisterOAuthRoutes(e)
+
+	if cfg.MetricsPort == 0 {
+		e.GET(metricsPath(cfg), gin.WrapH(metrics.Handler()))
+	}
 
 	return e, nil
 }
 
-func (cmd *Command) GracefulShutdown(server *http.Server, cfg *config.Config, repo *repositories.Repository) {
+func (cmd *Command) GracefulShutdown(cfg *config.Config, repo *repositories.Repository, servers ...*http.Server) {
 	quit := make(chan os.Signal, 1)
 
 	defer func() {
@@ -177,8 +359,10 @@ func (cmd *Command) GracefulShutdown(server *http.Server, cfg *config.Config, re
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatal().Msg(fmt.Sprintf("Server forced to shutdown: %v", err))
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			log.Fatal().Msg(fmt.Sprintf("Server forced to shutdown: %v", err))
+		}
 	}
 
 	log.Print("Server exiting")
@@ -222,6 +406,134 @@ func HandleBootFlags(cmd *Command) *BootOptions {
 		cmd.UpdatePasswordUserAdmin(load)
 	}
 
+	if *flags.RotateJwtKey {
+		load, err := Load(&BootOptions{
+			WorkDir:   *flags.OptWorkDir,
+			EnvPrefix: *flags.OptEnvPrefix,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		cmd.RotateJwtKey(load)
+	}
+
+	if *flags.RevokeUserTokens > 0 {
+		load, err := Load(&BootOptions{
+			WorkDir:   *flags.OptWorkDir,
+			EnvPrefix: *flags.OptEnvPrefix,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		cmd.RevokeUserTokens(load, *flags.RevokeUserTokens)
+	}
+
+	if *flags.UnlockAccount != "" || *flags.UnlockIp != "" {
+		load, err := Load(&BootOptions{
+			WorkDir:   *flags.OptWorkDir,
+			EnvPrefix: *flags.OptEnvPrefix,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		cmd.UnlockAccount(load, *flags.UnlockAccount, *flags.UnlockIp)
+	}
+
+	if *flags.CreateBasicClient != "" || *flags.EnableBasicClient != "" || *flags.DisableBasicClient != "" || *flags.ListBasicClients {
+		load, err := Load(&BootOptions{
+			WorkDir:   *flags.OptWorkDir,
+			EnvPrefix: *flags.OptEnvPrefix,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		switch {
+		case *flags.CreateBasicClient != "":
+			cmd.CreateBasicClient(load, *flags.CreateBasicClient)
+		case *flags.EnableBasicClient != "":
+			cmd.SetBasicClientEnabled(load, *flags.EnableBasicClient, true)
+		case *flags.DisableBasicClient != "":
+			cmd.SetBasicClientEnabled(load, *flags.DisableBasicClient, false)
+		default:
+			cmd.ListBasicClients(load)
+		}
+	}
+
+	if *flags.CreateApiKey != "" || *flags.ListApiKeys || *flags.RevokeApiKey > 0 {
+		load, err := Load(&BootOptions{
+			WorkDir:   *flags.OptWorkDir,
+			EnvPrefix: *flags.OptEnvPrefix,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		switch {
+		case *flags.CreateApiKey != "":
+			cmd.CreateApiKey(load, *flags.CreateApiKey)
+		case *flags.RevokeApiKey > 0:
+			cmd.RevokeApiKey(load, *flags.RevokeApiKey)
+		default:
+			cmd.ListApiKeys(load)
+		}
+	}
+
+	if *flags.CreateSigningClient != "" || *flags.EnableSigningClient != "" || *flags.DisableSigningClient != "" {
+		load, err := Load(&BootOptions{
+			WorkDir:   *flags.OptWorkDir,
+			EnvPrefix: *flags.OptEnvPrefix,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		switch {
+		case *flags.CreateSigningClient != "":
+			cmd.CreateSigningClient(load, *flags.CreateSigningClient)
+		case *flags.EnableSigningClient != "":
+			cmd.SetSigningClientEnabled(load, *flags.EnableSigningClient, true)
+		default:
+			cmd.SetSigningClientEnabled(load, *flags.DisableSigningClient, false)
+		}
+	}
+
+	if *flags.ResetTwoFactor != "" {
+		load, err := Load(&BootOptions{
+			WorkDir:   *flags.OptWorkDir,
+			EnvPrefix: *flags.OptEnvPrefix,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		cmd.ResetTwoFactor(load, *flags.ResetTwoFactor)
+	}
+
+	if *flags.CreateOAuthClient != "" || *flags.EnableOAuthClient != "" || *flags.DisableOAuthClient != "" || *flags.ListOAuthClients {
+		load, err := Load(&BootOptions{
+			WorkDir:   *flags.OptWorkDir,
+			EnvPrefix: *flags.OptEnvPrefix,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		switch {
+		case *flags.CreateOAuthClient != "":
+			cmd.CreateOAuthClient(load, *flags.CreateOAuthClient)
+		case *flags.EnableOAuthClient != "":
+			cmd.SetOAuthClientEnabled(load, *flags.EnableOAuthClient, true)
+		case *flags.DisableOAuthClient != "":
+			cmd.SetOAuthClientEnabled(load, *flags.DisableOAuthClient, false)
+		default:
+			cmd.ListOAuthClients(load)
+		}
+	}
+
 	return &BootOptions{
 		WorkDir:   *flags.OptWorkDir,
 		EnvPrefix: *flags.OptEnvPrefix,
@@ -264,7 +576,19 @@ func (cmd *Command) CreateUserAdmin(cfg *config.Config) {
 		os.Exit(0)
 	}
 
-	createdUserAdmin(rc, cfg)
+	passwords, err := initPasswords(cfg)
+	if err != nil {
+		fmt.Printf("failed to init passwords. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	username, password, err := createdUserAdmin(rc, passwords, *cmd.Flags.OptUsername, *cmd.Flags.OptEmail)
+	if err != nil {
+		fmt.Printf("failed to create user admin. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("user admin created. username = [%s], password = [%s]\n", username, password)
 
 	os.Exit(0)
 
@@ -285,18 +609,112 @@ func (cmd *Command) UpdatePasswordUserAdmin(cfg *config.Config) {
 		os.Exit(0)
 	}
 
-	updatePasswordUserAdmin(rc, cfg)
+	passwords, err := initPasswords(cfg)
+	if err != nil {
+		fmt.Printf("failed to init passwords. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	password, err := updatePasswordUserAdmin(rc, passwords, *cmd.Flags.OptUsername)
+	if err != nil {
+		fmt.Printf("failed to update password user admin. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("password user admin updated. username = [%s], password = [%s]\n", *cmd.Flags.OptUsername, password)
+
+	os.Exit(0)
+}
+
+func (cmd *Command) RevokeUserTokens(cfg *config.Config, userId int64) {
+	repo, err := repositories.NewRepository(cfg)
+	if err != nil {
+		fmt.Printf("failed to read database configuration. Error = [%v]", err)
+		os.Exit(0)
+	}
+
+	ctx := context.Background()
+
+	rc, err := repo.Connected(ctx)
+	if err != nil {
+		fmt.Printf("failed to connect to database. Error = [%v]", err)
+		os.Exit(0)
+	}
+
+	deps := &services.Dependencies{
+		Revocation: revocation.NewStore(rc, cfg.RevocationSyncInterval, cfg.RevocationCleanupInterval),
+	}
+
+	if err := services.NewService(ctx, rc, cfg, deps).RevokeUserTokens(userId, services.RevokeReasonAdminRevoked); err != nil {
+		fmt.Printf("failed to revoke user tokens. Error = [%v]", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("every token of user [%d] revoked\n", userId)
 
 	os.Exit(0)
 }
 
-func updatePasswordUserAdmin(rc *repositories.RepositoryContext, cfg *config.Config) string {
+// updatePasswordUserAdmin replaces the password of the user admin with one
+// generated to meet the password policy.
+func updatePasswordUserAdmin(rc *repositories.RepositoryContext, passwords *password.Policy, username string) (string, error) {
+	ctx := context.Background()
 
-	return ""
+	user, err := rc.FindAdminUserByUsernameOrEmail(ctx, username)
+	if err != nil {
+		return "", err
+	}
+
+	if user == nil {
+		return "", fmt.Errorf("user admin %s not found", username)
+	}
+
+	password, err := passwords.Generate(user.Username, user.Email)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := util.HashPassword(password)
+	if err != nil {
+		return "", err
+	}
+
+	if err := rc.UpdateAdminUserPassword(ctx, user.Id, hash); err != nil {
+		return "", err
+	}
 
+	return password, nil
 }
 
-func createdUserAdmin(rc *repositories.RepositoryContext, cfg *config.Config) (string, string) {
+// createdUserAdmin creates a super admin with a password generated to meet
+// the password policy.
+func createdUserAdmin(rc *repositories.RepositoryContext, passwords *password.Policy, username string, email string) (string, string, error) {
+	if email == "" {
+		email = username + "@localhost"
+	}
+
+	password, err := passwords.Generate(username, email)
+	if err != nil {
+		return "", "", err
+	}
+
+	hash, err := util.HashPassword(password)
+	if err != nil {
+		return "", "", err
+	}
+
+	user := &models.AdminUser{
+		Username: username,
+		Email:    email,
+		Name:     username,
+		Password: hash,
+		Role:     enums.AdminRoleSuperAdmin.String(),
+		IsActive: true,
+	}
+
+	if err := rc.CreateAdminUser(context.Background(), user); err != nil {
+		return "", "", err
+	}
 
-	return "", ""
+	return user.Username, password, nil
 }
@@ -0,0 +1,19 @@
+package init
+
+import (
+	"application/config"
+	"application/pkg/errorsink"
+	"fmt"
+)
+
+func newErrorSink(cfg *config.Config) (errorsink.Sink, error) {
+	sink, err := errorsink.New(errorsink.Config{
+		Driver: cfg.ErrorSinkDriver,
+		File:   resolvePath(cfg.WorkDir, cfg.ErrorSinkFile),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init error sink: %w", err)
+	}
+
+	return sink, nil
+}
@@ -0,0 +1,128 @@
+package init
+
+import (
+	"application/config"
+	pkgjwt "application/pkg/jwt"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// InitJwtAdapter loads the configured signing and verification keys. When a key
+// directory is configured the keys are reloaded and rotated in the background.
+func InitJwtAdapter(cfg *config.Config) (*pkgjwt.JwtAdapter, error) {
+	publicKeyFiles := make([]string, 0, len(cfg.JwtPublicKeyFiles))
+	for _, file := range cfg.JwtPublicKeyFiles {
+		publicKeyFiles = append(publicKeyFiles, resolvePath(cfg.WorkDir, file))
+	}
+
+	if cfg.JwtKeysDir != "" {
+		return initRotatingJwtAdapter(cfg, publicKeyFiles)
+	}
+
+	keys, err := pkgjwt.LoadKeySet(pkgjwt.KeyConfig{
+		Algorithm:      cfg.JwtAlgorithm,
+		KeyId:          cfg.JwtKeyId,
+		Secret:         cfg.JwtSecret,
+		PrivateKeyFile: resolvePath(cfg.WorkDir, cfg.JwtPrivateKeyFile),
+		PublicKeyFiles: publicKeyFiles,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load jwt keys: %w", err)
+	}
+
+	if key, err := keys.SigningKey(); err == nil {
+		log.Info().Str("kid", key.Id).Str("alg", key.Algorithm.Alg()).Msg("jwt signing key loaded")
+	} else {
+		log.Warn().Msg("jwt signing key not configured, tokens can only be verified")
+	}
+
+	return newJwtAdapter(cfg, keys), nil
+}
+
+func initRotatingJwtAdapter(cfg *config.Config, publicKeyFiles []string) (*pkgjwt.JwtAdapter, error) {
+	store, err := newJwtKeyStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// a stale lock left by a crashed instance is taken over after a minute
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if err := store.Ready(ctx); err != nil {
+		return nil, fmt.Errorf("failed to prepare jwt signing key: %w", err)
+	}
+
+	var extra []*pkgjwt.Key
+	for _, file := range publicKeyFiles {
+		key, err := pkgjwt.LoadPublicKeyFile(file, store.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		extra = append(extra, key)
+	}
+
+	keys := pkgjwt.NewKeySet()
+	if err := store.Refresh(keys, extra...); err != nil {
+		return nil, fmt.Errorf("failed to load jwt keys: %w", err)
+	}
+
+	store.StartRotation(context.Background(), keys, pkgjwt.DefaultKeyRefreshInterval, cfg.JwtKeyRotationInterval, extra...)
+
+	log.Info().Str("dir", store.Dir).Dur("rotation_interval", cfg.JwtKeyRotationInterval).Dur("overlap", store.Overlap).Msg("jwt key rotation enabled")
+
+	return newJwtAdapter(cfg, keys), nil
+}
+
+func newJwtAdapter(cfg *config.Config, keys *pkgjwt.KeySet) *pkgjwt.JwtAdapter {
+	adapter := pkgjwt.NewJwtAdapterWithKeySet(cfg.JwtIssuer, keys)
+	adapter.Audience = cfg.JwtAudience
+	adapter.Leeway = cfg.JwtClockSkew
+
+	return adapter
+}
+
+func newJwtKeyStore(cfg *config.Config) (*pkgjwt.KeyStore, error) {
+	return pkgjwt.NewKeyStore(resolvePath(cfg.WorkDir, cfg.JwtKeysDir), cfg.JwtAlgorithm, cfg.JwtKeyOverlap)
+}
+
+func (cmd *Command) RotateJwtKey(cfg *config.Config) {
+	if cfg.JwtKeysDir == "" {
+		fmt.Println("JWT_KEYS_DIR is not configured")
+		os.Exit(1)
+	}
+
+	store, err := newJwtKeyStore(cfg)
+	if err != nil {
+		fmt.Printf("failed to open jwt key directory. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	key, err := store.Rotate()
+	if err != nil {
+		fmt.Printf("failed to rotate jwt signing key. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	if key.Status == pkgjwt.KeyStatusPending {
+		fmt.Printf("jwt signing key published. kid = [%s], alg = [%s], signs from = [%s]\n", key.Id, key.Algorithm, store.ActivatesAt(key).Format(time.RFC3339))
+	} else {
+		fmt.Printf("jwt signing key created. kid = [%s], alg = [%s]\n", key.Id, key.Algorithm)
+	}
+
+	os.Exit(0)
+}
+
+// resolvePath makes a relative path relative to the working directory.
+func resolvePath(workDir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(workDir, path)
+}
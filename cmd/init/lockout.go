@@ -0,0 +1,96 @@
+package init
+
+import (
+	"application/app/repositories"
+	"application/app/services"
+	"application/config"
+	"application/pkg/audit"
+	"application/pkg/lockout"
+	"context"
+	"fmt"
+	"os"
+)
+
+func newLockoutGuard(cfg *config.Config, repo *repositories.RepositoryContext) *lockout.Guard {
+	return lockout.NewGuard(repo, lockout.Config{
+		Threshold:   cfg.LockoutThreshold,
+		IpThreshold: cfg.LockoutIpThreshold,
+		BaseDelay:   cfg.LockoutBaseDelay,
+		MaxDelay:    cfg.LockoutMaxDelay,
+		Window:      cfg.LockoutWindow,
+		CleanTTL:    cfg.LockoutCacheTTL,
+	})
+}
+
+// newPasswordResetGuard throttles the forgot password requests per username
+// and per IP address, with the delays of the login lockout.
+func newPasswordResetGuard(cfg *config.Config, repo *repositories.RepositoryContext) *lockout.Guard {
+	threshold := cfg.PasswordResetThreshold
+	if threshold <= 0 {
+		threshold = services.DefaultPasswordResetThreshold
+	}
+
+	ipThreshold := cfg.PasswordResetIpThreshold
+	if ipThreshold <= 0 {
+		ipThreshold = services.DefaultPasswordResetIpThreshold
+	}
+
+	return lockout.NewGuard(repo, lockout.Config{
+		Threshold:   threshold,
+		IpThreshold: ipThreshold,
+		BaseDelay:   cfg.LockoutBaseDelay,
+		MaxDelay:    cfg.LockoutMaxDelay,
+		Window:      cfg.LockoutWindow,
+		CleanTTL:    cfg.LockoutCacheTTL,
+		Prefix:      services.PasswordResetLockoutPrefix,
+	})
+}
+
+// UnlockAccount clears the failed login attempts of an account, an IP address or both.
+func (cmd *Command) UnlockAccount(cfg *config.Config, account string, ipAddress string) {
+	repo, err := repositories.NewRepository(cfg)
+	if err != nil {
+		fmt.Printf("failed to read database configuration. Error = [%v]", err)
+		os.Exit(0)
+	}
+
+	ctx := context.Background()
+
+	rc, err := repo.Connected(ctx)
+	if err != nil {
+		fmt.Printf("failed to connect to database. Error = [%v]", err)
+		os.Exit(0)
+	}
+
+	deps := &services.Dependencies{
+		Lockout: newLockoutGuard(cfg, rc),
+		Audit:   audit.NewRecorder(rc),
+	}
+	service := services.NewService(ctx, rc, cfg, deps)
+
+	for _, target := range []struct {
+		scope string
+		key   string
+	}{
+		{lockout.ScopeAccount, account},
+		{lockout.ScopeIp, ipAddress},
+	} {
+		if target.key == "" {
+			continue
+		}
+
+		unlocked, err := service.UnlockAccount(target.scope, target.key, "cli")
+		if err != nil {
+			fmt.Printf("failed to unlock %s [%s]. Error = [%v]", target.scope, target.key, err)
+			os.Exit(1)
+		}
+
+		if unlocked {
+			fmt.Printf("%s [%s] unlocked\n", target.scope, target.key)
+		} else {
+			fmt.Printf("%s [%s] has no failed logins\n", target.scope, target.key)
+		}
+	}
+
+	os.Exit(0)
+}
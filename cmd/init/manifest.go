@@ -28,10 +28,8 @@ func UpgradeDB(config *config.Config) error {
 	}
 
 	// load database url
-	log.Info().Msg(fmt.Sprintf("Load Database URL. value = [%s]", config.DatabaseDSN))
 	uri := fmt.Sprintf("%s://%s:%s@%s:%s/%s?sslmode=disable", database.DriverPostgresSql, config.DatabaseUserMigration, config.DatabasePassMigration, config.DatabaseHost, config.DatabasePort, config.DatabaseNameMigration)
 
-	log.Info().Str("uri", uri).Msg("uri migration database")
 	// source dir
 	path := filepath.Join(config.WorkDir, "/migrations")
 	log.Info().Str("path", path).Msg("migrations path")
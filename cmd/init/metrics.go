@@ -0,0 +1,38 @@
+package init
+
+import (
+	"application/config"
+	"application/pkg/metrics"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+func metricsPath(cfg *config.Config) string {
+	if cfg.MetricsPath == "" {
+		return metrics.DefaultPath
+	}
+
+	return cfg.MetricsPath
+}
+
+// newMetricsServer serves the metrics on the admin port, nil when they are
+// served with the API.
+func newMetricsServer(cfg *config.Config) *http.Server {
+	if cfg.MetricsPort == 0 {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath(cfg), metrics.Handler())
+
+	log.Info().Uint16("port", cfg.MetricsPort).Str("path", metricsPath(cfg)).Msg("metrics are served on the admin port")
+
+	return &http.Server{
+		Addr:              fmt.Sprintf("0.0.0.0:%d", cfg.MetricsPort),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
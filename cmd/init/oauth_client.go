@@ -0,0 +1,83 @@
+package init
+
+import (
+	"application/app/models"
+	"application/app/repositories"
+	"application/config"
+	"application/pkg/oauth"
+	"application/pkg/util"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const generatedOAuthClientIdLength = 24
+
+func newOAuthClientStore(cfg *config.Config, repo *repositories.RepositoryContext) *oauth.Store {
+	return oauth.NewStore(repo, []byte(cfg.ClientSecretKey), cfg.OAuthClientCacheTTL)
+}
+
+// CreateOAuthClient registers a client credentials client and prints its
+// generated client id and secret once.
+func (cmd *Command) CreateOAuthClient(cfg *config.Config, name string) {
+	rc, ctx := cmd.connect(cfg)
+
+	secret := util.GenerateRandomString(generatedClientSecretLength)
+
+	client := &models.OAuthClient{
+		ClientId:   strings.ToLower(util.GenerateRandomString(generatedOAuthClientIdLength)),
+		Name:       name,
+		SecretHash: util.HashSecret([]byte(cfg.ClientSecretKey), secret),
+		Scopes:     splitList(*cmd.Flags.OptScopes),
+		IsEnabled:  true,
+	}
+
+	if err := rc.CreateOAuthClient(ctx, client); err != nil {
+		fmt.Printf("failed to create oauth client. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("oauth client created. name = [%s], client_id = [%s], client_secret = [%s]\n", client.Name, client.ClientId, secret)
+
+	os.Exit(0)
+}
+
+func (cmd *Command) SetOAuthClientEnabled(cfg *config.Config, clientId string, enabled bool) {
+	rc, ctx := cmd.connect(cfg)
+
+	affected, err := rc.UpdateOAuthClientEnabled(ctx, clientId, enabled)
+	if err != nil {
+		fmt.Printf("failed to update oauth client. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	if affected == 0 {
+		fmt.Printf("oauth client [%s] not found\n", clientId)
+		os.Exit(1)
+	}
+
+	fmt.Printf("oauth client [%s] enabled = [%v]\n", clientId, enabled)
+
+	os.Exit(0)
+}
+
+func (cmd *Command) ListOAuthClients(cfg *config.Config) {
+	rc, ctx := cmd.connect(cfg)
+
+	clients, err := rc.FindOAuthClients(ctx)
+	if err != nil {
+		fmt.Printf("failed to list oauth clients. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	for _, client := range clients {
+		fmt.Printf("client_id = [%s], name = [%s], enabled = [%v], scopes = [%s]\n",
+			client.ClientId,
+			client.Name,
+			client.IsEnabled,
+			strings.Join(client.Scopes, ","),
+		)
+	}
+
+	os.Exit(0)
+}
@@ -0,0 +1,29 @@
+package init
+
+import (
+	"application/config"
+	"application/pkg/oidc"
+)
+
+// newOidcProvider returns nil when no issuer is configured, which disables the
+// single sign-on endpoints.
+func newOidcProvider(cfg *config.Config) *oidc.Provider {
+	if cfg.OidcIssuerUrl == "" {
+		return nil
+	}
+
+	scopes := cfg.OidcScopes
+	if len(scopes) == 0 {
+		scopes = []string{"email", "profile"}
+	}
+
+	return oidc.NewProvider(oidc.Config{
+		IssuerUrl:    cfg.OidcIssuerUrl,
+		ClientId:     cfg.OidcClientId,
+		ClientSecret: cfg.OidcClientSecret,
+		RedirectUrl:  cfg.OidcRedirectUrl,
+		Scopes:       scopes,
+		Leeway:       cfg.JwtClockSkew,
+		DiscoveryTtl: cfg.OidcDiscoveryTtl,
+	}, nil)
+}
@@ -0,0 +1,48 @@
+package init
+
+import (
+	"application/config"
+	"application/pkg/mailer"
+	"application/pkg/password"
+	"application/pkg/util"
+	"fmt"
+)
+
+// initPasswords applies the argon2id cost of new hashes and returns the
+// policy enforced on new passwords.
+func initPasswords(cfg *config.Config) (*password.Policy, error) {
+	util.SetPasswordHashParams(util.Argon2idParams{
+		Memory:      cfg.PasswordArgon2Memory,
+		Iterations:  cfg.PasswordArgon2Iterations,
+		Parallelism: cfg.PasswordArgon2Parallelism,
+	})
+
+	if err := util.PrepareDummyPasswordHash(); err != nil {
+		return nil, err
+	}
+
+	policy, err := password.NewPolicy(password.Config{
+		MinLength:        cfg.PasswordMinLength,
+		MaxLength:        cfg.PasswordMaxLength,
+		MinClasses:       cfg.PasswordMinClasses,
+		BreachedListFile: resolvePath(cfg.WorkDir, cfg.PasswordBreachedListFile),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init password policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+func newMailer(cfg *config.Config) (mailer.Mailer, error) {
+	m, err := mailer.New(mailer.Config{
+		Driver: cfg.MailerDriver,
+		From:   cfg.MailerFrom,
+		Dir:    resolvePath(cfg.WorkDir, cfg.MailerDir),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init mailer: %w", err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,25 @@
+package init
+
+import (
+	"application/config"
+	"application/pkg/secretbox"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// newSecretBox returns nil when SECRET_ENCRYPTION_KEY is not configured, in
+// which case the features storing encrypted secrets are disabled.
+func newSecretBox(cfg *config.Config) (*secretbox.Box, error) {
+	if cfg.SecretEncryptionKey == "" {
+		log.Warn().Msg("SECRET_ENCRYPTION_KEY is not configured, request signing and two-factor authentication are disabled")
+		return nil, nil
+	}
+
+	box, err := secretbox.NewBox(cfg.SecretEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SECRET_ENCRYPTION_KEY: %w", err)
+	}
+
+	return box, nil
+}
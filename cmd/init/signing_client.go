@@ -0,0 +1,78 @@
+package init
+
+import (
+	"application/app/models"
+	"application/app/repositories"
+	"application/config"
+	"application/pkg/secretbox"
+	"application/pkg/signature"
+	"application/pkg/util"
+	"fmt"
+	"os"
+)
+
+const generatedSigningSecretLength = 48
+
+// newSignatureVerifier returns nil without a secret box, in which case signed
+// routes answer 503.
+func newSignatureVerifier(cfg *config.Config, repo *repositories.RepositoryContext, box *secretbox.Box) *signature.Verifier {
+	if box == nil {
+		return nil
+	}
+
+	return signature.NewVerifier(repo, box, cfg.SignatureReplayWindow)
+}
+
+// CreateSigningClient registers a partner signing its requests and prints the shared secret once.
+func (cmd *Command) CreateSigningClient(cfg *config.Config, clientId string) {
+	box, err := secretbox.NewBox(cfg.SecretEncryptionKey)
+	if err != nil {
+		fmt.Printf("invalid SECRET_ENCRYPTION_KEY. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	rc, ctx := cmd.connect(cfg)
+
+	secret := util.GenerateRandomString(generatedSigningSecretLength)
+
+	ciphertext, err := box.Seal([]byte(secret))
+	if err != nil {
+		fmt.Printf("failed to encrypt client secret. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	client := &models.SigningClient{
+		ClientId:         clientId,
+		SecretCiphertext: ciphertext,
+		Scopes:           splitList(*cmd.Flags.OptScopes),
+		IsEnabled:        true,
+	}
+
+	if err := rc.CreateSigningClient(ctx, client); err != nil {
+		fmt.Printf("failed to create signing client. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("signing client created. client id = [%s], secret = [%s]\n", client.ClientId, secret)
+
+	os.Exit(0)
+}
+
+func (cmd *Command) SetSigningClientEnabled(cfg *config.Config, clientId string, enabled bool) {
+	rc, ctx := cmd.connect(cfg)
+
+	affected, err := rc.UpdateSigningClientEnabled(ctx, clientId, enabled)
+	if err != nil {
+		fmt.Printf("failed to update signing client. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	if affected == 0 {
+		fmt.Printf("signing client [%s] not found\n", clientId)
+		os.Exit(1)
+	}
+
+	fmt.Printf("signing client [%s] enabled = [%v]\n", clientId, enabled)
+
+	os.Exit(0)
+}
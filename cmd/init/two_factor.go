@@ -0,0 +1,44 @@
+package init
+
+import (
+	"application/app/enums"
+	"application/app/services"
+	"application/config"
+	"application/pkg/audit"
+	"fmt"
+	"os"
+)
+
+// ResetTwoFactor removes the two-factor authentication of an admin who lost their device and recovery codes.
+func (cmd *Command) ResetTwoFactor(cfg *config.Config, username string) {
+	rc, ctx := cmd.connect(cfg)
+
+	user, err := rc.FindAdminUserByUsernameOrEmail(ctx, username)
+	if err != nil {
+		fmt.Printf("failed to find user admin. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	if user == nil {
+		fmt.Printf("user admin [%s] not found\n", username)
+		os.Exit(1)
+	}
+
+	recorder := audit.NewRecorder(rc)
+	service := services.NewService(ctx, rc, cfg, &services.Dependencies{Audit: recorder})
+
+	if err := service.ResetTwoFactor(user.Id); err != nil {
+		fmt.Printf("failed to reset two-factor authentication. Error = [%v]\n", err)
+		os.Exit(1)
+	}
+
+	recorder.Record(ctx, audit.Event{
+		Name:     enums.AuditEventMfaReset,
+		Actor:    "cli",
+		Metadata: map[string]any{"user_id": user.Id, "username": user.Username},
+	})
+
+	fmt.Printf("two-factor authentication of [%s] reset\n", user.Username)
+
+	os.Exit(0)
+}
@@ -0,0 +1,224 @@
+// Command mock-oidc is a minimal OpenID Connect provider to try the single
+// sign-on locally. Every authorization request is approved at once for the
+// configured user, nothing is persisted and the keys change on each start.
+package main
+
+import (
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/oidc"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const codeLifetime = time.Minute
+
+type authorization struct {
+	ClientId      string
+	RedirectUri   string
+	Nonce         string
+	CodeChallenge string
+	ExpiresAt     time.Time
+}
+
+type server struct {
+	issuer       string
+	clientId     string
+	clientSecret string
+	claims       map[string]any
+	signer       *pkgjwt.JwtAdapter
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:9000", "listen address")
+	issuer := flag.String("issuer", "http://127.0.0.1:9000", "issuer URL, must match OIDC_ISSUER_URL")
+	clientId := flag.String("client-id", "admin-console", "accepted client id")
+	clientSecret := flag.String("client-secret", "", "client secret, empty for a public client")
+	subject := flag.String("subject", "mock-user-1", "subject of the signed in user")
+	email := flag.String("email", "jane.doe@example.com", "email of the signed in user")
+	name := flag.String("name", "Jane Doe", "name of the signed in user")
+	username := flag.String("username", "jane.doe", "preferred username of the signed in user")
+	groups := flag.String("groups", "admins", "comma separated groups of the signed in user")
+	flag.Parse()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to generate the signing key")
+	}
+
+	key, err := pkgjwt.NewAsymmetricKey(&privateKey.PublicKey, privateKey, nil)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create the signing key")
+	}
+
+	keys := pkgjwt.NewKeySet()
+	keys.SetSigningKey(key)
+
+	s := &server{
+		issuer:       strings.TrimSuffix(*issuer, "/"),
+		clientId:     *clientId,
+		clientSecret: *clientSecret,
+		claims: map[string]any{
+			"sub":                *subject,
+			"email":              *email,
+			"email_verified":     true,
+			"name":               *name,
+			"preferred_username": *username,
+			"groups":             strings.Split(*groups, ","),
+		},
+		signer: &pkgjwt.JwtAdapter{Issuer: strings.TrimSuffix(*issuer, "/"), Audience: []string{*clientId}, Keys: keys},
+		codes:  make(map[string]authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("GET /authorize", s.authorize)
+	mux.HandleFunc("POST /token", s.token)
+
+	log.Info().Str("addr", *addr).Str("issuer", s.issuer).Str("client_id", s.clientId).Msg("mock oidc provider listening")
+
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		log.Fatal().Err(err).Msg("mock oidc provider stopped")
+	}
+}
+
+func (s *server) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJson(w, http.StatusOK, oidc.Metadata{
+		Issuer:                        s.issuer,
+		AuthorizationEndpoint:         s.issuer + "/authorize",
+		TokenEndpoint:                 s.issuer + "/token",
+		JwksUri:                       s.issuer + "/jwks",
+		CodeChallengeMethodsSupported: []string{oidc.CodeChallengeMethod},
+	})
+}
+
+func (s *server) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJson(w, http.StatusOK, s.signer.Keys.JWKS())
+}
+
+// authorize approves the request and redirects back with a code.
+func (s *server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	redirectUri, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectUri.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("response_type") != "code" || query.Get("client_id") != s.clientId ||
+		query.Get("code_challenge_method") != oidc.CodeChallengeMethod || query.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := oidc.RandomValue()
+
+	s.mu.Lock()
+	s.codes[code] = authorization{
+		ClientId:      s.clientId,
+		RedirectUri:   redirectUri.String(),
+		Nonce:         query.Get("nonce"),
+		CodeChallenge: query.Get("code_challenge"),
+		ExpiresAt:     time.Now().Add(codeLifetime),
+	}
+	s.mu.Unlock()
+
+	callback := redirectUri.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirectUri.RawQuery = callback.Encode()
+
+	http.Redirect(w, r, redirectUri.String(), http.StatusFound)
+}
+
+// token redeems a code once, checking the client, the redirect URI and the PKCE verifier.
+func (s *server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	if !s.authenticate(r) {
+		tokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	s.mu.Lock()
+	grant, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	if !ok || time.Now().After(grant.ExpiresAt) || grant.RedirectUri != r.PostForm.Get("redirect_uri") ||
+		subtle.ConstantTimeCompare([]byte(oidc.CodeChallenge(r.PostForm.Get("code_verifier"))), []byte(grant.CodeChallenge)) != 1 {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	custom := make(map[string]any, len(s.claims)+1)
+	for name, value := range s.claims {
+		if name != "sub" {
+			custom[name] = value
+		}
+	}
+	if grant.Nonce != "" {
+		custom["nonce"] = grant.Nonce
+	}
+
+	idToken, err := pkgjwt.IssueClaims(s.signer, &pkgjwt.Claims[map[string]any]{
+		RegisteredClaims: pkgjwt.RegisteredClaims{Subject: s.claims["sub"].(string)},
+		Custom:           custom,
+	}, 5*time.Minute)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJson(w, http.StatusOK, oidc.TokenResponse{
+		AccessToken: oidc.RandomValue(),
+		TokenType:   pkgjwt.TokenTypeBearer,
+		IdToken:     idToken.Token,
+		ExpiresIn:   300,
+	})
+}
+
+func (s *server) authenticate(r *http.Request) bool {
+	clientId, clientSecret, basic := r.BasicAuth()
+	if basic {
+		clientId, _ = url.QueryUnescape(clientId)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientId = r.PostForm.Get("client_id")
+	}
+
+	if clientId != s.clientId {
+		return false
+	}
+
+	return s.clientSecret == "" || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(s.clientSecret)) == 1
+}
+
+func tokenError(w http.ResponseWriter, status int, code string) {
+	writeJson(w, status, map[string]string{"error": code})
+}
+
+func writeJson(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
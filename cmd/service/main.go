@@ -2,6 +2,7 @@ package main
 
 import (
 	appiinit "application/cmd/init"
+	"application/pkg/logging"
 	"os"
 	"time"
 )
@@ -10,6 +11,9 @@ func main() {
 	// capture start time server
 	startTime := time.Now()
 
+	// keep secrets out of every log entry, before anything is logged
+	logging.Setup(os.Stderr)
+
 	// init cli
 	cli := appiinit.NewCLI(os.Args)
 
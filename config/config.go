@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type Config struct {
 	/// Work directory path
 	WorkDir     string `envconfig:"-"`
@@ -10,8 +12,10 @@ type Config struct {
 	AppMode string `envconfig:"APP_MODE"`
 
 	// Basic Auth
-	BasicAuthUsername string `envconfig:"BASIC_AUTH_USERNAME"`
-	BasicAuthPassword string `envconfig:"BASIC_AUTH_PASSWORD"`
+	BasicAuthUsername string        `envconfig:"BASIC_AUTH_USERNAME"`
+	BasicAuthPassword string        `envconfig:"BASIC_AUTH_PASSWORD"`
+	BasicAuthCacheTTL time.Duration `envconfig:"BASIC_AUTH_CACHE_TTL"`
+	ClientSecretKey   string        `envconfig:"CLIENT_SECRET_KEY"`
 
 	// Database config migration
 	DatabaseNameMigration string `envconfig:"MIGRATION_DB_NAME"`
@@ -20,28 +24,133 @@ type Config struct {
 	DatabaseUpgradeOnBoot bool   `envconfig:"DB_BOOT_UPGRADE"`
 
 	// Database config main
-	DatabaseDSN             string `envconfig:"DATABASE_DSN"`
-	DatabaseName            string `envconfig:"DATABASE_NAME"`
-	DatabaseHost            string `envconfig:"DATABASE_HOST"`
-	DatabasePort            string `envconfig:"DATABASE_PORT"`
-	DatabaseUser            string `envconfig:"DATABASE_USER"`
-	DatabasePass            string `envconfig:"DATABASE_PASS"`
-	DatabaseTimezone        string `envconfig:"DATABASE_TIMEZONE"`
-	DatabaseSslMode         string `envconfig:"DATABASE_SSL_MODE"`
-	DatabaseMaxIdleConn     int    `envconfig:"DATABASE_MAX_IDLE_CONN"`
-	DatabaseMaxConnLifetime int    `envconfig:"DATABASE_CONN_LIFETIME"`
-	DatabaseOpenConn        int    `envconfig:"DATABASE_OPEN_CONN"`
+	DatabaseDSN             string        `envconfig:"DATABASE_DSN"`
+	DatabaseName            string        `envconfig:"DATABASE_NAME"`
+	DatabaseHost            string        `envconfig:"DATABASE_HOST"`
+	DatabasePort            string        `envconfig:"DATABASE_PORT"`
+	DatabaseUser            string        `envconfig:"DATABASE_USER"`
+	DatabasePass            string        `envconfig:"DATABASE_PASS"`
+	DatabaseTimezone        string        `envconfig:"DATABASE_TIMEZONE"`
+	DatabaseSslMode         string        `envconfig:"DATABASE_SSL_MODE"`
+	DatabaseMaxIdleConn     int           `envconfig:"DATABASE_MAX_IDLE_CONN"`
+	DatabaseMaxConnLifetime int           `envconfig:"DATABASE_CONN_LIFETIME"`
+	DatabaseOpenConn        int           `envconfig:"DATABASE_OPEN_CONN"`
+	DatabaseSlowThreshold   time.Duration `envconfig:"DATABASE_SLOW_THRESHOLD"`
 
 	// SERVER
 	ServerPort     uint16 `envconfig:"SERVER_PORT"`
 	NodeId         string `envconfig:"NODE_ID"`
 	ServerTimeZone string `envconfig:"TZ"`
 
+	// Metrics
+	MetricsPort uint16 `envconfig:"METRICS_PORT"`
+	MetricsPath string `envconfig:"METRICS_PATH"`
+
 	// LOGGING
-	LogLevel string `envconfig:"LOG_LEVEL"`
+	LogLevel        string   `envconfig:"LOG_LEVEL"`
+	LogRedactFields []string `envconfig:"LOG_REDACT_FIELDS"`
+
+	// Access log
+	AccessLogSkipPaths  []string `envconfig:"ACCESS_LOG_SKIP_PATHS"`
+	AccessLogSampleRate float64  `envconfig:"ACCESS_LOG_SAMPLE_RATE"`
+	AccessLogHeaders    []string `envconfig:"ACCESS_LOG_HEADERS"`
+	AccessLogRedact     []string `envconfig:"ACCESS_LOG_REDACT"`
+
+	// ERROR RESPONSES
+	ErrorProblemJson bool   `envconfig:"ERROR_PROBLEM_JSON"`
+	ErrorTypeBaseUrl string `envconfig:"ERROR_TYPE_BASE_URL"`
+
+	// Error tracking of recovered panics
+	ErrorSinkDriver string `envconfig:"ERROR_SINK_DRIVER"`
+	ErrorSinkFile   string `envconfig:"ERROR_SINK_FILE"`
 
 	// JWT
-	JwtSecret string `envconfig:"JWT_SECRET"`
-	JwtExpire int64  `envconfig:"JWT_EXPIRE"`
-	JwtIssuer string `envconfig:"JWT_ISSUER"`
+	JwtSecret              string `envconfig:"JWT_SECRET"`
+	JwtExpire              int64  `envconfig:"JWT_EXPIRE"`                // access token lifetime in hours, superseded by JwtAccessExpireMinutes
+	JwtAccessExpireMinutes int64  `envconfig:"JWT_ACCESS_EXPIRE_MINUTES"` // access token lifetime in minutes
+	JwtRefreshExpire       int64  `envconfig:"JWT_REFRESH_EXPIRE"`        // refresh token lifetime in hours
+	JwtIssuer              string `envconfig:"JWT_ISSUER"`
+
+	// JWT claims validation
+	JwtAudience  []string      `envconfig:"JWT_AUDIENCE"`
+	JwtClockSkew time.Duration `envconfig:"JWT_CLOCK_SKEW"`
+
+	// JWT signing keys
+	JwtAlgorithm      string   `envconfig:"JWT_ALGORITHM"`
+	JwtKeyId          string   `envconfig:"JWT_KEY_ID"`
+	JwtPrivateKeyFile string   `envconfig:"JWT_PRIVATE_KEY_FILE"`
+	JwtPublicKeyFiles []string `envconfig:"JWT_PUBLIC_KEY_FILES"`
+
+	// JWT key rotation
+	JwtKeysDir             string        `envconfig:"JWT_KEYS_DIR"`
+	JwtKeyRotationInterval time.Duration `envconfig:"JWT_KEY_ROTATION_INTERVAL"`
+	JwtKeyOverlap          time.Duration `envconfig:"JWT_KEY_OVERLAP"`
+
+	// Token revocation
+	RevocationSyncInterval    time.Duration `envconfig:"REVOCATION_SYNC_INTERVAL"`
+	RevocationCleanupInterval time.Duration `envconfig:"REVOCATION_CLEANUP_INTERVAL"`
+
+	// RBAC
+	RbacCacheTTL        time.Duration `envconfig:"RBAC_CACHE_TTL"`
+	RbacRefreshInterval time.Duration `envconfig:"RBAC_REFRESH_INTERVAL"`
+
+	// API keys
+	ApiKeyTouchInterval time.Duration `envconfig:"API_KEY_TOUCH_INTERVAL"`
+
+	// Encryption of stored secrets (signing clients, TOTP)
+	SecretEncryptionKey string `envconfig:"SECRET_ENCRYPTION_KEY"`
+
+	// Two-factor authentication
+	MfaChallengeLifetime time.Duration `envconfig:"MFA_CHALLENGE_LIFETIME"`
+
+	// Request signing
+	SignatureReplayWindow time.Duration `envconfig:"SIGNATURE_REPLAY_WINDOW"`
+
+	// Password policy and hashing
+	PasswordMinLength         int    `envconfig:"PASSWORD_MIN_LENGTH"`
+	PasswordMaxLength         int    `envconfig:"PASSWORD_MAX_LENGTH"`
+	PasswordMinClasses        int    `envconfig:"PASSWORD_MIN_CLASSES"`
+	PasswordBreachedListFile  string `envconfig:"PASSWORD_BREACHED_LIST_FILE"`
+	PasswordArgon2Memory      uint32 `envconfig:"PASSWORD_ARGON2_MEMORY"`
+	PasswordArgon2Iterations  uint32 `envconfig:"PASSWORD_ARGON2_ITERATIONS"`
+	PasswordArgon2Parallelism uint8  `envconfig:"PASSWORD_ARGON2_PARALLELISM"`
+
+	// Password reset
+	PasswordResetUrl           string        `envconfig:"PASSWORD_RESET_URL"`
+	PasswordResetTokenLifetime time.Duration `envconfig:"PASSWORD_RESET_TOKEN_LIFETIME"`
+	PasswordResetThreshold     int           `envconfig:"PASSWORD_RESET_THRESHOLD"`
+	PasswordResetIpThreshold   int           `envconfig:"PASSWORD_RESET_IP_THRESHOLD"`
+	PasswordResetWorkers       int           `envconfig:"PASSWORD_RESET_WORKERS"`
+	PasswordResetQueueSize     int           `envconfig:"PASSWORD_RESET_QUEUE_SIZE"`
+
+	// OAuth2 client credentials
+	OAuthTokenLifetime  time.Duration `envconfig:"OAUTH_TOKEN_LIFETIME"`
+	OAuthClientCacheTTL time.Duration `envconfig:"OAUTH_CLIENT_CACHE_TTL"`
+
+	// OpenID Connect login of admins
+	OidcIssuerUrl       string            `envconfig:"OIDC_ISSUER_URL"`
+	OidcClientId        string            `envconfig:"OIDC_CLIENT_ID"`
+	OidcClientSecret    string            `envconfig:"OIDC_CLIENT_SECRET"`
+	OidcRedirectUrl     string            `envconfig:"OIDC_REDIRECT_URL"`
+	OidcScopes          []string          `envconfig:"OIDC_SCOPES"`
+	OidcFlowLifetime    time.Duration     `envconfig:"OIDC_FLOW_LIFETIME"`
+	OidcRoleClaim       string            `envconfig:"OIDC_ROLE_CLAIM"`
+	OidcRoleMapping     map[string]string `envconfig:"OIDC_ROLE_MAPPING"`
+	OidcDefaultRole     string            `envconfig:"OIDC_DEFAULT_ROLE"`
+	OidcJitProvisioning bool              `envconfig:"OIDC_JIT_PROVISIONING"`
+	OidcLinkByEmail     bool              `envconfig:"OIDC_LINK_BY_EMAIL"`
+	OidcDiscoveryTtl    time.Duration     `envconfig:"OIDC_DISCOVERY_TTL"`
+
+	// Mailer
+	MailerDriver string `envconfig:"MAILER_DRIVER"`
+	MailerFrom   string `envconfig:"MAILER_FROM"`
+	MailerDir    string `envconfig:"MAILER_DIR"`
+
+	// Brute-force protection
+	LockoutThreshold   int           `envconfig:"LOCKOUT_THRESHOLD"`
+	LockoutIpThreshold int           `envconfig:"LOCKOUT_IP_THRESHOLD"`
+	LockoutBaseDelay   time.Duration `envconfig:"LOCKOUT_BASE_DELAY"`
+	LockoutMaxDelay    time.Duration `envconfig:"LOCKOUT_MAX_DELAY"`
+	LockoutWindow      time.Duration `envconfig:"LOCKOUT_WINDOW"`
+	LockoutCacheTTL    time.Duration `envconfig:"LOCKOUT_CACHE_TTL"`
 }
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens
+(
+    id                 BIGSERIAL PRIMARY KEY,
+    family_id          UUID         NOT NULL,
+    parent_id          BIGINT       NULL REFERENCES refresh_tokens (id) ON DELETE SET NULL,
+    user_id            BIGINT       NOT NULL,
+    subject            VARCHAR(255) NOT NULL,
+    token_hash         CHAR(64)     NOT NULL,
+    user_agent         TEXT         NULL,
+    ip_address         VARCHAR(64)  NULL,
+    session_started_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    expires_at         TIMESTAMPTZ  NOT NULL,
+    used_at            TIMESTAMPTZ  NULL,
+    revoked_at         TIMESTAMPTZ  NULL,
+    revoked_reason     VARCHAR(64)  NULL,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS refresh_tokens_token_hash_uindex ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_id_index ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id_index ON refresh_tokens (user_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS token_revocations
+(
+    id         BIGSERIAL PRIMARY KEY,
+    jti        VARCHAR(64) NULL,
+    user_id    BIGINT      NULL,
+    reason     VARCHAR(64) NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS token_revocations_jti_uindex ON token_revocations (jti) WHERE jti IS NOT NULL;
+CREATE INDEX IF NOT EXISTS token_revocations_user_id_index ON token_revocations (user_id);
+CREATE INDEX IF NOT EXISTS token_revocations_expires_at_index ON token_revocations (expires_at);
@@ -0,0 +1,59 @@
+CREATE TABLE IF NOT EXISTS roles
+(
+    id          BIGSERIAL PRIMARY KEY,
+    code        VARCHAR(64)  NOT NULL,
+    name        VARCHAR(128) NOT NULL,
+    description TEXT         NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS roles_code_uindex ON roles (code);
+
+CREATE TABLE IF NOT EXISTS permissions
+(
+    id          BIGSERIAL PRIMARY KEY,
+    code        VARCHAR(128) NOT NULL,
+    description TEXT         NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS permissions_code_uindex ON permissions (code);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role_id       BIGINT NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id BIGINT NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO roles (code, name, description)
+VALUES ('SUPER_ADMIN', 'Super Admin', 'Unrestricted access'),
+       ('ADMIN', 'Admin', 'Manages users and sessions'),
+       ('OPERATOR', 'Operator', 'Day to day operations'),
+       ('VIEWER', 'Viewer', 'Read only access')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (code, description)
+VALUES ('*', 'Every permission'),
+       ('users:read', 'Read admin users'),
+       ('users:write', 'Create and update admin users'),
+       ('roles:read', 'Read roles and permissions'),
+       ('roles:write', 'Manage roles and permissions'),
+       ('sessions:revoke', 'Revoke tokens and sessions of other users')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON (r.code, p.code) IN (
+                                                    ('SUPER_ADMIN', '*'),
+                                                    ('ADMIN', 'users:read'),
+                                                    ('ADMIN', 'users:write'),
+                                                    ('ADMIN', 'roles:read'),
+                                                    ('ADMIN', 'sessions:revoke'),
+                                                    ('OPERATOR', 'users:read'),
+                                                    ('OPERATOR', 'roles:read'),
+                                                    ('VIEWER', 'users:read')
+    )
+ON CONFLICT DO NOTHING;
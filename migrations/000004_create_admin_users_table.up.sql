@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS admin_users
+(
+    id            BIGSERIAL PRIMARY KEY,
+    username      VARCHAR(64)  NOT NULL,
+    email         VARCHAR(255) NOT NULL,
+    name          VARCHAR(255) NOT NULL,
+    password      VARCHAR(255) NOT NULL,
+    role          VARCHAR(64)  NOT NULL REFERENCES roles (code),
+    is_active     BOOLEAN      NOT NULL DEFAULT TRUE,
+    last_login_at TIMESTAMPTZ  NULL,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at    TIMESTAMPTZ  NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS admin_users_username_uindex ON admin_users (LOWER(username)) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS admin_users_email_uindex ON admin_users (LOWER(email)) WHERE deleted_at IS NULL;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    scope          VARCHAR(16)  NOT NULL,
+    key            VARCHAR(255) NOT NULL,
+    failures       INTEGER      NOT NULL DEFAULT 0,
+    locked_until   TIMESTAMPTZ  NULL,
+    last_failed_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, key)
+);
+
+CREATE TABLE IF NOT EXISTS audit_events
+(
+    id         BIGSERIAL PRIMARY KEY,
+    event      VARCHAR(64)  NOT NULL,
+    actor      VARCHAR(255) NULL,
+    ip_address VARCHAR(64)  NULL,
+    metadata   JSONB        NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_event_index ON audit_events (event);
+CREATE INDEX IF NOT EXISTS audit_events_created_at_index ON audit_events (created_at);
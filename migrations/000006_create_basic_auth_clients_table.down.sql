@@ -0,0 +1 @@
+DROP TABLE IF EXISTS basic_auth_clients;
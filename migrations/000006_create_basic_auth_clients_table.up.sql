@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS basic_auth_clients
+(
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(64)  NOT NULL,
+    secret_hash VARCHAR(255) NOT NULL,
+    scopes      JSONB        NOT NULL DEFAULT '[]',
+    routes      JSONB        NOT NULL DEFAULT '[]',
+    is_enabled  BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS basic_auth_clients_name_uindex ON basic_auth_clients (LOWER(name));
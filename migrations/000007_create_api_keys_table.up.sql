@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id              BIGSERIAL PRIMARY KEY,
+    name            VARCHAR(255) NOT NULL,
+    prefix          VARCHAR(16)  NOT NULL,
+    key_hash        VARCHAR(64)  NOT NULL,
+    scopes          JSONB        NOT NULL DEFAULT '[]',
+    rate_limit_tier VARCHAR(32)  NOT NULL DEFAULT 'STANDARD',
+    expires_at      TIMESTAMPTZ  NULL,
+    last_used_at    TIMESTAMPTZ  NULL,
+    revoked_at      TIMESTAMPTZ  NULL,
+    created_by      BIGINT       NULL REFERENCES admin_users (id),
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_prefix_uindex ON api_keys (prefix);
+
+INSERT INTO permissions (code, description)
+VALUES ('api_keys:read', 'Read API keys'),
+       ('api_keys:write', 'Create and revoke API keys')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON (r.code, p.code) IN (
+                                                    ('ADMIN', 'api_keys:read'),
+                                                    ('ADMIN', 'api_keys:write'),
+                                                    ('OPERATOR', 'api_keys:read')
+    )
+ON CONFLICT DO NOTHING;
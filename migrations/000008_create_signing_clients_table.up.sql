@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS signing_clients
+(
+    id                BIGSERIAL PRIMARY KEY,
+    client_id         VARCHAR(64) NOT NULL,
+    secret_ciphertext TEXT        NOT NULL,
+    scopes            JSONB       NOT NULL DEFAULT '[]',
+    is_enabled        BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS signing_clients_client_id_uindex ON signing_clients (client_id);
@@ -0,0 +1,15 @@
+ALTER TABLE admin_users
+    ADD COLUMN IF NOT EXISTS totp_secret         TEXT        NULL,
+    ADD COLUMN IF NOT EXISTS totp_enabled_at     TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS totp_last_used_step BIGINT      NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS admin_recovery_codes
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES admin_users (id) ON DELETE CASCADE,
+    code_hash  VARCHAR(64) NOT NULL,
+    used_at    TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS admin_recovery_codes_user_id_code_hash_uindex ON admin_recovery_codes (user_id, code_hash);
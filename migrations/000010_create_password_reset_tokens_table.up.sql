@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS password_reset_tokens
+(
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT      NOT NULL REFERENCES admin_users (id) ON DELETE CASCADE,
+    token_hash   VARCHAR(64) NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    used_at      TIMESTAMPTZ NULL,
+    requested_ip VARCHAR(64) NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS password_reset_tokens_token_hash_uindex ON password_reset_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS password_reset_tokens_user_id_index ON password_reset_tokens (user_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS oauth_clients
+(
+    id          BIGSERIAL PRIMARY KEY,
+    client_id   VARCHAR(64)  NOT NULL,
+    name        VARCHAR(128) NOT NULL,
+    secret_hash VARCHAR(255) NOT NULL,
+    scopes      JSONB        NOT NULL DEFAULT '[]',
+    is_enabled  BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS oauth_clients_client_id_uindex ON oauth_clients (client_id);
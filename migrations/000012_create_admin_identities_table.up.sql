@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS admin_identities
+(
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT       NOT NULL REFERENCES admin_users (id) ON DELETE CASCADE,
+    issuer        VARCHAR(255) NOT NULL,
+    subject       VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL DEFAULT '',
+    provisioned   BOOLEAN      NOT NULL DEFAULT FALSE,
+    last_login_at TIMESTAMPTZ  NULL,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS admin_identities_issuer_subject_uindex ON admin_identities (issuer, subject);
+CREATE INDEX IF NOT EXISTS admin_identities_user_id_index ON admin_identities (user_id);
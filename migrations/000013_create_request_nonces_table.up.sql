@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS request_nonces
+(
+    client_id  VARCHAR(64)  NOT NULL,
+    nonce      VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (client_id, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS request_nonces_expires_at_index ON request_nonces (expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE admin_users
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(8) NOT NULL DEFAULT '';
@@ -0,0 +1,121 @@
+package apikey
+
+import (
+	"application/app/models"
+	"application/pkg/cache"
+	"application/pkg/util"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// KeyType starts every key so that leaked keys are easy to recognise.
+	KeyType = "ak"
+
+	prefixLength = 8
+	secretLength = 40
+
+	DefaultTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidKey = errors.New("invalid api key")
+	ErrKeyRevoked = errors.New("api key is revoked")
+	ErrKeyExpired = errors.New("api key is expired")
+)
+
+// Generate creates a key formatted as ak_<prefix>_<secret>. The prefix is
+// stored in clear to find the key, the key itself only as its hash.
+func Generate() (key string, prefix string, hash string) {
+	prefix = strings.ToLower(util.GenerateRandomString(prefixLength))
+	key = KeyType + "_" + prefix + "_" + util.GenerateRandomString(secretLength)
+
+	return key, prefix, Hash(key)
+}
+
+// Hash digests a key. Keys are random enough for a fast hash to be safe.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Parse returns the lookup prefix of a key.
+func Parse(key string) (string, bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != KeyType || len(parts[1]) != prefixLength || parts[2] == "" {
+		return "", false
+	}
+
+	return parts[1], true
+}
+
+// Backend loads keys and records their use.
+type Backend interface {
+	FindApiKeyByPrefix(ctx context.Context, prefix string) (*models.ApiKey, error)
+	UpdateApiKeyLastUsed(ctx context.Context, id int64, usedAt time.Time) error
+}
+
+// Store authenticates API keys. The last use of a key is written at most once
+// per touch interval so that busy partners do not cause a write per request.
+type Store struct {
+	backend       Backend
+	touchInterval time.Duration
+	touched       *cache.Cache[int64, struct{}]
+}
+
+func NewStore(backend Backend, touchInterval time.Duration) *Store {
+	if touchInterval <= 0 {
+		touchInterval = DefaultTouchInterval
+	}
+
+	return &Store{
+		backend:       backend,
+		touchInterval: touchInterval,
+		touched:       cache.New[int64, struct{}](),
+	}
+}
+
+func (s *Store) StartJanitor(ctx context.Context) {
+	s.touched.StartJanitor(ctx, s.touchInterval)
+}
+
+// Authenticate returns the active key matching the presented value.
+func (s *Store) Authenticate(ctx context.Context, value string) (*models.ApiKey, error) {
+	prefix, ok := Parse(value)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	key, err := s.backend.FindApiKeyByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil || subtle.ConstantTimeCompare([]byte(Hash(value)), []byte(key.KeyHash)) != 1 {
+		return nil, ErrInvalidKey
+	}
+
+	if key.RevokedAt != nil {
+		return nil, ErrKeyRevoked
+	}
+
+	now := time.Now()
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return nil, ErrKeyExpired
+	}
+
+	if s.touched.SetIfAbsent(key.Id, struct{}{}, s.touchInterval) {
+		if err := s.backend.UpdateApiKeyLastUsed(ctx, key.Id, now); err != nil {
+			log.Error().Err(err).Int64("api_key_id", key.Id).Msg("failed to record api key use")
+		}
+	}
+
+	return key, nil
+}
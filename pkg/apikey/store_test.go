@@ -0,0 +1,109 @@
+package apikey
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryBackend finds keys by prefix and counts the recorded uses.
+type memoryBackend struct {
+	keys    map[string]*models.ApiKey
+	touches int
+}
+
+func (b *memoryBackend) FindApiKeyByPrefix(_ context.Context, prefix string) (*models.ApiKey, error) {
+	return b.keys[prefix], nil
+}
+
+func (b *memoryBackend) UpdateApiKeyLastUsed(_ context.Context, _ int64, _ time.Time) error {
+	b.touches++
+	return nil
+}
+
+func TestGenerateAndParse(t *testing.T) {
+	key, prefix, hash := Generate()
+
+	if !strings.HasPrefix(key, KeyType+"_"+prefix+"_") {
+		t.Errorf("key = %q, want it to start with the prefix %q", key, prefix)
+	}
+	if hash != Hash(key) || strings.Contains(hash, key) {
+		t.Errorf("hash = %q, want the digest of the key", hash)
+	}
+
+	parsed, ok := Parse(key)
+	if !ok || parsed != prefix {
+		t.Errorf("Parse() = %q, %v, want %q", parsed, ok, prefix)
+	}
+
+	for _, invalid := range []string{"", "ak_short_secret", "sk_" + prefix + "_secret", KeyType + "_" + prefix + "_", KeyType + "_" + prefix} {
+		if _, ok := Parse(invalid); ok {
+			t.Errorf("Parse(%q) = ok, want rejected", invalid)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	active, activePrefix, activeHash := Generate()
+	revoked, revokedPrefix, revokedHash := Generate()
+	expired, expiredPrefix, expiredHash := Generate()
+	expiring, expiringPrefix, expiringHash := Generate()
+
+	backend := &memoryBackend{keys: map[string]*models.ApiKey{
+		activePrefix:   {Id: 1, Prefix: activePrefix, KeyHash: activeHash, RateLimitTier: "PREMIUM"},
+		revokedPrefix:  {Id: 2, Prefix: revokedPrefix, KeyHash: revokedHash, RevokedAt: &past},
+		expiredPrefix:  {Id: 3, Prefix: expiredPrefix, KeyHash: expiredHash, ExpiresAt: &past},
+		expiringPrefix: {Id: 4, Prefix: expiringPrefix, KeyHash: expiringHash, ExpiresAt: &future},
+	}}
+	store := NewStore(backend, 0)
+
+	unknown, _, _ := Generate()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr error
+	}{
+		{"active", active, nil},
+		{"not expired yet", expiring, nil},
+		{"revoked", revoked, ErrKeyRevoked},
+		{"expired", expired, ErrKeyExpired},
+		{"unknown prefix", unknown, ErrInvalidKey},
+		{"wrong secret", active[:len(active)-1] + "x", ErrInvalidKey},
+		{"malformed", "not-a-key", ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := store.Authenticate(context.Background(), tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && key == nil {
+				t.Error("Authenticate() returned no key")
+			}
+		})
+	}
+}
+
+func TestAuthenticateTouchesOncePerInterval(t *testing.T) {
+	key, prefix, hash := Generate()
+	backend := &memoryBackend{keys: map[string]*models.ApiKey{prefix: {Id: 1, Prefix: prefix, KeyHash: hash}}}
+	store := NewStore(backend, time.Hour)
+
+	for range 3 {
+		if _, err := store.Authenticate(context.Background(), key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if backend.touches != 1 {
+		t.Errorf("touches = %d, want the last use written once per interval", backend.touches)
+	}
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"application/app/enums"
+	"application/app/models"
+	"context"
+	"encoding/json"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Backend persists audit events.
+type Backend interface {
+	CreateAuditEvent(ctx context.Context, event *models.AuditEvent) error
+}
+
+type Event struct {
+	Name      enums.CodeAuditEvent
+	Actor     string
+	IpAddress string
+	Metadata  map[string]any
+}
+
+// Recorder writes security relevant events to the log and to the audit trail.
+type Recorder struct {
+	backend Backend
+}
+
+func NewRecorder(backend Backend) *Recorder {
+	return &Recorder{backend: backend}
+}
+
+// Record never fails the caller: an event that cannot be stored is still logged.
+func (r *Recorder) Record(ctx context.Context, event Event) {
+	log.Info().
+		Str("audit_event", event.Name.String()).
+		Str("actor", event.Actor).
+		Str("ip_address", event.IpAddress).
+		Interface("metadata", event.Metadata).
+		Msg("audit event")
+
+	record := &models.AuditEvent{Event: event.Name.String()}
+	if event.Actor != "" {
+		record.Actor = &event.Actor
+	}
+	if event.IpAddress != "" {
+		record.IpAddress = &event.IpAddress
+	}
+	if len(event.Metadata) > 0 {
+		metadata, err := json.Marshal(event.Metadata)
+		if err != nil {
+			log.Error().Err(err).Str("audit_event", event.Name.String()).Msg("failed to encode audit event metadata")
+		} else {
+			value := string(metadata)
+			record.Metadata = &value
+		}
+	}
+
+	if err := r.backend.CreateAuditEvent(ctx, record); err != nil {
+		log.Error().Err(err).Str("audit_event", event.Name.String()).Msg("failed to store audit event")
+	}
+}
@@ -0,0 +1,156 @@
+package basicauth
+
+import (
+	"application/app/models"
+	"application/pkg/cache"
+	"application/pkg/util"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"path"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTTL is how long a verified client is trusted before it is loaded again.
+const DefaultCacheTTL = time.Minute
+
+var (
+	ErrInvalidCredentials = errors.New("invalid basic auth credentials")
+	ErrClientDisabled     = errors.New("basic auth client is disabled")
+)
+
+// Backend loads the registered clients.
+type Backend interface {
+	FindBasicAuthClientByName(ctx context.Context, name string) (*models.BasicAuthClient, error)
+}
+
+// Store authenticates basic auth clients. Secrets are stored as HMAC-SHA256
+// digests under the secret key. Verified credentials are cached to spare a
+// query per request, so disabling a client takes effect within the cache ttl.
+type Store struct {
+	backend  Backend
+	key      []byte
+	ttl      time.Duration
+	verified *cache.Cache[string, *models.BasicAuthClient]
+	legacy   *models.BasicAuthClient
+	password string
+}
+
+func NewStore(backend Backend, key []byte, ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	return &Store{
+		backend:  backend,
+		key:      key,
+		ttl:      ttl,
+		verified: cache.New[string, *models.BasicAuthClient](),
+	}
+}
+
+// WithLegacyCredentials keeps accepting the single BASIC_AUTH_USERNAME and
+// BASIC_AUTH_PASSWORD pair as a client granted every scope and route.
+func (s *Store) WithLegacyCredentials(username string, password string) *Store {
+	if username == "" || password == "" {
+		return s
+	}
+
+	s.legacy = &models.BasicAuthClient{
+		Name:      username,
+		Scopes:    models.StringList{"*"},
+		IsEnabled: true,
+	}
+	s.password = password
+
+	return s
+}
+
+func (s *Store) StartJanitor(ctx context.Context) {
+	s.verified.StartJanitor(ctx, s.ttl)
+}
+
+// Authenticate returns the enabled client matching the credentials.
+func (s *Store) Authenticate(ctx context.Context, name string, secret string) (*models.BasicAuthClient, error) {
+	if name == "" || secret == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	if s.legacy != nil && strings.EqualFold(name, s.legacy.Name) {
+		if !constantTimeEqual(secret, s.password) {
+			return nil, ErrInvalidCredentials
+		}
+		return s.legacy, nil
+	}
+
+	key := cacheKey(name, secret)
+	if client, ok := s.verified.Get(key); ok {
+		return client, nil
+	}
+
+	client, err := s.backend.FindBasicAuthClientByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil || !util.CompareSecret(s.key, client.SecretHash, secret) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !client.IsEnabled {
+		return nil, ErrClientDisabled
+	}
+
+	s.verified.Set(key, client, s.ttl)
+
+	return client, nil
+}
+
+// AllowsRoute reports whether the client may call the route. Routes are gin
+// route templates matched with path.Match, optionally prefixed by a method
+// ("POST /api/v1/orders/*"); a trailing "/*" also matches nested routes. A
+// client without routes may call every route.
+func AllowsRoute(client *models.BasicAuthClient, method string, route string) bool {
+	if len(client.Routes) == 0 {
+		return true
+	}
+
+	for _, pattern := range client.Routes {
+		if allowedMethod, rest, found := strings.Cut(pattern, " "); found {
+			if !strings.EqualFold(allowedMethod, method) {
+				continue
+			}
+			pattern = strings.TrimSpace(rest)
+		}
+
+		if pattern == "*" {
+			return true
+		}
+
+		if matched, _ := path.Match(pattern, route); matched {
+			return true
+		}
+
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(route, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func cacheKey(name string, secret string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(name) + "\x00" + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// constantTimeEqual compares digests so that the length of the expected value does not leak either.
+func constantTimeEqual(given string, expected string) bool {
+	givenSum := sha256.Sum256([]byte(given))
+	expectedSum := sha256.Sum256([]byte(expected))
+
+	return subtle.ConstantTimeCompare(givenSum[:], expectedSum[:]) == 1
+}
@@ -0,0 +1,122 @@
+package basicauth
+
+import (
+	"application/app/models"
+	"application/pkg/util"
+	"context"
+	"errors"
+	"testing"
+)
+
+var testKey = []byte("client-secret-key")
+
+// memoryBackend finds clients by name and counts the lookups.
+type memoryBackend struct {
+	clients map[string]*models.BasicAuthClient
+	finds   int
+}
+
+func (b *memoryBackend) FindBasicAuthClientByName(_ context.Context, name string) (*models.BasicAuthClient, error) {
+	b.finds++
+	return b.clients[name], nil
+}
+
+func newTestStore() (*Store, *memoryBackend) {
+	backend := &memoryBackend{clients: map[string]*models.BasicAuthClient{
+		"orders":   {Id: 1, Name: "orders", SecretHash: util.HashSecret(testKey, "orders-secret"), IsEnabled: true},
+		"disabled": {Id: 2, Name: "disabled", SecretHash: util.HashSecret(testKey, "disabled-secret")},
+	}}
+
+	return NewStore(backend, testKey, 0).WithLegacyCredentials("legacy", "legacy-secret"), backend
+}
+
+func TestAuthenticate(t *testing.T) {
+	store, _ := newTestStore()
+
+	tests := []struct {
+		name    string
+		client  string
+		secret  string
+		wantErr error
+	}{
+		{"valid", "orders", "orders-secret", nil},
+		{"wrong secret", "orders", "other-secret", ErrInvalidCredentials},
+		{"unknown client", "unknown", "orders-secret", ErrInvalidCredentials},
+		{"empty secret", "orders", "", ErrInvalidCredentials},
+		{"disabled", "disabled", "disabled-secret", ErrClientDisabled},
+		{"disabled with a wrong secret", "disabled", "other-secret", ErrInvalidCredentials},
+		{"legacy", "LEGACY", "legacy-secret", nil},
+		{"legacy with a wrong secret", "legacy", "orders-secret", ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := store.Authenticate(context.Background(), tt.client, tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && client == nil {
+				t.Error("Authenticate() returned no client")
+			}
+		})
+	}
+}
+
+func TestAuthenticateUsesTheKey(t *testing.T) {
+	backend := &memoryBackend{clients: map[string]*models.BasicAuthClient{
+		"orders": {Name: "orders", SecretHash: util.HashSecret([]byte("other-key"), "orders-secret"), IsEnabled: true},
+	}}
+
+	if _, err := NewStore(backend, testKey, 0).Authenticate(context.Background(), "orders", "orders-secret"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() error = %v, want a digest under another key rejected", err)
+	}
+}
+
+func TestAuthenticateCachesVerifiedClients(t *testing.T) {
+	store, backend := newTestStore()
+
+	for range 3 {
+		if _, err := store.Authenticate(context.Background(), "orders", "orders-secret"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if backend.finds != 1 {
+		t.Errorf("finds = %d, want the client loaded once", backend.finds)
+	}
+
+	for range 2 {
+		_, _ = store.Authenticate(context.Background(), "orders", "other-secret")
+	}
+	if backend.finds != 3 {
+		t.Errorf("finds = %d, want rejected secrets never cached", backend.finds)
+	}
+}
+
+func TestAllowsRoute(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes models.StringList
+		method string
+		route  string
+		want   bool
+	}{
+		{"no routes", nil, "GET", "/api/v1/orders", true},
+		{"exact", models.StringList{"/api/v1/orders"}, "GET", "/api/v1/orders", true},
+		{"other route", models.StringList{"/api/v1/orders"}, "GET", "/api/v1/users", false},
+		{"method", models.StringList{"POST /api/v1/orders"}, "POST", "/api/v1/orders", true},
+		{"other method", models.StringList{"POST /api/v1/orders"}, "GET", "/api/v1/orders", false},
+		{"pattern", models.StringList{"/api/v1/orders/:id"}, "GET", "/api/v1/orders/:id", true},
+		{"nested", models.StringList{"/api/v1/orders/*"}, "GET", "/api/v1/orders/:id/items", true},
+		{"prefix is not nested", models.StringList{"/api/v1/orders/*"}, "GET", "/api/v1/ordersx", false},
+		{"every route of a method", models.StringList{"get *"}, "GET", "/api/v1/users", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &models.BasicAuthClient{Routes: tt.routes}
+			if got := AllowsRoute(client, tt.method, tt.route); got != tt.want {
+				t.Errorf("AllowsRoute(%v, %s %s) = %v, want %v", tt.routes, tt.method, tt.route, got, tt.want)
+			}
+		})
+	}
+}
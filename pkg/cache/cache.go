@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Cache is an in-memory key value store whose entries expire after a ttl.
+type Cache[K comparable, V any] struct {
+	mu    sync.Mutex
+	items map[K]entry[V]
+}
+
+func New[K comparable, V any]() *Cache[K, V] {
+	return &Cache[K, V]{items: make(map[K]entry[V])}
+}
+
+// Get returns the value of an unexpired entry.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if !ok || !item.expiresAt.After(time.Now()) {
+		var zero V
+		return zero, false
+	}
+
+	return item.value, true
+}
+
+// Set stores the value for ttl.
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[key] = entry[V]{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+// SetIfAbsent stores the value for ttl unless an unexpired entry exists. It
+// reports whether the value was stored.
+func (c *Cache[K, V]) SetIfAbsent(key K, value V, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if item, ok := c.items[key]; ok && item.expiresAt.After(now) {
+		return false
+	}
+
+	c.items[key] = entry[V]{value: value, expiresAt: now.Add(ttl)}
+
+	return true
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, key)
+}
+
+// Clear removes every entry.
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.items)
+}
+
+// Purge removes the expired entries.
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, item := range c.items {
+		if !item.expiresAt.After(now) {
+			delete(c.items, key)
+		}
+	}
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
+
+// StartJanitor purges the expired entries every interval until the context is done.
+func (c *Cache[K, V]) StartJanitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Purge()
+			}
+		}
+	}()
+}
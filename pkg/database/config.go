@@ -1,6 +1,9 @@
 package database
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type Config struct {
 	Driver          string
@@ -15,6 +18,8 @@ type Config struct {
 	MaxOpenConn     *int
 	MaxConnLifetime *int
 	AppMode         string
+	// SlowThreshold is the duration above which a query is logged as slow.
+	SlowThreshold time.Duration
 }
 
 func (c *Config) NormalizeValue() {
@@ -1,12 +1,11 @@
 package database
 
 import (
+	"application/pkg/metrics"
+	"time"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
-	"log"
-	"os"
-	"time"
 )
 
 type Database struct {
@@ -37,25 +36,9 @@ func NewDatabase(config Config) (*Database, error) {
 
 // initial database
 func (d *Database) Init() error {
-	var level logger.LogLevel
-	if d.Config.AppMode == "PRODUCTION" {
-		level = logger.Error
-	} else {
-		level = logger.Info
-	}
-
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-		logger.Config{
-			SlowThreshold:        time.Second, // Slow SQL threshold
-			LogLevel:             level,       // Log level
-			ParameterizedQueries: true,        // Don't include params in the SQL log
-			Colorful:             true,        // Disable color
-		},
-	)
 	// create connection
 	connection, err := gorm.Open(postgres.Open(d.DSN), &gorm.Config{
-		Logger: newLogger,
+		Logger: newLogger(d.Config.SlowThreshold),
 	})
 	if err != nil {
 		return err
@@ -71,6 +54,10 @@ func (d *Database) Init() error {
 	db.SetMaxOpenConns(*d.Config.MaxOpenConn)
 	db.SetConnMaxLifetime(time.Duration(*d.Config.MaxConnLifetime) * time.Second)
 
+	if err := metrics.RegisterDatabase(db, d.Config.Database); err != nil {
+		return err
+	}
+
 	d.DB = connection
 
 	return nil
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"application/pkg/metrics"
+	"application/pkg/requestid"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DefaultSlowThreshold is the duration above which a query is logged as slow.
+const DefaultSlowThreshold = time.Second
+
+// zerologLogger writes the GORM logs through zerolog, so that LOG_LEVEL
+// applies: queries are logged at debug, slow queries at warn and failed
+// queries at error, with the request id of the context. The SQL is logged
+// without its parameters.
+type zerologLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newLogger(slowThreshold time.Duration) logger.Interface {
+	if slowThreshold <= 0 {
+		slowThreshold = DefaultSlowThreshold
+	}
+
+	return &zerologLogger{level: logger.Info, slowThreshold: slowThreshold}
+}
+
+func (l *zerologLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *zerologLogger) Info(ctx context.Context, msg string, args ...any) {
+	if l.level >= logger.Info {
+		requestid.Logger(ctx).Info().Msgf("[gorm] "+msg, args...)
+	}
+}
+
+func (l *zerologLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if l.level >= logger.Warn {
+		requestid.Logger(ctx).Warn().Msgf("[gorm] "+msg, args...)
+	}
+}
+
+func (l *zerologLogger) Error(ctx context.Context, msg string, args ...any) {
+	if l.level >= logger.Error {
+		requestid.Logger(ctx).Error().Msgf("[gorm] "+msg, args...)
+	}
+}
+
+func (l *zerologLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	slow := elapsed > l.slowThreshold
+	if slow {
+		metrics.SlowQueries.Inc()
+	}
+
+	result := "ok"
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		result = "error"
+	}
+	metrics.DbQueryDuration.WithLabelValues(result).Observe(elapsed.Seconds())
+
+	if l.level <= logger.Silent {
+		return
+	}
+
+	var event *zerolog.Event
+	log := requestid.Logger(ctx)
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		event = log.Error().Err(err)
+	case slow && l.level >= logger.Warn:
+		event = log.Warn().Dur("threshold", l.slowThreshold)
+	case l.level >= logger.Info:
+		event = log.Debug()
+	}
+
+	// the SQL is only built when the entry is written
+	if event == nil || !event.Enabled() {
+		return
+	}
+
+	sql, rows := fc()
+	event.Str("sql", sql).Int64("rows", rows).Dur("elapsed", elapsed).Msg("[gorm] query")
+}
+
+// ParamsFilter keeps the parameters out of the logged SQL.
+func (l *zerologLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	return sql, nil
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"application/pkg/metrics"
+	"application/pkg/requestid"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// captureQueryLogs collects the global log output at the level.
+func captureQueryLogs(t *testing.T, level zerolog.Level) *bytes.Buffer {
+	t.Helper()
+
+	var out bytes.Buffer
+	logger, globalLevel := log.Logger, zerolog.GlobalLevel()
+	log.Logger = zerolog.New(&out)
+	zerolog.SetGlobalLevel(level)
+	t.Cleanup(func() {
+		log.Logger = logger
+		zerolog.SetGlobalLevel(globalLevel)
+	})
+
+	return &out
+}
+
+func TestLoggerTrace(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     zerolog.Level
+		elapsed   time.Duration
+		err       error
+		wantLevel string
+	}{
+		{name: "query at debug", level: zerolog.DebugLevel, wantLevel: "debug"},
+		{name: "query at info", level: zerolog.InfoLevel},
+		{name: "slow query", level: zerolog.InfoLevel, elapsed: 2 * time.Second, wantLevel: "warn"},
+		{name: "failed query", level: zerolog.InfoLevel, err: errors.New("syntax error"), wantLevel: "error"},
+		{name: "record not found", level: zerolog.InfoLevel, err: gorm.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureQueryLogs(t, tt.level)
+			ctx := requestid.NewContext(context.Background(), "request-1")
+
+			built := false
+			newLogger(time.Second).Trace(ctx, time.Now().Add(-tt.elapsed), func() (string, int64) {
+				built = true
+				return "SELECT * FROM orders WHERE id = $1", 1
+			}, tt.err)
+
+			if tt.wantLevel == "" {
+				if out.Len() != 0 || built {
+					t.Errorf("logs = %q, sql built %v, want nothing", out.String(), built)
+				}
+				return
+			}
+
+			var entry map[string]any
+			if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+				t.Fatalf("log %q is not JSON: %v", out.String(), err)
+			}
+			if entry["level"] != tt.wantLevel || entry["sql"] != "SELECT * FROM orders WHERE id = $1" || entry["request_id"] != "request-1" {
+				t.Errorf("entry = %v, want the query at %s", entry, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestLoggerCountsSlowQueries(t *testing.T) {
+	captureQueryLogs(t, zerolog.Disabled)
+	before := testutil.ToFloat64(metrics.SlowQueries)
+
+	l := newLogger(10 * time.Millisecond)
+	l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+	if got := testutil.ToFloat64(metrics.SlowQueries) - before; got != 1 {
+		t.Errorf("slow queries = %v, want 1", got)
+	}
+}
+
+func TestLoggerObservesQueryDuration(t *testing.T) {
+	captureQueryLogs(t, zerolog.Disabled)
+
+	tests := []struct {
+		name   string
+		err    error
+		result string
+	}{
+		{name: "query", result: "ok"},
+		{name: "record not found", err: gorm.ErrRecordNotFound, result: "ok"},
+		{name: "failed query", err: errors.New("syntax error"), result: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := queryCount(t, tt.result)
+
+			// the silent logger still observes the queries
+			newLogger(time.Second).LogMode(logger.Silent).Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, tt.err)
+
+			if got := queryCount(t, tt.result) - count; got != 1 {
+				t.Errorf("queries{result=%s} = %d, want 1", tt.result, got)
+			}
+		})
+	}
+}
+
+// queryCount returns how many queries were observed with the result.
+func queryCount(t *testing.T, result string) uint64 {
+	t.Helper()
+
+	var metric dto.Metric
+	if err := metrics.DbQueryDuration.WithLabelValues(result).(prometheus.Metric).Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestLoggerLogMode(t *testing.T) {
+	out := captureQueryLogs(t, zerolog.DebugLevel)
+
+	base := newLogger(0)
+	silent := base.LogMode(logger.Silent)
+
+	silent.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("failed"))
+	silent.Error(context.Background(), "failed %s", "query")
+	if out.Len() != 0 {
+		t.Errorf("silent logs = %q, want nothing", out.String())
+	}
+
+	base.Error(context.Background(), "failed %s", "query")
+	if !strings.Contains(out.String(), "[gorm] failed query") {
+		t.Errorf("logs = %q, want the error of the base logger", out.String())
+	}
+
+	if threshold := base.(*zerologLogger).slowThreshold; threshold != DefaultSlowThreshold {
+		t.Errorf("slow threshold = %v, want %v", threshold, DefaultSlowThreshold)
+	}
+}
+
+func TestLoggerHidesParams(t *testing.T) {
+	filter, ok := newLogger(0).(interface {
+		ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any)
+	})
+	if !ok {
+		t.Fatal("logger does not filter params")
+	}
+
+	sql, params := filter.ParamsFilter(context.Background(), "SELECT * FROM admin_users WHERE password = $1", "s3cr3t")
+	if sql != "SELECT * FROM admin_users WHERE password = $1" || params != nil {
+		t.Errorf("ParamsFilter() = %q, %v, want the SQL without params", sql, params)
+	}
+}
@@ -0,0 +1,87 @@
+package errorsink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	DriverNone = "none"
+	DriverFile = "file"
+)
+
+// Event is a crash reported to the error tracker.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+	Stack     string    `json:"stack"`
+	RequestId string    `json:"requestId,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Route     string    `json:"route,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	User      string    `json:"user,omitempty"`
+	Node      string    `json:"node,omitempty"`
+}
+
+// Sink reports crashes. Implementations for an error tracking service plug in
+// here; the file sink is a local stand-in.
+type Sink interface {
+	Report(ctx context.Context, event *Event) error
+}
+
+type Config struct {
+	Driver string
+	// File receives the events of the file sink, one JSON object per line.
+	File string
+}
+
+// New returns nil when no driver is configured.
+func New(config Config) (Sink, error) {
+	switch config.Driver {
+	case "", DriverNone:
+		return nil, nil
+	case DriverFile:
+		if config.File == "" {
+			return nil, fmt.Errorf("error sink file is required for the %s driver", DriverFile)
+		}
+		if err := os.MkdirAll(filepath.Dir(config.File), 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create error sink directory: %w", err)
+		}
+		return &FileSink{path: config.File}, nil
+	default:
+		return nil, fmt.Errorf("unknown error sink driver %q", config.Driver)
+	}
+}
+
+// FileSink appends every event to a JSON lines file.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (s *FileSink) Report(ctx context.Context, event *Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode error event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open error sink file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write error event: %w", err)
+	}
+
+	return nil
+}
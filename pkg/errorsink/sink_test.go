@@ -0,0 +1,87 @@
+package errorsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "errors", "events.jsonl")
+
+	tests := []struct {
+		name     string
+		config   Config
+		wantSink bool
+		wantErr  bool
+	}{
+		{name: "no driver", config: Config{}},
+		{name: "none", config: Config{Driver: DriverNone}},
+		{name: "file", config: Config{Driver: DriverFile, File: file}, wantSink: true},
+		{name: "file without path", config: Config{Driver: DriverFile}, wantErr: true},
+		{name: "unknown driver", config: Config{Driver: "sentry"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := New(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (sink != nil) != tt.wantSink {
+				t.Errorf("New() sink = %v, want a sink %v", sink, tt.wantSink)
+			}
+		})
+	}
+}
+
+func TestFileSinkAppendsEvents(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "events.jsonl")
+
+	sink, err := New(Config{Driver: DriverFile, File: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, message := range []string{"first", "second"} {
+		event := &Event{Time: time.Now(), Message: message, Stack: "goroutine 1", RequestId: "request-1", Route: "/orders/:id"}
+		if err := sink.Report(context.Background(), event); err != nil {
+			t.Fatalf("Report() error = %v", err)
+		}
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var messages []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %q is not an event: %v", scanner.Text(), err)
+		}
+		if event.RequestId != "request-1" || event.Route != "/orders/:id" {
+			t.Errorf("event = %+v", event)
+		}
+		messages = append(messages, event.Message)
+	}
+
+	if len(messages) != 2 || messages[0] != "first" || messages[1] != "second" {
+		t.Errorf("messages = %v, want [first second]", messages)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
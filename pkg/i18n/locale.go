@@ -0,0 +1,92 @@
+// Package i18n resolves the language of a request for the messages sent to clients.
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	English    = "en"
+	Indonesian = "id"
+
+	// Default is used when the client accepts no supported language.
+	Default = English
+
+	// LocaleKey holds the stored preference of the authenticated admin, set on
+	// the gin context by the authentication middleware. It wins over the request.
+	LocaleKey = "Locale"
+	// LocaleQuery lets a client pass its preference explicitly, e.g. ?lang=id.
+	LocaleQuery = "lang"
+)
+
+// Locales are the supported languages.
+func Locales() []string {
+	return []string{English, Indonesian}
+}
+
+// Supported tells whether the locale has messages.
+func Supported(locale string) bool {
+	return slices.Contains(Locales(), locale)
+}
+
+// Parse picks the supported language preferred by an Accept-Language header.
+// The language with the highest quality value wins, the first one on a tie.
+// Languages with q=0 are refused.
+func Parse(acceptLanguage string) string {
+	locale, best := Default, 0.0
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if !Supported(language) {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if quality, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		if quality > best {
+			locale, best = language, quality
+		}
+	}
+
+	return locale
+}
+
+// FromContext returns the locale of the request: the preference set on the
+// context, then the lang query parameter, then the Accept-Language header.
+func FromContext(ctx *gin.Context) string {
+	if locale := ctx.GetString(LocaleKey); Supported(locale) {
+		return locale
+	}
+
+	if locale := strings.ToLower(ctx.Query(LocaleQuery)); Supported(locale) {
+		return locale
+	}
+
+	return Parse(ctx.GetHeader("Accept-Language"))
+}
+
+// Render replaces the {name} placeholders of a template with the params.
+func Render(template string, params map[string]any) string {
+	if len(params) == 0 {
+		return template
+	}
+
+	replacements := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+
+	return strings.NewReplacer(replacements...).Replace(template)
+}
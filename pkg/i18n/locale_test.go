@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{acceptLanguage: "", want: Default},
+		{acceptLanguage: "id", want: Indonesian},
+		{acceptLanguage: "id-ID,id;q=0.9", want: Indonesian},
+		{acceptLanguage: "EN-us", want: English},
+		{acceptLanguage: "fr-FR, id;q=0.8", want: Indonesian},
+		{acceptLanguage: "fr, de", want: Default},
+		{acceptLanguage: "en;q=0.5, id;q=0.8", want: Indonesian},
+		{acceptLanguage: "id;q=0.8, en", want: English},
+		{acceptLanguage: "en;q=0.7, id;q=0.7", want: English},
+		{acceptLanguage: "id;q=0.7, en;q=0.7", want: Indonesian},
+		{acceptLanguage: "id;q=0", want: Default},
+		{acceptLanguage: "id;q=0, en;q=0.1", want: English},
+		{acceptLanguage: "id;q=high, en;q=0.1", want: English},
+		{acceptLanguage: " id ; q=0.9 ", want: Indonesian},
+	}
+
+	for _, tt := range tests {
+		if got := Parse(tt.acceptLanguage); got != tt.want {
+			t.Errorf("Parse(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		preference     string
+		query          string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "nothing", want: Default},
+		{name: "header", acceptLanguage: "id", want: Indonesian},
+		{name: "query over header", query: "?lang=EN", acceptLanguage: "id", want: English},
+		{name: "unsupported query", query: "?lang=fr", acceptLanguage: "id", want: Indonesian},
+		{name: "preference over query", preference: Indonesian, query: "?lang=en", acceptLanguage: "en", want: Indonesian},
+		{name: "unsupported preference", preference: "fr", acceptLanguage: "id", want: Indonesian},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			if tt.acceptLanguage != "" {
+				ctx.Request.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			if tt.preference != "" {
+				ctx.Set(LocaleKey, tt.preference)
+			}
+
+			if got := FromContext(ctx); got != tt.want {
+				t.Errorf("FromContext() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		template string
+		params   map[string]any
+		want     string
+	}{
+		{template: "try again in {retryAfter} seconds", params: map[string]any{"retryAfter": 30}, want: "try again in 30 seconds"},
+		{template: "{a} and {b}", params: map[string]any{"a": "x", "b": "y"}, want: "x and y"},
+		{template: "{missing} stays", params: map[string]any{"other": 1}, want: "{missing} stays"},
+		{template: "no params", want: "no params"},
+	}
+
+	for _, tt := range tests {
+		if got := Render(tt.template, tt.params); got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package pkgjwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+var (
+	ErrTokenExpired           = errors.New("token is expired")
+	ErrTokenMissingExpiration = errors.New("token has no expiration")
+	ErrTokenNotValidYet       = errors.New("token is not valid yet")
+	ErrTokenUsedBeforeIssued  = errors.New("token used before issued")
+	ErrTokenInvalidIssuer     = errors.New("token has invalid issuer")
+	ErrTokenInvalidAudience   = errors.New("token has invalid audience")
+	ErrTokenInvalidUse        = errors.New("token has invalid use")
+)
+
+const (
+	// TokenUseMfaChallenge marks the token proving the password step of a two-step login.
+	TokenUseMfaChallenge = "mfa_challenge"
+	// TokenUseClientCredentials marks the access tokens of OAuth2 machine clients.
+	TokenUseClientCredentials = "client_credentials"
+	// TokenUseOidcFlow marks the token carrying the state of an OpenID Connect login.
+	TokenUseOidcFlow = "oidc_flow"
+)
+
+// Audience is the aud claim, encoded as a string when it holds a single value.
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("invalid aud claim: %w", err)
+	}
+
+	*a = multiple
+	return nil
+}
+
+// RegisteredClaims are the claims registered by RFC 7519.
+type RegisteredClaims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  float64  `json:"iat,omitempty"`
+	Id        string   `json:"jti,omitempty"`
+}
+
+// IssuedAtTime returns the iat claim. Tokens are issued with a fraction of a
+// second, so that a revocation tells apart the tokens issued right after it.
+func (c *RegisteredClaims) IssuedAtTime() time.Time {
+	return time.UnixMicro(int64(math.Round(c.IssuedAt * 1e6)))
+}
+
+// Validate checks the time based claims allowing leeway for clock skew, and
+// the issuer and audience when they are expected.
+func (c *RegisteredClaims) Validate(now time.Time, issuer string, audience []string, leeway time.Duration) error {
+	if c.ExpiresAt == 0 {
+		return ErrTokenMissingExpiration
+	}
+
+	if now.Add(-leeway).After(time.Unix(c.ExpiresAt, 0)) {
+		return ErrTokenExpired
+	}
+
+	if c.NotBefore != 0 && now.Add(leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return ErrTokenNotValidYet
+	}
+
+	if c.IssuedAt != 0 && now.Add(leeway).Before(c.IssuedAtTime()) {
+		return ErrTokenUsedBeforeIssued
+	}
+
+	if issuer != "" && c.Issuer != issuer {
+		return ErrTokenInvalidIssuer
+	}
+
+	if len(audience) > 0 && !slices.ContainsFunc(c.Audience, func(aud string) bool {
+		return slices.Contains(audience, aud)
+	}) {
+		return ErrTokenInvalidAudience
+	}
+
+	return nil
+}
+
+// Claims combines the registered claims with application specific claims of
+// type T, encoded side by side in the same JSON object.
+type Claims[T any] struct {
+	RegisteredClaims
+	Custom T
+}
+
+// Valid satisfies jwt.Claims. Claims are validated by the adapter instead, so
+// that leeway, issuer and audience are taken into account.
+func (c *Claims[T]) Valid() error {
+	return nil
+}
+
+func (c Claims[T]) MarshalJSON() ([]byte, error) {
+	merged := make(map[string]json.RawMessage)
+
+	custom, err := json.Marshal(c.Custom)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(custom, &merged); err != nil {
+		return nil, fmt.Errorf("custom claims must encode to a JSON object: %w", err)
+	}
+
+	registered, err := json.Marshal(c.RegisteredClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(registered, &merged); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(merged)
+}
+
+func (c *Claims[T]) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.RegisteredClaims); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &c.Custom)
+}
+
+// AccessClaims are the application claims of an access token.
+type AccessClaims struct {
+	Id          int64    `json:"id"`
+	Role        string   `json:"role,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	TenantId    string   `json:"tid,omitempty"`
+	SessionId   string   `json:"sid,omitempty"`
+	Locale      string   `json:"locale,omitempty"`
+	// Use is empty for access tokens. Other tokens signed with the same keys
+	// set it so that they are never accepted as access tokens.
+	Use string `json:"use,omitempty"`
+}
+
+// MfaChallengeClaims are the application claims of an MFA challenge token.
+type MfaChallengeClaims struct {
+	UserId int64  `json:"uid"`
+	Use    string `json:"use"`
+}
+
+// ClientClaims are the application claims of an OAuth2 client credentials
+// token. Scope holds the granted scopes separated by spaces as in RFC 8693.
+type ClientClaims struct {
+	ClientId string `json:"client_id"`
+	Scope    string `json:"scope,omitempty"`
+	Use      string `json:"use"`
+}
+
+// OidcFlowClaims are the application claims of the token handed to the
+// browser between the redirect to the identity provider and the callback.
+// The PKCE code verifier is encrypted, the browser never learns it.
+type OidcFlowClaims struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"cv"`
+	Use          string `json:"use"`
+}
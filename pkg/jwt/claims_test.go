@@ -0,0 +1,128 @@
+package pkgjwt
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRegisteredClaimsValidate(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	leeway := 30 * time.Second
+
+	valid := func() RegisteredClaims {
+		return RegisteredClaims{
+			Issuer:    testIssuer,
+			Audience:  Audience{"api"},
+			ExpiresAt: now.Add(time.Minute).Unix(),
+			NotBefore: now.Unix(),
+			IssuedAt:  float64(now.Unix()),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		change   func(c *RegisteredClaims)
+		audience []string
+		want     error
+	}{
+		{"valid", func(c *RegisteredClaims) {}, []string{"api"}, nil},
+		{"missing exp", func(c *RegisteredClaims) { c.ExpiresAt = 0 }, nil, ErrTokenMissingExpiration},
+		{"expired", func(c *RegisteredClaims) { c.ExpiresAt = now.Add(-time.Minute).Unix() }, nil, ErrTokenExpired},
+		{"expired within leeway", func(c *RegisteredClaims) { c.ExpiresAt = now.Add(-10 * time.Second).Unix() }, nil, nil},
+		{"not valid yet", func(c *RegisteredClaims) { c.NotBefore = now.Add(time.Minute).Unix() }, nil, ErrTokenNotValidYet},
+		{"nbf within leeway", func(c *RegisteredClaims) { c.NotBefore = now.Add(10 * time.Second).Unix() }, nil, nil},
+		{"issued in the future", func(c *RegisteredClaims) { c.IssuedAt = float64(now.Add(time.Minute).Unix()) }, nil, ErrTokenUsedBeforeIssued},
+		{"other issuer", func(c *RegisteredClaims) { c.Issuer = "other" }, nil, ErrTokenInvalidIssuer},
+		{"other audience", func(c *RegisteredClaims) {}, []string{"admin"}, ErrTokenInvalidAudience},
+		{"one of several audiences", func(c *RegisteredClaims) { c.Audience = Audience{"admin", "api"} }, []string{"api"}, nil},
+		{"no audience expected", func(c *RegisteredClaims) { c.Audience = nil }, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.change(&claims)
+
+			if err := claims.Validate(now, testIssuer, tt.audience, leeway); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAudienceJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		audience Audience
+		encoded  string
+	}{
+		{"single", Audience{"api"}, `"api"`},
+		{"several", Audience{"api", "admin"}, `["api","admin"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.audience)
+			if err != nil || string(data) != tt.encoded {
+				t.Fatalf("Marshal() = %s, %v, want %s", data, err, tt.encoded)
+			}
+
+			var decoded Audience
+			if err := json.Unmarshal(data, &decoded); err != nil || !slices.Equal(decoded, tt.audience) {
+				t.Errorf("Unmarshal() = %v, %v, want %v", decoded, err, tt.audience)
+			}
+		})
+	}
+
+	var invalid Audience
+	if err := json.Unmarshal([]byte(`1`), &invalid); err == nil {
+		t.Error("Unmarshal(1) error = nil, want an invalid aud error")
+	}
+}
+
+func TestIssueAndVerifyJwt(t *testing.T) {
+	adapter := NewJwtAdapter(testIssuer, "secret")
+	adapter.Audience = Audience{"api"}
+
+	before := time.Now()
+	session, err := adapter.IssueJwt(&IssueJwtPayload{
+		Id:          7,
+		Subject:     "admin",
+		Roles:       []string{"ADMIN", "AUDITOR"},
+		Permissions: []string{"users:read"},
+		TenantId:    "tenant",
+		SessionId:   "session",
+		Locale:      "id",
+		Lifetime:    time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("IssueJwt() error = %v", err)
+	}
+
+	claims, err := adapter.VerifyJwt(session.Token)
+	if err != nil {
+		t.Fatalf("VerifyJwt() error = %v", err)
+	}
+
+	if claims.Id != 7 || claims.Sub != "admin" || claims.Role != "ADMIN" || claims.TenantId != "tenant" ||
+		claims.Sid != "session" || claims.Locale != "id" || claims.Jti == "" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if !slices.Equal(claims.Roles, []string{"ADMIN", "AUDITOR"}) || !slices.Equal(claims.Permissions, []string{"users:read"}) {
+		t.Errorf("roles = %v, permissions = %v", claims.Roles, claims.Permissions)
+	}
+
+	// iat keeps the fraction of a second for the revocation of a user
+	if claims.IssuedAt.Before(before.Truncate(time.Microsecond)) || claims.Iat != claims.IssuedAt.Unix() {
+		t.Errorf("issued at = %v (iat %d), want after %v", claims.IssuedAt, claims.Iat, before)
+	}
+
+	other := NewJwtAdapter(testIssuer, "secret")
+	other.Audience = Audience{"admin"}
+	if _, err := other.VerifyJwt(session.Token); !errors.Is(err, ErrTokenInvalidAudience) {
+		t.Errorf("VerifyJwt() for another audience error = %v, want %v", err, ErrTokenInvalidAudience)
+	}
+}
@@ -0,0 +1,83 @@
+package pkgjwt
+
+import (
+	"application/app/web"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// IssueClientJwtPayload represents the payload used to create the access token of a machine client.
+type IssueClientJwtPayload struct {
+	ClientId string
+	Scopes   []string
+	Lifetime time.Duration
+}
+
+// ClientJwtResponse represents the claims of a machine client access token.
+type ClientJwtResponse struct {
+	ClientId string   `json:"client_id"`
+	Scopes   []string `json:"scopes"`
+	Sub      string   `json:"sub"`
+	Iss      string   `json:"iss"`
+	Aud      []string `json:"aud"`
+	Jti      string   `json:"jti"`
+	Iat      int64    `json:"iat"`
+	Nbf      int64    `json:"nbf"`
+	Exp      int64    `json:"exp"`
+
+	IssuedAt time.Time `json:"-"`
+}
+
+// IssueClientJwt issues an access token to an OAuth2 client. The client is the
+// subject and the token is never accepted by VerifyJwt.
+func (j *JwtAdapter) IssueClientJwt(payload *IssueClientJwtPayload) (*web.Session, error) {
+	return IssueClaims(j, &Claims[ClientClaims]{
+		RegisteredClaims: RegisteredClaims{Subject: payload.ClientId},
+		Custom: ClientClaims{
+			ClientId: payload.ClientId,
+			Scope:    strings.Join(payload.Scopes, " "),
+			Use:      TokenUseClientCredentials,
+		},
+	}, payload.Lifetime)
+}
+
+// VerifyClientJwt verifies the token and returns its client claims.
+func (j *JwtAdapter) VerifyClientJwt(token string) (*ClientJwtResponse, error) {
+	claims, err := VerifyClaims[ClientClaims](j, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Custom.Use != TokenUseClientCredentials || claims.Custom.ClientId == "" {
+		return nil, Error(ErrTokenInvalidUse)
+	}
+
+	return &ClientJwtResponse{
+		ClientId: claims.Custom.ClientId,
+		Scopes:   strings.Fields(claims.Custom.Scope),
+		Sub:      claims.Subject,
+		Iss:      claims.Issuer,
+		Aud:      claims.Audience,
+		Jti:      claims.Id,
+		Iat:      int64(claims.IssuedAt),
+		Nbf:      claims.NotBefore,
+		Exp:      claims.ExpiresAt,
+		IssuedAt: claims.IssuedAtTime(),
+	}, nil
+}
+
+// TokenUse reads the use claim of a token without verifying it, only to pick
+// how the token must be verified. It is empty for access tokens.
+func TokenUse(token string) string {
+	claims := new(Claims[struct {
+		Use string `json:"use"`
+	}])
+
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, claims); err != nil {
+		return ""
+	}
+
+	return claims.Custom.Use
+}
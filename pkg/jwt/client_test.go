@@ -0,0 +1,44 @@
+package pkgjwt
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestVerifyRejectsOtherTokenUses(t *testing.T) {
+	adapter := NewJwtAdapter(testIssuer, "secret")
+
+	client, err := adapter.IssueClientJwt(&IssueClientJwtPayload{ClientId: "client", Scopes: []string{"read"}, Lifetime: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	access, err := adapter.IssueJwt(&IssueJwtPayload{Id: 1, Subject: "admin", Lifetime: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := adapter.VerifyJwt(client.Token); !errors.Is(err, ErrTokenInvalidUse) {
+		t.Errorf("VerifyJwt(client token) error = %v, want %v", err, ErrTokenInvalidUse)
+	}
+	if _, err := adapter.VerifyClientJwt(access.Token); !errors.Is(err, ErrTokenInvalidUse) {
+		t.Errorf("VerifyClientJwt(access token) error = %v, want %v", err, ErrTokenInvalidUse)
+	}
+
+	claims, err := adapter.VerifyClientJwt(client.Token)
+	if err != nil {
+		t.Fatalf("VerifyClientJwt() error = %v", err)
+	}
+	if claims.ClientId != "client" || claims.Sub != "client" || !slices.Equal(claims.Scopes, []string{"read"}) {
+		t.Errorf("client claims = %+v", claims)
+	}
+
+	if use := TokenUse(client.Token); use != TokenUseClientCredentials {
+		t.Errorf("TokenUse() = %q, want %q", use, TokenUseClientCredentials)
+	}
+	if use := TokenUse(access.Token); use != "" {
+		t.Errorf("TokenUse() = %q, want an access token", use)
+	}
+}
@@ -1,7 +1,20 @@
 package pkgjwt
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
 
+	"github.com/golang-jwt/jwt"
+)
+
+// Error wraps a verification failure. The jwt library does not unwrap the
+// errors of the key function, so the inner error is unwrapped here to keep
+// errors.Is working for errors such as ErrUnknownKeyId.
 func Error(err error) error {
+	var validation *jwt.ValidationError
+	if errors.As(err, &validation) && validation.Inner != nil {
+		err = validation.Inner
+	}
+
 	return fmt.Errorf("failed to verify JWT token: %w", err)
 }
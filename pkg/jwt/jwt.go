@@ -2,94 +2,192 @@ package pkgjwt
 
 import (
 	"application/app/web"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
+const TokenTypeBearer = "Bearer"
+
 // JwtAdapter handles JWT creation and management.
 type JwtAdapter struct {
-	Algorithm *jwt.SigningMethodHMAC
-	Issuer    string
-	Secret    string
+	Issuer   string
+	Audience []string
+	// Leeway tolerates clock skew between the issuer and the verifier.
+	Leeway time.Duration
+	Keys   *KeySet
 }
 
 // IssueJwtPayload represents the payload used to create a JWT.
 type IssueJwtPayload struct {
-	Id      int64
-	Subject string
-	// Role     enums.CodeAdminRole
-	Lifetime int64
+	// TokenId is the jti of the token, a random one when empty.
+	TokenId     string
+	Id          int64
+	Subject     string
+	SessionId   string
+	Roles       []string
+	Permissions []string
+	TenantId    string
+	Locale      string
+	Lifetime    time.Duration
 }
 
 // JwtResponse represents the JWT response format.
 type JwtResponse struct {
-	Id   int64  `json:"id"`
-	Sub  string `json:"sub"`
-	Role string `json:"role"`
-	Exp  int64  `json:"exp"`
+	Id          int64    `json:"id"`
+	Sub         string   `json:"sub"`
+	Role        string   `json:"role"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	TenantId    string   `json:"tid"`
+	Sid         string   `json:"sid"`
+	Locale      string   `json:"locale"`
+	Jti         string   `json:"jti"`
+	Iat         int64    `json:"iat"`
+	Exp         int64    `json:"exp"`
+
+	IssuedAt time.Time `json:"-"`
 }
 
-// NewJwtAdapter creates a new instance of JwtAdapter.
+// NewJwtAdapter creates a new instance of JwtAdapter signing with a HS512 shared secret.
 func NewJwtAdapter(issuer, secret string) *JwtAdapter {
+	keys := NewKeySet()
+	keys.SetSigningKey(&Key{Id: DefaultHmacKeyId, Algorithm: jwt.SigningMethodHS512, Secret: []byte(secret)})
+
+	return NewJwtAdapterWithKeySet(issuer, keys)
+}
+
+// NewJwtAdapterWithKeySet creates a new instance of JwtAdapter backed by a key set.
+func NewJwtAdapterWithKeySet(issuer string, keys *KeySet) *JwtAdapter {
 	return &JwtAdapter{
-		Algorithm: jwt.SigningMethodHS512,
-		Issuer:    issuer,
-		Secret:    secret,
+		Issuer: issuer,
+		Keys:   keys,
 	}
 }
 
 // IssueJwt issues a new JWT based on the provided payload.
 func (j *JwtAdapter) IssueJwt(payload *IssueJwtPayload) (*web.Session, error) {
-	exp := time.Now().Add(time.Hour * time.Duration(payload.Lifetime)).Unix()
+	claims := &Claims[AccessClaims]{
+		RegisteredClaims: RegisteredClaims{Subject: payload.Subject, Id: payload.TokenId},
+		Custom: AccessClaims{
+			Id:          payload.Id,
+			Roles:       payload.Roles,
+			Permissions: payload.Permissions,
+			TenantId:    payload.TenantId,
+			SessionId:   payload.SessionId,
+			Locale:      payload.Locale,
+		},
+	}
 
-	token := jwt.New(j.Algorithm)
-	claims := token.Claims.(jwt.MapClaims)
-	claims["id"] = payload.Id
-	claims["exp"] = exp
-	claims["sub"] = payload.Subject
-	claims["iss"] = j.Issuer // Adding issuer to claims for more context
+	if len(payload.Roles) > 0 {
+		claims.Custom.Role = payload.Roles[0]
+	}
+
+	return IssueClaims(j, claims, payload.Lifetime)
+}
 
-	tokenString, err := token.SignedString([]byte(j.Secret))
+// VerifyJwt verifies the token and returns its access token claims.
+func (j *JwtAdapter) VerifyJwt(token string) (*JwtResponse, error) {
+	claims, err := VerifyClaims[AccessClaims](j, token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to issue JWT token: %w", err)
+		return nil, err
 	}
 
-	return &web.Session{Token: tokenString, ExpiredAt: exp}, nil
+	if claims.Custom.Use != "" {
+		return nil, Error(ErrTokenInvalidUse)
+	}
+
+	return &JwtResponse{
+		Id:          claims.Custom.Id,
+		Sub:         claims.Subject,
+		Role:        claims.Custom.Role,
+		Roles:       claims.Custom.Roles,
+		Permissions: claims.Custom.Permissions,
+		TenantId:    claims.Custom.TenantId,
+		Sid:         claims.Custom.SessionId,
+		Locale:      claims.Custom.Locale,
+		Jti:         claims.Id,
+		Iat:         int64(claims.IssuedAt),
+		Exp:         claims.ExpiresAt,
+		IssuedAt:    claims.IssuedAtTime(),
+	}, nil
 }
 
-func (j *JwtAdapter) VerifyJwt(token string) (*JwtResponse, error) {
+// IssueClaims signs the claims with the signing key of the adapter. The issuer,
+// audience, jti and time based claims are filled in when left empty.
+func IssueClaims[T any](j *JwtAdapter, claims *Claims[T], lifetime time.Duration) (*web.Session, error) {
+	key, err := j.Keys.SigningKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue JWT token: %w", err)
+	}
+
+	signKey, err := key.SignKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue JWT token: %w", err)
+	}
 
+	now := time.Now()
+
+	if claims.Issuer == "" {
+		claims.Issuer = j.Issuer
+	}
+	if len(claims.Audience) == 0 {
+		claims.Audience = j.Audience
+	}
+	if claims.Id == "" {
+		claims.Id = uuid.New().String()
+	}
+	claims.IssuedAt = float64(now.UnixMicro()) / 1e6
+	claims.NotBefore = now.Unix()
+	claims.ExpiresAt = now.Add(lifetime).Unix()
+
+	token := jwt.NewWithClaims(key.Algorithm, claims)
+	token.Header["kid"] = key.Id
+
+	tokenString, err := token.SignedString(signKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue JWT token: %w", err)
+	}
+
+	return &web.Session{TokenType: TokenTypeBearer, Token: tokenString, ExpiredAt: claims.ExpiresAt}, nil
+}
+
+// VerifyClaims verifies the token against the key matching its kid header,
+// validates the registered claims and decodes the custom claims into T.
+func VerifyClaims[T any](j *JwtAdapter, token string) (*Claims[T], error) {
 	key := func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+
+		key, ok := j.Keys.Lookup(kid)
+		if !ok {
+			log.Error().Str("kid", kid).Msg("unknown key id")
+			return nil, fmt.Errorf("%w: %q", ErrUnknownKeyId, kid)
+		}
+
+		if token.Method.Alg() != key.Algorithm.Alg() {
 			log.Error().Err(errors.New("unexpected signing method"))
 			return nil, errors.New(fmt.Errorf("unexpected signing method: %v", token.Header["alg"]).Error())
 		}
 
-		return []byte(j.Secret), nil
+		return key.VerifyKey(), nil
 	}
 
-	mapClaims := jwt.MapClaims{}
-	if _, err := jwt.ParseWithClaims(token, &mapClaims, key); err != nil {
+	parser := &jwt.Parser{ValidMethods: j.Keys.Algorithms(), SkipClaimsValidation: true}
+
+	claims := new(Claims[T])
+	if _, err := parser.ParseWithClaims(token, claims, key); err != nil {
 		log.Error().Err(err).Msg("failed parse with claims to verify JWT token")
 		return nil, Error(err)
 	}
 
-	jsonData, err := json.Marshal(mapClaims)
-	if err != nil {
-		log.Error().Err(err).Msg("failed json marshal to verify JWT token")
+	if err := claims.Validate(time.Now(), j.Issuer, j.Audience, j.Leeway); err != nil {
+		log.Error().Err(err).Msg("failed validate claims to verify JWT token")
 		return nil, Error(err)
 	}
 
-	var claims JwtResponse
-	if errMarshal := json.Unmarshal(jsonData, &claims); errMarshal != nil {
-		log.Error().Err(errMarshal).Msg("failed json unmarshal to verify JWT token")
-		return nil, Error(errMarshal)
-	}
-
-	return &claims, nil
+	return claims, nil
 }
@@ -0,0 +1,380 @@
+package pkgjwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	// DefaultAlgorithm is used when no algorithm is configured.
+	DefaultAlgorithm = "HS512"
+	// DefaultHmacKeyId is the key id given to a shared secret when none is configured.
+	DefaultHmacKeyId = "default"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no signing key configured")
+	ErrUnknownKeyId = errors.New("unknown key id")
+)
+
+// Key is a single signing or verification key identified by its key id.
+type Key struct {
+	Id         string
+	Algorithm  jwt.SigningMethod
+	Secret     []byte
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// KeyConfig describes where the signing and verification keys are loaded from.
+type KeyConfig struct {
+	Algorithm      string
+	KeyId          string
+	Secret         string
+	PrivateKeyFile string
+	PublicKeyFiles []string
+}
+
+// KeySet holds the key used for signing and every key accepted for verification.
+type KeySet struct {
+	mu      sync.RWMutex
+	signing *Key
+	keys    map[string]*Key
+}
+
+// NewKeySet creates an empty key set.
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]*Key)}
+}
+
+// IsHmac reports whether the key is a shared secret.
+func (k *Key) IsHmac() bool {
+	_, ok := k.Algorithm.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+// SignKey returns the value expected by the signing method to sign a token.
+func (k *Key) SignKey() (any, error) {
+	if k.IsHmac() {
+		return k.Secret, nil
+	}
+
+	if k.PrivateKey == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	return k.PrivateKey, nil
+}
+
+// VerifyKey returns the value expected by the signing method to verify a token.
+func (k *Key) VerifyKey() any {
+	if k.IsHmac() {
+		return k.Secret
+	}
+
+	return k.PublicKey
+}
+
+// Add registers a verification key.
+func (s *KeySet) Add(key *Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.Id] = key
+}
+
+// SetSigningKey registers the key and uses it to sign new tokens.
+func (s *KeySet) SetSigningKey(key *Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.Id] = key
+	s.signing = key
+}
+
+// SigningKey returns the key used to sign new tokens.
+func (s *KeySet) SigningKey() (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.signing == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	return s.signing, nil
+}
+
+// Replace swaps the signing key and every verification key at once.
+func (s *KeySet) Replace(signing *Key, keys []*Key) {
+	replaced := make(map[string]*Key, len(keys)+1)
+	for _, key := range keys {
+		replaced[key.Id] = key
+	}
+
+	if signing != nil {
+		replaced[signing.Id] = signing
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.signing = signing
+	s.keys = replaced
+}
+
+// Keys returns a snapshot of every key in the set.
+func (s *KeySet) Keys() []*Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Lookup returns the verification key for a key id. Tokens issued without
+// a key id are verified with the signing key.
+func (s *KeySet) Lookup(kid string) (*Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if kid == "" {
+		return s.signing, s.signing != nil
+	}
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// Algorithms returns the algorithm names of every key in the set.
+func (s *KeySet) Algorithms() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var algorithms []string
+	seen := make(map[string]bool)
+	for _, key := range s.keys {
+		alg := key.Algorithm.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			algorithms = append(algorithms, alg)
+		}
+	}
+
+	return algorithms
+}
+
+// LoadKeySet builds a key set from a shared secret or from PEM encoded key files.
+func LoadKeySet(cfg KeyConfig) (*KeySet, error) {
+	algorithm := cfg.Algorithm
+	if algorithm == "" {
+		algorithm = DefaultAlgorithm
+	}
+
+	method := jwt.GetSigningMethod(algorithm)
+	if method == nil || method == jwt.SigningMethodNone {
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+
+	set := NewKeySet()
+
+	if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("signing algorithm %s requires a secret", algorithm)
+		}
+
+		kid := cfg.KeyId
+		if kid == "" {
+			kid = DefaultHmacKeyId
+		}
+
+		set.SetSigningKey(&Key{Id: kid, Algorithm: method, Secret: []byte(cfg.Secret)})
+		return set, nil
+	}
+
+	if cfg.PrivateKeyFile != "" {
+		key, err := LoadPrivateKeyFile(cfg.PrivateKeyFile, method)
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.KeyId != "" {
+			key.Id = cfg.KeyId
+		}
+
+		set.SetSigningKey(key)
+	}
+
+	for _, file := range cfg.PublicKeyFiles {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+
+		key, err := LoadPublicKeyFile(file, method)
+		if err != nil {
+			return nil, err
+		}
+
+		set.Add(key)
+	}
+
+	if len(set.keys) == 0 {
+		return nil, fmt.Errorf("signing algorithm %s requires a private key or at least one public key", algorithm)
+	}
+
+	return set, nil
+}
+
+// LoadPrivateKeyFile reads a PEM encoded private key usable for signing.
+func LoadPrivateKeyFile(file string, preferred jwt.SigningMethod) (*Key, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key %s: %w", file, err)
+	}
+
+	signer, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", file, err)
+	}
+
+	return NewAsymmetricKey(signer.Public(), signer, preferred)
+}
+
+// LoadPublicKeyFile reads a PEM encoded public key or certificate usable for verification.
+func LoadPublicKeyFile(file string, preferred jwt.SigningMethod) (*Key, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key %s: %w", file, err)
+	}
+
+	publicKey, err := ParsePublicKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", file, err)
+	}
+
+	return NewAsymmetricKey(publicKey, nil, preferred)
+}
+
+// NewAsymmetricKey creates a key whose id is derived from the public key.
+func NewAsymmetricKey(publicKey crypto.PublicKey, privateKey crypto.Signer, preferred jwt.SigningMethod) (*Key, error) {
+	method, err := algorithmForKey(publicKey, preferred)
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := KeyId(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{Id: kid, Algorithm: method, PrivateKey: privateKey, PublicKey: publicKey}, nil
+}
+
+// KeyId derives a stable key id from the SHA-256 digest of the public key.
+func KeyId(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	sum := sha256.Sum256(der)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), nil
+}
+
+// ParsePrivateKeyPEM parses a PKCS#8, PKCS#1 or SEC 1 encoded private key.
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key block %q", block.Type)
+}
+
+// ParsePublicKeyPEM parses a PKIX or PKCS#1 encoded public key or an X.509 certificate.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key block %q", block.Type)
+}
+
+// algorithmForKey picks the signing method matching the key type, keeping the
+// configured method when it belongs to the same family.
+func algorithmForKey(publicKey crypto.PublicKey, preferred jwt.SigningMethod) (jwt.SigningMethod, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		switch preferred.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			return preferred, nil
+		}
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+		return nil, fmt.Errorf("unsupported elliptic curve %s", key.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
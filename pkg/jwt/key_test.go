@@ -0,0 +1,452 @@
+package pkgjwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const testIssuer = "test-issuer"
+
+func generateRsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func generateEcKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func generateEdKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func encodePem(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+func pkcs8Pem(t *testing.T, key crypto.Signer) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return encodePem("PRIVATE KEY", der)
+}
+
+func pkixPem(t *testing.T, key crypto.PublicKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return encodePem("PUBLIC KEY", der)
+}
+
+func certificatePem(t *testing.T, key crypto.Signer) []byte {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "jwt"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return encodePem("CERTIFICATE", der)
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+// signToken signs claims for the adapter under test, bypassing IssueJwt so
+// that the header and the key can be chosen freely.
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key any) string {
+	t.Helper()
+
+	now := time.Now()
+	token := jwt.NewWithClaims(method, jwt.MapClaims{
+		"id":  1,
+		"sub": "admin",
+		"iss": testIssuer,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+		"jti": "jti",
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestParsePrivateKeyPEM(t *testing.T) {
+	rsaKey := generateRsaKey(t)
+	ecKey := generateEcKey(t, elliptic.P256())
+	edKey := generateEdKey(t)
+
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		pem  []byte
+		want crypto.PublicKey
+	}{
+		{"pkcs1 rsa", encodePem("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), rsaKey.Public()},
+		{"pkcs8 rsa", pkcs8Pem(t, rsaKey), rsaKey.Public()},
+		{"sec1 ec", encodePem("EC PRIVATE KEY", sec1), ecKey.Public()},
+		{"pkcs8 ec", pkcs8Pem(t, ecKey), ecKey.Public()},
+		{"pkcs8 ed25519", pkcs8Pem(t, edKey), edKey.Public()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := ParsePrivateKeyPEM(tt.pem)
+			if err != nil {
+				t.Fatalf("ParsePrivateKeyPEM() error = %v", err)
+			}
+
+			equal, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+			if !ok || !equal.Equal(tt.want) {
+				t.Errorf("public key of the parsed private key does not match")
+			}
+		})
+	}
+
+	for name, data := range map[string][]byte{
+		"no pem block":  []byte("not a key"),
+		"garbage block": encodePem("PRIVATE KEY", []byte("garbage")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParsePrivateKeyPEM(data); err == nil {
+				t.Error("ParsePrivateKeyPEM() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestParsePublicKeyPEM(t *testing.T) {
+	rsaKey := generateRsaKey(t)
+	ecKey := generateEcKey(t, elliptic.P384())
+	edKey := generateEdKey(t)
+
+	tests := []struct {
+		name string
+		pem  []byte
+		want crypto.PublicKey
+	}{
+		{"pkix rsa", pkixPem(t, rsaKey.Public()), rsaKey.Public()},
+		{"pkcs1 rsa", encodePem("RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)), rsaKey.Public()},
+		{"pkix ec", pkixPem(t, ecKey.Public()), ecKey.Public()},
+		{"pkix ed25519", pkixPem(t, edKey.Public()), edKey.Public()},
+		{"rsa certificate", certificatePem(t, rsaKey), rsaKey.Public()},
+		{"ec certificate", certificatePem(t, ecKey), ecKey.Public()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publicKey, err := ParsePublicKeyPEM(tt.pem)
+			if err != nil {
+				t.Fatalf("ParsePublicKeyPEM() error = %v", err)
+			}
+
+			equal, ok := publicKey.(interface{ Equal(crypto.PublicKey) bool })
+			if !ok || !equal.Equal(tt.want) {
+				t.Errorf("parsed public key does not match")
+			}
+		})
+	}
+
+	if _, err := ParsePublicKeyPEM(encodePem("PUBLIC KEY", []byte("garbage"))); err == nil {
+		t.Error("ParsePublicKeyPEM() error = nil, want an error")
+	}
+}
+
+func TestNewAsymmetricKeyAlgorithm(t *testing.T) {
+	rsaKey := generateRsaKey(t)
+
+	tests := []struct {
+		name      string
+		key       crypto.Signer
+		preferred jwt.SigningMethod
+		want      string
+	}{
+		{"rsa default", rsaKey, jwt.SigningMethodRS256, "RS256"},
+		{"rsa keeps the configured family", rsaKey, jwt.SigningMethodPS384, "PS384"},
+		{"rsa ignores another family", rsaKey, jwt.SigningMethodES256, "RS256"},
+		{"p-256", generateEcKey(t, elliptic.P256()), jwt.SigningMethodRS256, "ES256"},
+		{"p-384", generateEcKey(t, elliptic.P384()), jwt.SigningMethodES256, "ES384"},
+		{"p-521", generateEcKey(t, elliptic.P521()), jwt.SigningMethodES256, "ES512"},
+		{"ed25519", generateEdKey(t), jwt.SigningMethodRS256, "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := NewAsymmetricKey(tt.key.Public(), tt.key, tt.preferred)
+			if err != nil {
+				t.Fatalf("NewAsymmetricKey() error = %v", err)
+			}
+
+			if got := key.Algorithm.Alg(); got != tt.want {
+				t.Errorf("algorithm = %s, want %s", got, tt.want)
+			}
+
+			kid, err := KeyId(tt.key.Public())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key.Id != kid || kid == "" {
+				t.Errorf("key id = %q, want the stable id %q", key.Id, kid)
+			}
+		})
+	}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	rsaKey := generateRsaKey(t)
+	otherKey := generateEcKey(t, elliptic.P256())
+
+	privateFile := writeFile(t, "private.pem", pkcs8Pem(t, rsaKey))
+	publicFile := writeFile(t, "public.pem", pkixPem(t, otherKey.Public()))
+
+	rsaKid, _ := KeyId(rsaKey.Public())
+	otherKid, _ := KeyId(otherKey.Public())
+
+	tests := []struct {
+		name        string
+		config      KeyConfig
+		wantErr     bool
+		signingKid  string
+		verifyKids  []string
+		wantSigning bool
+	}{
+		{
+			name:        "hmac with the default key id",
+			config:      KeyConfig{Secret: "secret"},
+			signingKid:  DefaultHmacKeyId,
+			wantSigning: true,
+		},
+		{
+			name:        "hmac with a configured key id",
+			config:      KeyConfig{Algorithm: "HS256", KeyId: "k1", Secret: "secret"},
+			signingKid:  "k1",
+			wantSigning: true,
+		},
+		{
+			name:    "hmac without secret",
+			config:  KeyConfig{Algorithm: "HS256"},
+			wantErr: true,
+		},
+		{
+			name:    "none is refused",
+			config:  KeyConfig{Algorithm: "none", Secret: "secret"},
+			wantErr: true,
+		},
+		{
+			name:        "private key and extra public key",
+			config:      KeyConfig{Algorithm: "RS256", PrivateKeyFile: privateFile, PublicKeyFiles: []string{publicFile, " "}},
+			signingKid:  rsaKid,
+			verifyKids:  []string{otherKid},
+			wantSigning: true,
+		},
+		{
+			name:        "configured key id replaces the derived one",
+			config:      KeyConfig{Algorithm: "RS256", KeyId: "custom", PrivateKeyFile: privateFile},
+			signingKid:  "custom",
+			wantSigning: true,
+		},
+		{
+			name:       "verification only",
+			config:     KeyConfig{Algorithm: "ES256", PublicKeyFiles: []string{publicFile}},
+			verifyKids: []string{otherKid},
+		},
+		{
+			name:    "asymmetric without keys",
+			config:  KeyConfig{Algorithm: "RS256"},
+			wantErr: true,
+		},
+		{
+			name:    "missing private key file",
+			config:  KeyConfig{Algorithm: "RS256", PrivateKeyFile: filepath.Join(t.TempDir(), "missing.pem")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := LoadKeySet(tt.config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadKeySet() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadKeySet() error = %v", err)
+			}
+
+			signing, err := set.SigningKey()
+			if tt.wantSigning {
+				if err != nil {
+					t.Fatalf("SigningKey() error = %v", err)
+				}
+				if signing.Id != tt.signingKid {
+					t.Errorf("signing key id = %q, want %q", signing.Id, tt.signingKid)
+				}
+			} else if !errors.Is(err, ErrNoSigningKey) {
+				t.Errorf("SigningKey() error = %v, want %v", err, ErrNoSigningKey)
+			}
+
+			for _, kid := range tt.verifyKids {
+				if _, ok := set.Lookup(kid); !ok {
+					t.Errorf("Lookup(%q) found no key", kid)
+				}
+			}
+		})
+	}
+}
+
+func TestKeySetLookup(t *testing.T) {
+	signing := &Key{Id: "signing", Algorithm: jwt.SigningMethodHS256, Secret: []byte("a")}
+	verifying := &Key{Id: "verifying", Algorithm: jwt.SigningMethodHS256, Secret: []byte("b")}
+
+	set := NewKeySet()
+	set.Add(verifying)
+
+	if _, ok := set.Lookup(""); ok {
+		t.Error(`Lookup("") found a key in a set without signing key`)
+	}
+
+	set.SetSigningKey(signing)
+
+	tests := []struct {
+		name string
+		kid  string
+		want *Key
+	}{
+		{"empty kid falls back to the signing key", "", signing},
+		{"signing key by id", "signing", signing},
+		{"verification key by id", "verifying", verifying},
+		{"unknown kid", "unknown", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := set.Lookup(tt.kid)
+			if ok != (tt.want != nil) || got != tt.want {
+				t.Errorf("Lookup(%q) = %v, %v, want %v", tt.kid, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyJwtKeySelection(t *testing.T) {
+	rsaKey := generateRsaKey(t)
+	ecKey := generateEcKey(t, elliptic.P256())
+
+	signing, err := NewAsymmetricKey(rsaKey.Public(), rsaKey, jwt.SigningMethodRS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifying, err := NewAsymmetricKey(ecKey.Public(), nil, jwt.SigningMethodES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := NewKeySet()
+	set.SetSigningKey(signing)
+	set.Add(verifying)
+	adapter := NewJwtAdapterWithKeySet(testIssuer, set)
+
+	// the HMAC secret an attacker would try is the public key of the signing key
+	publicPem := pkixPem(t, rsaKey.Public())
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"signing key by kid", signToken(t, jwt.SigningMethodRS256, signing.Id, rsaKey), false},
+		{"signing key without kid", signToken(t, jwt.SigningMethodRS256, "", rsaKey), false},
+		{"verification key by kid", signToken(t, jwt.SigningMethodES256, verifying.Id, ecKey), false},
+		{"unknown kid", signToken(t, jwt.SigningMethodRS256, "unknown", rsaKey), true},
+		{"hmac with the public key", signToken(t, jwt.SigningMethodHS256, signing.Id, publicPem), true},
+		{"alg of another key", signToken(t, jwt.SigningMethodES256, signing.Id, ecKey), true},
+		{"wrong key for the kid", signToken(t, jwt.SigningMethodRS256, signing.Id, generateRsaKey(t)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := adapter.VerifyJwt(tt.token)
+
+			switch {
+			case !tt.wantErr && err != nil:
+				t.Fatalf("VerifyJwt() error = %v", err)
+			case !tt.wantErr && claims.Sub != "admin":
+				t.Errorf("sub = %q, want admin", claims.Sub)
+			case tt.wantErr && err == nil:
+				t.Error("VerifyJwt() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,443 @@
+package pkgjwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	KeyStatusPending = "pending"
+	KeyStatusActive  = "active"
+	KeyStatusRetired = "retired"
+
+	// DefaultKeyOverlap keeps a retired key verifiable when no overlap is configured.
+	DefaultKeyOverlap = 24 * time.Hour
+	// DefaultKeyRefreshInterval is how often a running service reloads the key directory.
+	DefaultKeyRefreshInterval = time.Minute
+	// JwksMaxAge is how long clients may cache the published JWKS.
+	JwksMaxAge = 5 * time.Minute
+	// DefaultKeyPublishDelay keeps a new key pending until every instance has
+	// reloaded the directory and every cached JWKS contains it.
+	DefaultKeyPublishDelay = DefaultKeyRefreshInterval + JwksMaxAge
+
+	manifestFile = "keys.json"
+	lockFile     = ".rotate.lock"
+	staleLock    = time.Minute
+	readyRetry   = 200 * time.Millisecond
+)
+
+var ErrRotationInProgress = errors.New("key rotation already in progress")
+
+// KeyMetadata describes a key file in the key directory.
+type KeyMetadata struct {
+	Id        string     `json:"kid"`
+	Algorithm string     `json:"alg"`
+	File      string     `json:"file"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RetiredAt *time.Time `json:"retiredAt,omitempty"`
+}
+
+type keyManifest struct {
+	Keys []KeyMetadata `json:"keys"`
+}
+
+// KeyStore keeps rotating signing keys as PEM files in a directory, together
+// with a manifest recording which key is active and when the others retired.
+// A new key is first published as pending, so that verifiers learn it before
+// any token is signed with it, and becomes active after PublishDelay.
+type KeyStore struct {
+	Dir          string
+	Algorithm    jwt.SigningMethod
+	Overlap      time.Duration
+	PublishDelay time.Duration
+}
+
+// NewKeyStore creates a key store generating keys for the given algorithm.
+func NewKeyStore(dir string, algorithm string, overlap time.Duration) (*KeyStore, error) {
+	if algorithm == "" {
+		algorithm = DefaultAlgorithm
+	}
+
+	method := jwt.GetSigningMethod(algorithm)
+	if method == nil || method == jwt.SigningMethodNone {
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+
+	if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+		return nil, fmt.Errorf("key rotation requires an asymmetric algorithm, got %s", algorithm)
+	}
+
+	if overlap <= 0 {
+		overlap = DefaultKeyOverlap
+	}
+
+	return &KeyStore{Dir: dir, Algorithm: method, Overlap: overlap, PublishDelay: DefaultKeyPublishDelay}, nil
+}
+
+// Load returns the active key and the keys that only verify: the pending key
+// and the retired keys still inside the overlap window.
+func (s *KeyStore) Load() (*Key, []*Key, error) {
+	manifest, err := s.readManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+
+	var active *Key
+	var verifying []*Key
+	for _, meta := range manifest.Keys {
+		if meta.Status == KeyStatusRetired && s.expired(meta, now) {
+			continue
+		}
+
+		key, err := LoadPrivateKeyFile(filepath.Join(s.Dir, meta.File), jwt.GetSigningMethod(meta.Algorithm))
+		if err != nil {
+			return nil, nil, err
+		}
+		key.Id = meta.Id
+
+		if meta.Status == KeyStatusActive {
+			active = key
+			continue
+		}
+
+		// pending and retired keys only verify
+		key.PrivateKey = nil
+		verifying = append(verifying, key)
+	}
+
+	return active, verifying, nil
+}
+
+// Refresh reloads the key directory into the key set. Extra keys are kept for verification.
+func (s *KeyStore) Refresh(set *KeySet, extra ...*Key) error {
+	active, verifying, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	set.Replace(active, append(verifying, extra...))
+
+	return nil
+}
+
+// ActivatesAt returns when a pending key becomes active.
+func (s *KeyStore) ActivatesAt(meta *KeyMetadata) time.Time {
+	return meta.CreatedAt.Add(s.PublishDelay)
+}
+
+// Rotate publishes a new pending key, activated by Advance once PublishDelay
+// has passed. The first key of an empty directory is active at once. When a
+// key is already pending it is returned instead.
+func (s *KeyStore) Rotate() (*KeyMetadata, error) {
+	return s.update(func(manifest *keyManifest, now time.Time) (*KeyMetadata, error) {
+		if pending := manifest.find(KeyStatusPending); pending != nil {
+			return pending, nil
+		}
+
+		return s.addKey(manifest, now)
+	})
+}
+
+// Advance moves the rotation forward: it creates a key when none is active,
+// activates the pending key once PublishDelay has passed and publishes a new
+// pending key when the active key is older than maxAge. A zero maxAge disables
+// scheduled rotation. The state is checked under the directory lock, so that
+// instances sharing the directory never rotate twice. It returns nil when
+// nothing changed.
+func (s *KeyStore) Advance(maxAge time.Duration) (*KeyMetadata, error) {
+	return s.update(func(manifest *keyManifest, now time.Time) (*KeyMetadata, error) {
+		active := manifest.find(KeyStatusActive)
+		pending := manifest.find(KeyStatusPending)
+
+		switch {
+		case active == nil || pending != nil && !now.Before(s.ActivatesAt(pending)):
+			if pending == nil {
+				return s.addKey(manifest, now)
+			}
+			return s.promote(manifest, pending.Id, now), nil
+		case pending == nil && maxAge > 0 && now.Sub(active.CreatedAt) >= maxAge:
+			return s.addKey(manifest, now)
+		}
+
+		return nil, nil
+	})
+}
+
+// Ready makes sure a key is active before the service signs anything: it
+// creates the first key of an empty directory or activates a pending key that
+// became due while no instance was running. When another instance holds the
+// directory lock, e.g. on the first boot of several instances, it waits for it
+// and retries until ctx is done.
+func (s *KeyStore) Ready(ctx context.Context) error {
+	for {
+		_, err := s.Advance(0)
+		if err == nil {
+			active, _, err := s.Load()
+			if err != nil {
+				return err
+			}
+			if active != nil {
+				return nil
+			}
+		} else if !errors.Is(err, ErrRotationInProgress) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("no active jwt signing key: %w", ctx.Err())
+		case <-time.After(readyRetry):
+		}
+	}
+}
+
+// update runs a change of the manifest under the directory lock and writes it,
+// without the retired keys whose overlap window has passed, when it returns a key.
+func (s *KeyStore) update(change func(manifest *keyManifest, now time.Time) (*KeyMetadata, error)) (*KeyMetadata, error) {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	manifest, err := s.readManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	changed, err := change(manifest, now)
+	if err != nil || changed == nil {
+		return changed, err
+	}
+
+	keys := make([]KeyMetadata, 0, len(manifest.Keys))
+	for _, meta := range manifest.Keys {
+		if s.expired(meta, now) {
+			if err := os.Remove(filepath.Join(s.Dir, meta.File)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Warn().Err(err).Str("kid", meta.Id).Msg("failed to remove expired jwt key")
+			}
+			continue
+		}
+
+		keys = append(keys, meta)
+	}
+
+	if err := s.writeManifest(&keyManifest{Keys: keys}); err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// addKey generates a key, pending unless no key is active yet.
+func (s *KeyStore) addKey(manifest *keyManifest, now time.Time) (*KeyMetadata, error) {
+	signer, err := generateKey(s.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := NewAsymmetricKey(signer.Public(), signer, s.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	file := key.Id + ".pem"
+	if err := writePrivateKey(filepath.Join(s.Dir, file), signer); err != nil {
+		return nil, err
+	}
+
+	meta := KeyMetadata{
+		Id:        key.Id,
+		Algorithm: key.Algorithm.Alg(),
+		File:      file,
+		Status:    KeyStatusPending,
+		CreatedAt: now,
+	}
+	if manifest.find(KeyStatusActive) == nil {
+		meta.Status = KeyStatusActive
+	}
+	manifest.Keys = append(manifest.Keys, meta)
+
+	log.Info().Str("kid", meta.Id).Str("alg", meta.Algorithm).Str("status", meta.Status).Msg("jwt signing key created")
+
+	return &meta, nil
+}
+
+// promote activates the key and retires the active one.
+func (s *KeyStore) promote(manifest *keyManifest, kid string, now time.Time) *KeyMetadata {
+	var promoted *KeyMetadata
+	for i := range manifest.Keys {
+		meta := &manifest.Keys[i]
+
+		switch {
+		case meta.Id == kid:
+			meta.Status = KeyStatusActive
+			promoted = meta
+		case meta.Status == KeyStatusActive:
+			meta.Status = KeyStatusRetired
+			meta.RetiredAt = &now
+		}
+	}
+
+	log.Info().Str("kid", promoted.Id).Str("alg", promoted.Algorithm).Msg("jwt signing key rotated")
+
+	copied := *promoted
+	return &copied
+}
+
+// StartRotation reloads the key directory every refresh interval so keys rotated
+// by another instance or the CLI are picked up, activates pending keys and
+// publishes a new key once the active key is older than rotateAfter. A zero
+// rotateAfter disables scheduled rotation.
+func (s *KeyStore) StartRotation(ctx context.Context, set *KeySet, refresh time.Duration, rotateAfter time.Duration, extra ...*Key) {
+	if refresh <= 0 {
+		refresh = DefaultKeyRefreshInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if _, err := s.Advance(rotateAfter); err != nil && !errors.Is(err, ErrRotationInProgress) {
+				log.Error().Err(err).Msg("failed to rotate jwt signing key")
+			}
+
+			if err := s.Refresh(set, extra...); err != nil {
+				log.Error().Err(err).Msg("failed to refresh jwt keys")
+			}
+		}
+	}()
+}
+
+func (s *KeyStore) expired(meta KeyMetadata, now time.Time) bool {
+	return meta.Status == KeyStatusRetired && meta.RetiredAt != nil && now.Sub(*meta.RetiredAt) > s.Overlap
+}
+
+// find returns the first key with the status.
+func (m *keyManifest) find(status string) *KeyMetadata {
+	for i := range m.Keys {
+		if m.Keys[i].Status == status {
+			return &m.Keys[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *KeyStore) readManifest() (*keyManifest, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, manifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &keyManifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key manifest: %w", err)
+	}
+
+	manifest := new(keyManifest)
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse key manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func (s *KeyStore) writeManifest(manifest *keyManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(s.Dir, manifestFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write key manifest: %w", err)
+	}
+
+	return os.Rename(tmp, filepath.Join(s.Dir, manifestFile))
+}
+
+// lock prevents two processes sharing the directory from rotating at the same time.
+func (s *KeyStore) lock() (func(), error) {
+	path := filepath.Join(s.Dir, lockFile)
+
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+		_ = os.Remove(path)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return nil, ErrRotationInProgress
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock key directory: %w", err)
+	}
+	_ = f.Close()
+
+	return func() { _ = os.Remove(path) }, nil
+}
+
+func generateKey(method jwt.SigningMethod) (crypto.Signer, error) {
+	switch method {
+	case jwt.SigningMethodES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case jwt.SigningMethodES384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case jwt.SigningMethodES512:
+		return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case jwt.SigningMethodEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+
+	return nil, fmt.Errorf("cannot generate key for algorithm %s", method.Alg())
+}
+
+func writePrivateKey(path string, signer crypto.Signer) error {
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+
+	return nil
+}
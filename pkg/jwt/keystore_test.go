@@ -0,0 +1,233 @@
+package pkgjwt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestKeyStore(t *testing.T) *KeyStore {
+	t.Helper()
+
+	store, err := NewKeyStore(t.TempDir(), "ES256", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func statuses(t *testing.T, store *KeyStore) map[string]string {
+	t.Helper()
+
+	manifest, err := store.readManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := make(map[string]string, len(manifest.Keys))
+	for _, meta := range manifest.Keys {
+		result[meta.Id] = meta.Status
+	}
+
+	return result
+}
+
+func TestNewKeyStoreRefusesSharedSecrets(t *testing.T) {
+	if _, err := NewKeyStore(t.TempDir(), "HS256", 0); err == nil {
+		t.Error("NewKeyStore() error = nil, want an error for HS256")
+	}
+}
+
+func TestKeyStoreRotation(t *testing.T) {
+	store := newTestKeyStore(t)
+
+	// an empty directory gets an active key at once
+	first, err := store.Advance(0)
+	if err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	if first == nil || first.Status != KeyStatusActive {
+		t.Fatalf("first key = %+v, want an active key", first)
+	}
+
+	if changed, err := store.Advance(0); err != nil || changed != nil {
+		t.Fatalf("Advance() = %+v, %v, want no change", changed, err)
+	}
+
+	// a rotated key is published before it signs
+	pending, err := store.Rotate()
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if pending.Status != KeyStatusPending {
+		t.Fatalf("rotated key status = %s, want %s", pending.Status, KeyStatusPending)
+	}
+
+	again, err := store.Rotate()
+	if err != nil || again.Id != pending.Id {
+		t.Fatalf("second Rotate() = %+v, %v, want the pending key %s", again, err, pending.Id)
+	}
+
+	set := NewKeySet()
+	if err := store.Refresh(set); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	signing, err := set.SigningKey()
+	if err != nil || signing.Id != first.Id {
+		t.Fatalf("signing key = %v, %v, want %s", signing, err, first.Id)
+	}
+
+	published, ok := set.Lookup(pending.Id)
+	if !ok {
+		t.Fatal("pending key is not in the key set")
+	}
+	if published.PrivateKey != nil {
+		t.Error("pending key can sign, want verification only")
+	}
+	if !containsKid(set.JWKS(), pending.Id) {
+		t.Error("pending key is not published in the JWKS")
+	}
+
+	// the pending key waits for the publish delay
+	if changed, err := store.Advance(0); err != nil || changed != nil {
+		t.Fatalf("Advance() before the publish delay = %+v, %v, want no change", changed, err)
+	}
+
+	store.PublishDelay = 0
+
+	promoted, err := store.Advance(0)
+	if err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	if promoted == nil || promoted.Id != pending.Id || promoted.Status != KeyStatusActive {
+		t.Fatalf("promoted key = %+v, want %s active", promoted, pending.Id)
+	}
+
+	want := map[string]string{first.Id: KeyStatusRetired, pending.Id: KeyStatusActive}
+	if got := statuses(t, store); !equalStatuses(got, want) {
+		t.Fatalf("statuses = %v, want %v", got, want)
+	}
+
+	// the retired key still verifies during the overlap
+	if err := store.Refresh(set); err != nil {
+		t.Fatal(err)
+	}
+	if signing, _ := set.SigningKey(); signing.Id != pending.Id {
+		t.Errorf("signing key = %s, want %s", signing.Id, pending.Id)
+	}
+	if _, ok := set.Lookup(first.Id); !ok {
+		t.Error("retired key is not verifiable during the overlap")
+	}
+
+	// an old active key schedules the next rotation
+	next, err := store.Advance(time.Nanosecond)
+	if err != nil || next == nil || next.Status != KeyStatusPending {
+		t.Fatalf("Advance(maxAge) = %+v, %v, want a pending key", next, err)
+	}
+
+	// retired keys are removed once the overlap has passed
+	store.Overlap = time.Nanosecond
+	time.Sleep(time.Millisecond)
+
+	if _, err := store.Advance(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := statuses(t, store)[first.Id]; ok {
+		t.Error("expired key is still in the manifest")
+	}
+	if _, err := os.Stat(filepath.Join(store.Dir, first.File)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expired key file still exists: %v", err)
+	}
+}
+
+func TestKeyStoreLock(t *testing.T) {
+	store := newTestKeyStore(t)
+
+	unlock, err := store.lock()
+	if err != nil {
+		t.Fatalf("lock() error = %v", err)
+	}
+
+	if _, err := store.Advance(0); !errors.Is(err, ErrRotationInProgress) {
+		t.Fatalf("Advance() while locked error = %v, want %v", err, ErrRotationInProgress)
+	}
+
+	unlock()
+
+	if _, err := store.Advance(0); err != nil {
+		t.Fatalf("Advance() after unlock error = %v", err)
+	}
+
+	// a lock left by a crashed process is taken over once stale
+	if _, err := store.lock(); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLock)
+	if err := os.Chtimes(filepath.Join(store.Dir, lockFile), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Rotate(); err != nil {
+		t.Errorf("Rotate() with a stale lock error = %v", err)
+	}
+}
+
+func TestKeyStoreReadyWaitsForTheLock(t *testing.T) {
+	store := newTestKeyStore(t)
+
+	unlock, err := store.lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	short, cancel := context.WithTimeout(context.Background(), 3*readyRetry)
+	defer cancel()
+
+	if err := store.Ready(short); err == nil {
+		t.Fatal("Ready() error = nil while another instance holds the lock")
+	}
+
+	time.AfterFunc(readyRetry, unlock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*readyRetry)
+	defer cancel()
+
+	if err := store.Ready(ctx); err != nil {
+		t.Fatalf("Ready() error = %v", err)
+	}
+
+	active, _, err := store.Load()
+	if err != nil || active == nil {
+		t.Fatalf("Load() = %v, %v, want an active key", active, err)
+	}
+}
+
+func containsKid(jwks JWKS, kid string) bool {
+	for _, jwk := range jwks.Keys {
+		if jwk.Kid == kid {
+			return true
+		}
+	}
+
+	return false
+}
+
+func equalStatuses(got map[string]string, want map[string]string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+
+	for kid, status := range want {
+		if got[kid] != status {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,229 @@
+package lockout
+
+import (
+	"application/app/models"
+	"application/pkg/cache"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	ScopeAccount = "account"
+	ScopeIp      = "ip"
+
+	DefaultThreshold   = 5
+	DefaultIpThreshold = 20
+	DefaultBaseDelay   = time.Minute
+	DefaultMaxDelay    = time.Hour
+	DefaultWindow      = 24 * time.Hour
+	DefaultCleanTTL    = 5 * time.Second
+)
+
+var ErrLocked = errors.New("too many failed attempts")
+
+// LockedError tells until when an account or IP address is locked.
+type LockedError struct {
+	Scope string
+	Until time.Time
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s: %s locked until %s", ErrLocked, e.Scope, e.Until.Format(time.RFC3339))
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrLocked
+}
+
+// RetryAfter is the number of seconds left before the lock ends.
+func (e *LockedError) RetryAfter() int {
+	return int(math.Ceil(time.Until(e.Until).Seconds()))
+}
+
+// Store keeps the failed attempt counters shared by every instance and the CLI.
+type Store interface {
+	FindLoginAttempt(ctx context.Context, scope string, key string) (*models.LoginAttempt, error)
+	IncrementLoginFailure(ctx context.Context, scope string, key string, now time.Time, resetBefore time.Time) (int, error)
+	LockLoginAttempt(ctx context.Context, scope string, key string, lockedUntil time.Time) error
+	DeleteLoginAttempt(ctx context.Context, scope string, key string) (int64, error)
+}
+
+type Config struct {
+	// Threshold is the number of consecutive failures locking an account.
+	Threshold int
+	// IpThreshold is the number of consecutive failures locking an IP address.
+	IpThreshold int
+	// BaseDelay is the first lock duration, doubled on every further failure.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Window restarts the counter when the last failure is older.
+	Window time.Duration
+	// CleanTTL is how long an account or IP address without failures is
+	// remembered, sparing the counters a query on every request. A lock set by
+	// another instance is seen at most that late.
+	CleanTTL time.Duration
+	// Prefix is put before the stored scopes, so that guards counting other
+	// attempts than logins share the counters without mixing them up.
+	Prefix string
+}
+
+// Guard counts failed attempts per account and per IP address and locks them
+// with a progressive backoff once a threshold is reached.
+type Guard struct {
+	store  Store
+	config Config
+	// clean remembers the scope keys that have no failed attempt
+	clean *cache.Cache[string, struct{}]
+}
+
+// NewGuard creates a guard. Zero config values fall back to the defaults.
+func NewGuard(store Store, config Config) *Guard {
+	if config.Threshold <= 0 {
+		config.Threshold = DefaultThreshold
+	}
+	if config.IpThreshold <= 0 {
+		config.IpThreshold = DefaultIpThreshold
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = DefaultBaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = DefaultMaxDelay
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultWindow
+	}
+	if config.CleanTTL <= 0 {
+		config.CleanTTL = DefaultCleanTTL
+	}
+
+	return &Guard{store: store, config: config, clean: cache.New[string, struct{}]()}
+}
+
+// Check returns a *LockedError when the account or the IP address is locked.
+func (g *Guard) Check(ctx context.Context, account string, ipAddress string) error {
+	if err := g.check(ctx, ScopeIp, ipAddress); err != nil {
+		return err
+	}
+
+	return g.check(ctx, ScopeAccount, account)
+}
+
+// Failure counts a failed attempt. It returns the scopes that got locked by it.
+func (g *Guard) Failure(ctx context.Context, account string, ipAddress string) ([]*LockedError, error) {
+	var locked []*LockedError
+
+	for _, target := range []struct {
+		scope     string
+		key       string
+		threshold int
+	}{
+		{ScopeAccount, account, g.config.Threshold},
+		{ScopeIp, ipAddress, g.config.IpThreshold},
+	} {
+		if target.key == "" {
+			continue
+		}
+		g.clean.Delete(cleanKey(target.scope, target.key))
+
+		now := time.Now()
+		failures, err := g.store.IncrementLoginFailure(ctx, g.scope(target.scope), target.key, now, now.Add(-g.config.Window))
+		if err != nil {
+			return locked, err
+		}
+
+		if failures < target.threshold {
+			continue
+		}
+
+		until := now.Add(g.delay(failures - target.threshold))
+		if err := g.store.LockLoginAttempt(ctx, g.scope(target.scope), target.key, until); err != nil {
+			return locked, err
+		}
+
+		locked = append(locked, &LockedError{Scope: target.scope, Until: until})
+	}
+
+	return locked, nil
+}
+
+// Success clears the failures of the account. The IP address counter is kept
+// so that one valid account cannot be used to reset a password spraying run.
+// Nothing is written when the account is known to have no failures.
+func (g *Guard) Success(ctx context.Context, account string) error {
+	key := cleanKey(ScopeAccount, account)
+	if _, ok := g.clean.Get(key); ok {
+		return nil
+	}
+
+	if _, err := g.store.DeleteLoginAttempt(ctx, g.scope(ScopeAccount), account); err != nil {
+		return err
+	}
+	g.clean.Set(key, struct{}{}, g.config.CleanTTL)
+
+	return nil
+}
+
+// Unlock clears the failures of an account or an IP address. It reports
+// whether there was anything to clear.
+func (g *Guard) Unlock(ctx context.Context, scope string, key string) (bool, error) {
+	deleted, err := g.store.DeleteLoginAttempt(ctx, g.scope(scope), key)
+	return deleted > 0, err
+}
+
+// StartJanitor purges the expired clean entries until the context is done.
+func (g *Guard) StartJanitor(ctx context.Context) {
+	g.clean.StartJanitor(ctx, time.Minute)
+}
+
+func (g *Guard) check(ctx context.Context, scope string, key string) error {
+	if key == "" {
+		return nil
+	}
+
+	if _, ok := g.clean.Get(cleanKey(scope, key)); ok {
+		return nil
+	}
+
+	attempt, err := g.store.FindLoginAttempt(ctx, g.scope(scope), key)
+	if err != nil {
+		return err
+	}
+
+	if attempt == nil {
+		g.clean.Set(cleanKey(scope, key), struct{}{}, g.config.CleanTTL)
+		return nil
+	}
+
+	if attempt != nil && attempt.LockedUntil != nil && attempt.LockedUntil.After(time.Now()) {
+		return &LockedError{Scope: scope, Until: *attempt.LockedUntil}
+	}
+
+	return nil
+}
+
+// scope is the stored scope of the counters of this guard.
+func (g *Guard) scope(scope string) string {
+	return g.config.Prefix + scope
+}
+
+func cleanKey(scope string, key string) string {
+	return scope + ":" + key
+}
+
+// delay doubles the base delay for every failure past the threshold.
+func (g *Guard) delay(excess int) time.Duration {
+	if excess > 30 {
+		return g.config.MaxDelay
+	}
+
+	delay := g.config.BaseDelay * time.Duration(1<<excess)
+	if delay <= 0 || delay > g.config.MaxDelay {
+		return g.config.MaxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,292 @@
+package lockout
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStore keeps the counters in a map like the login_attempts table.
+type memoryStore struct {
+	mu       sync.Mutex
+	attempts map[string]*models.LoginAttempt
+	reads    int
+	err      error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{attempts: make(map[string]*models.LoginAttempt)}
+}
+
+func (s *memoryStore) FindLoginAttempt(_ context.Context, scope string, key string) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reads++
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	attempt, ok := s.attempts[scope+"|"+key]
+	if !ok {
+		return nil, nil
+	}
+
+	copied := *attempt
+	return &copied, nil
+}
+
+func (s *memoryStore) IncrementLoginFailure(_ context.Context, scope string, key string, now time.Time, resetBefore time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	attempt, ok := s.attempts[scope+"|"+key]
+	if !ok {
+		attempt = &models.LoginAttempt{Scope: scope, Key: key}
+		s.attempts[scope+"|"+key] = attempt
+	}
+
+	if attempt.LastFailedAt.Before(resetBefore) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = now
+
+	return attempt.Failures, nil
+}
+
+func (s *memoryStore) LockLoginAttempt(_ context.Context, scope string, key string, lockedUntil time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempt, ok := s.attempts[scope+"|"+key]; ok {
+		attempt.LockedUntil = &lockedUntil
+	}
+
+	return nil
+}
+
+func (s *memoryStore) DeleteLoginAttempt(_ context.Context, scope string, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.attempts[scope+"|"+key]; !ok {
+		return 0, nil
+	}
+
+	delete(s.attempts, scope+"|"+key)
+
+	return 1, nil
+}
+
+func fail(t *testing.T, guard *Guard, account string, ipAddress string, times int) []*LockedError {
+	t.Helper()
+
+	var locked []*LockedError
+	for range times {
+		var err error
+		if locked, err = guard.Failure(context.Background(), account, ipAddress); err != nil {
+			t.Fatalf("Failure() error = %v", err)
+		}
+	}
+
+	return locked
+}
+
+func TestGuardLocksTheAccount(t *testing.T) {
+	ctx := context.Background()
+	guard := NewGuard(newMemoryStore(), Config{Threshold: 3, IpThreshold: 10, BaseDelay: time.Minute, MaxDelay: time.Hour})
+
+	if locked := fail(t, guard, "admin", "10.0.0.1", 2); len(locked) != 0 {
+		t.Fatalf("locked = %v before the threshold", locked)
+	}
+	if err := guard.Check(ctx, "admin", "10.0.0.1"); err != nil {
+		t.Fatalf("Check() error = %v before the threshold", err)
+	}
+
+	locked := fail(t, guard, "admin", "10.0.0.1", 1)
+	if len(locked) != 1 || locked[0].Scope != ScopeAccount {
+		t.Fatalf("locked = %v, want the account", locked)
+	}
+
+	var lockedErr *LockedError
+	if err := guard.Check(ctx, "admin", "10.0.0.2"); !errors.As(err, &lockedErr) || !errors.Is(err, ErrLocked) {
+		t.Fatalf("Check() error = %v, want a *LockedError", err)
+	}
+	if retry := lockedErr.RetryAfter(); retry < 59 || retry > 60 {
+		t.Errorf("RetryAfter() = %d, want a minute", retry)
+	}
+
+	if err := guard.Check(ctx, "other", "10.0.0.1"); err != nil {
+		t.Errorf("Check() of another account error = %v", err)
+	}
+}
+
+func TestGuardLocksTheIpAddress(t *testing.T) {
+	ctx := context.Background()
+	guard := NewGuard(newMemoryStore(), Config{Threshold: 10, IpThreshold: 3})
+
+	fail(t, guard, "first", "10.0.0.1", 1)
+	fail(t, guard, "second", "10.0.0.1", 1)
+	locked := fail(t, guard, "third", "10.0.0.1", 1)
+
+	if len(locked) != 1 || locked[0].Scope != ScopeIp {
+		t.Fatalf("locked = %v, want the ip address", locked)
+	}
+
+	var lockedErr *LockedError
+	if err := guard.Check(ctx, "fourth", "10.0.0.1"); !errors.As(err, &lockedErr) || lockedErr.Scope != ScopeIp {
+		t.Errorf("Check() error = %v, want the ip address locked", err)
+	}
+
+	// without an address only the account counts
+	if err := guard.Check(ctx, "fourth", ""); err != nil {
+		t.Errorf("Check() without an ip address error = %v", err)
+	}
+}
+
+func TestGuardDelayDoublesUpToTheMaximum(t *testing.T) {
+	guard := NewGuard(newMemoryStore(), Config{Threshold: 1, BaseDelay: time.Minute, MaxDelay: 5 * time.Minute})
+
+	tests := []struct {
+		excess int
+		want   time.Duration
+	}{
+		{0, time.Minute},
+		{1, 2 * time.Minute},
+		{2, 4 * time.Minute},
+		{3, 5 * time.Minute},
+		{40, 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := guard.delay(tt.excess); got != tt.want {
+			t.Errorf("delay(%d) = %s, want %s", tt.excess, got, tt.want)
+		}
+	}
+
+	first := fail(t, guard, "admin", "", 1)
+	second := fail(t, guard, "admin", "", 1)
+	if len(first) != 1 || len(second) != 1 || !second[0].Until.After(first[0].Until.Add(30*time.Second)) {
+		t.Errorf("second lock %v does not outlast the first %v", second, first)
+	}
+}
+
+func TestGuardWindowRestartsTheCounter(t *testing.T) {
+	store := newMemoryStore()
+	guard := NewGuard(store, Config{Threshold: 3, Window: time.Hour})
+
+	fail(t, guard, "admin", "", 2)
+	store.attempts[ScopeAccount+"|admin"].LastFailedAt = time.Now().Add(-2 * time.Hour)
+
+	if locked := fail(t, guard, "admin", "", 1); len(locked) != 0 {
+		t.Errorf("locked = %v, want the old failures forgotten", locked)
+	}
+	if failures := store.attempts[ScopeAccount+"|admin"].Failures; failures != 1 {
+		t.Errorf("failures = %d, want 1", failures)
+	}
+}
+
+func TestGuardSuccessKeepsTheIpCounter(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	guard := NewGuard(store, Config{})
+
+	fail(t, guard, "admin", "10.0.0.1", 2)
+
+	if err := guard.Success(ctx, "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.attempts[ScopeAccount+"|admin"]; ok {
+		t.Error("account failures kept after a success")
+	}
+	if attempt, ok := store.attempts[ScopeIp+"|10.0.0.1"]; !ok || attempt.Failures != 2 {
+		t.Errorf("ip failures = %v, want 2 kept", attempt)
+	}
+}
+
+func TestGuardCleanCache(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	guard := NewGuard(store, Config{Threshold: 1, CleanTTL: 50 * time.Millisecond})
+
+	for range 3 {
+		if err := guard.Check(ctx, "admin", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.reads != 1 {
+		t.Fatalf("reads = %d, want a clean account read once", store.reads)
+	}
+
+	// another instance locks the account, seen once the clean entry expired
+	other := NewGuard(store, Config{Threshold: 1})
+	fail(t, other, "admin", "", 1)
+
+	if err := guard.Check(ctx, "admin", ""); err != nil {
+		t.Fatalf("Check() error = %v, want the clean entry used", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if err := guard.Check(ctx, "admin", ""); !errors.Is(err, ErrLocked) {
+		t.Errorf("Check() error = %v after the clean ttl, want %v", err, ErrLocked)
+	}
+
+	// a failure on this instance forgets the clean entry at once
+	if err := guard.Success(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+	fail(t, guard, "user", "", 1)
+	if err := guard.Check(ctx, "user", ""); !errors.Is(err, ErrLocked) {
+		t.Errorf("Check() error = %v after a local failure, want %v", err, ErrLocked)
+	}
+}
+
+func TestGuardPrefixSeparatesTheCounters(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	logins := NewGuard(store, Config{Threshold: 1})
+	resets := NewGuard(store, Config{Threshold: 1, Prefix: "reset_"})
+
+	fail(t, resets, "admin", "10.0.0.1", 1)
+
+	if _, ok := store.attempts["reset_"+ScopeAccount+"|admin"]; !ok {
+		t.Fatalf("attempts = %v, want the prefixed scope", store.attempts)
+	}
+	if err := logins.Check(ctx, "admin", "10.0.0.1"); err != nil {
+		t.Errorf("Check() of the logins error = %v, want the counters apart", err)
+	}
+	if err := resets.Check(ctx, "admin", ""); !errors.Is(err, ErrLocked) {
+		t.Errorf("Check() of the resets error = %v, want %v", err, ErrLocked)
+	}
+
+	unlocked, err := resets.Unlock(ctx, ScopeAccount, "admin")
+	if err != nil || !unlocked {
+		t.Fatalf("Unlock() = %v, %v, want true", unlocked, err)
+	}
+	if unlocked, _ := logins.Unlock(ctx, ScopeAccount, "admin"); unlocked {
+		t.Error("Unlock() of the logins cleared a counter")
+	}
+}
+
+func TestGuardReturnsStoreErrors(t *testing.T) {
+	store := newMemoryStore()
+	store.err = errors.New("database is down")
+	guard := NewGuard(store, Config{})
+
+	if err := guard.Check(context.Background(), "admin", "10.0.0.1"); err == nil || errors.Is(err, ErrLocked) {
+		t.Errorf("Check() error = %v, want the store error", err)
+	}
+	if _, err := guard.Failure(context.Background(), "admin", "10.0.0.1"); err == nil {
+		t.Error("Failure() error = nil, want the store error")
+	}
+}
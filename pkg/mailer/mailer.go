@@ -0,0 +1,96 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages. Implementations for an SMTP server or a mail API
+// plug in here; the log and file mailers are local stand-ins.
+type Mailer interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+type Config struct {
+	Driver string
+	From   string
+	// Dir receives the messages of the file mailer.
+	Dir string
+}
+
+func New(config Config) (Mailer, error) {
+	switch config.Driver {
+	case "", DriverLog:
+		return &LogMailer{from: config.From}, nil
+	case DriverFile:
+		if config.Dir == "" {
+			return nil, fmt.Errorf("mailer directory is required for the %s driver", DriverFile)
+		}
+		if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create mailer directory: %w", err)
+		}
+		return &FileMailer{from: config.From, dir: config.Dir}, nil
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %q", config.Driver)
+	}
+}
+
+// LogMailer writes messages to the log. Bodies may carry secrets such as
+// reset links, so it is only meant for local development.
+type LogMailer struct {
+	from string
+}
+
+func (m *LogMailer) Send(ctx context.Context, message *Message) error {
+	log.Info().
+		Str("from", m.from).
+		Str("to", message.To).
+		Str("subject", message.Subject).
+		Str("body", message.Body).
+		Msg("mail sent")
+
+	return nil
+}
+
+// FileMailer writes every message to its own .eml file.
+type FileMailer struct {
+	from string
+	dir  string
+}
+
+func (m *FileMailer) Send(ctx context.Context, message *Message) error {
+	now := time.Now()
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "From: %s\r\n", m.from)
+	fmt.Fprintf(&content, "To: %s\r\n", message.To)
+	fmt.Fprintf(&content, "Subject: %s\r\n", message.Subject)
+	fmt.Fprintf(&content, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	content.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	content.WriteString(message.Body)
+
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405"), uuid.New().String())
+	if err := os.WriteFile(filepath.Join(m.dir, name), []byte(content.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Package metrics holds the Prometheus collectors of the service.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "app"
+
+// DefaultPath is where the metrics are served.
+const DefaultPath = "/metrics"
+
+// Registry holds every collector of the service.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewBuildInfoCollector(),
+	)
+}
+
+// Panics counts the panics recovered while serving a request, by route template.
+var Panics = factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "http_panics_total",
+	Help:      "Panics recovered while serving HTTP requests.",
+}, []string{"route"})
+
+// SlowQueries counts the database queries slower than the configured threshold.
+var SlowQueries = factory.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "db_slow_queries_total",
+	Help:      "Database queries slower than the slow query threshold.",
+})
+
+// HttpRequests counts the served requests by method, route template and status.
+var HttpRequests = factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "http_requests_total",
+	Help:      "HTTP requests served.",
+}, []string{"method", "route", "status"})
+
+// HttpDuration observes the time spent serving requests by method, route template and status.
+var HttpDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "http_request_duration_seconds",
+	Help:      "Time spent serving HTTP requests.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"method", "route", "status"})
+
+// DbQueryDuration observes the duration of the database queries, by result (ok or error).
+var DbQueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "db_query_duration_seconds",
+	Help:      "Duration of the database queries.",
+	Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"result"})
+
+var migrationVersion = factory.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "migration_version",
+	Help:      "Version of the applied database migrations, dirty when the last one failed.",
+}, []string{"dirty"})
+
+var buildInfo = factory.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "build_info",
+	Help:      "Version of the running service, always 1.",
+}, []string{"version", "build"})
+
+// SetMigrationVersion reports the version of the database schema.
+func SetMigrationVersion(version uint, dirty bool) {
+	migrationVersion.Reset()
+
+	label := "false"
+	if dirty {
+		label = "true"
+	}
+	migrationVersion.WithLabelValues(label).Set(float64(version))
+}
+
+// SetBuildInfo reports the version of the running service.
+func SetBuildInfo(version string, build string) {
+	buildInfo.Reset()
+	buildInfo.WithLabelValues(version, build).Set(1)
+}
+
+// RegisterDatabase reports the statistics of the connection pool: open,
+// in use and idle connections, waits and closed connections.
+func RegisterDatabase(db *sql.DB, name string) error {
+	err := Registry.Register(collectors.NewDBStatsCollector(db, name))
+
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		return nil
+	}
+
+	return err
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
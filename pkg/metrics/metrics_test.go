@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSetMigrationVersion(t *testing.T) {
+	SetMigrationVersion(13, true)
+	SetMigrationVersion(14, false)
+
+	if got := testutil.CollectAndCount(migrationVersion); got != 1 {
+		t.Errorf("migration version series = %d, want 1", got)
+	}
+	if got := testutil.ToFloat64(migrationVersion.WithLabelValues("false")); got != 14 {
+		t.Errorf("migration version = %v, want 14", got)
+	}
+}
+
+func TestRegisterDatabaseTwice(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	for range 2 {
+		if err := RegisterDatabase(sqlDB, "test"); err != nil {
+			t.Fatalf("RegisterDatabase() error = %v", err)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	SetBuildInfo("1.2.3", "abc123")
+	HttpRequests.WithLabelValues(http.MethodGet, "/orders/:id", "200").Inc()
+
+	server := httptest.NewServer(Handler())
+	t.Cleanup(server.Close)
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`app_build_info{build="abc123",version="1.2.3"} 1`,
+		`app_http_requests_total{method="GET",route="/orders/:id",status="200"}`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics miss %s", want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package mfa
+
+import (
+	"application/pkg/util"
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	// Period is the lifetime of a code in seconds, as expected by authenticator apps.
+	Period = 30
+	// Skew is the number of periods accepted before and after the current one.
+	Skew = 1
+
+	qrCodeSize = 256
+
+	RecoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var validateOpts = totp.ValidateOpts{
+	Period:    Period,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// Enrollment is a new TOTP secret with the ways to hand it to an authenticator app.
+type Enrollment struct {
+	Secret string
+	Uri    string
+	QrCode []byte
+}
+
+// Enroll generates a secret for the account.
+func Enroll(issuer string, account string) (*Enrollment, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: account,
+		Period:      Period,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+
+	image, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render totp qr code: %w", err)
+	}
+
+	var qrCode bytes.Buffer
+	if err := png.Encode(&qrCode, image); err != nil {
+		return nil, fmt.Errorf("failed to encode totp qr code: %w", err)
+	}
+
+	return &Enrollment{
+		Secret: key.Secret(),
+		Uri:    key.URL(),
+		QrCode: qrCode.Bytes(),
+	}, nil
+}
+
+// Validate checks the code against the periods around now. It returns the
+// period the code belongs to, which must be greater than lastStep so that a
+// code cannot be used twice.
+func Validate(secret string, code string, now time.Time, lastStep int64) (int64, bool) {
+	code = strings.TrimSpace(code)
+	current := now.Unix() / Period
+
+	for offset := int64(-Skew); offset <= Skew; offset++ {
+		step := current + offset
+		if step <= lastStep {
+			continue
+		}
+
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*Period, 0), validateOpts)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// GenerateRecoveryCodes returns single-use codes formatted as xxxxx-xxxxx.
+func GenerateRecoveryCodes() []string {
+	codes := make([]string, 0, RecoveryCodeCount)
+	for range RecoveryCodeCount {
+		code := strings.ToLower(util.GenerateRandomString(recoveryCodeLength))
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+	}
+
+	return codes
+}
+
+// HashRecoveryCode digests a recovery code ignoring case, spaces and dashes.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:])
+}
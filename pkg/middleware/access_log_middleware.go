@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/logging"
+	"application/pkg/requestid"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+var (
+	// DefaultAccessLogSkipPaths are the health and metrics routes polled by orchestrators.
+	DefaultAccessLogSkipPaths = []string{"/health", "/healthz", "/metrics"}
+	// DefaultAccessLogHeaders are the request headers written to the access log.
+	DefaultAccessLogHeaders = []string{"User-Agent"}
+
+	// sensitiveHeaders and sensitiveParams are always redacted.
+	sensitiveHeaders = []string{header, apiKeyHeader, "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	sensitiveParams  = []string{"token", "access_token", "refresh_token", "id_token", "code", "state", "password", "secret", "client_secret", "api_key", "apikey", "key", "signature"}
+)
+
+type AccessLogConfig struct {
+	// SkipPaths are not logged unless the request fails.
+	SkipPaths []string
+	// SampleRate is the share of successful requests logged, failed requests
+	// are always logged. Zero or above one logs every request.
+	SampleRate float64
+	// Headers are the request headers written to the log.
+	Headers []string
+	// Redact lists further header and query parameter names whose value is hidden.
+	Redact []string
+}
+
+// AccessLog writes one JSON entry per request through zerolog, replacing
+// gin.Logger. It must run after RequestId to log the request id.
+func AccessLog(config AccessLogConfig) gin.HandlerFunc {
+	if config.SkipPaths == nil {
+		config.SkipPaths = DefaultAccessLogSkipPaths
+	}
+	if config.Headers == nil {
+		config.Headers = DefaultAccessLogHeaders
+	}
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		config.SampleRate = 1
+	}
+
+	redact := make(map[string]bool)
+	for _, name := range slices.Concat(sensitiveHeaders, sensitiveParams, config.Redact) {
+		redact[strings.ToLower(name)] = true
+	}
+
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		failed := status >= http.StatusBadRequest
+		if !failed {
+			if slices.Contains(config.SkipPaths, ctx.Request.URL.Path) {
+				return
+			}
+			if config.SampleRate < 1 && rand.Float64() >= config.SampleRate {
+				return
+			}
+		}
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		level := zerolog.InfoLevel
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = zerolog.ErrorLevel
+		case failed:
+			level = zerolog.WarnLevel
+		}
+
+		event := requestid.Logger(ctx).WithLevel(level).
+			Str("method", ctx.Request.Method).
+			Str("route", route).
+			Str("path", ctx.Request.URL.Path).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Int("bytes", max(ctx.Writer.Size(), 0)).
+			Str("ip", ctx.ClientIP())
+
+		if query := redactQuery(ctx.Request.URL.RawQuery, redact); query != "" {
+			event = event.Str("query", query)
+		}
+
+		if claims, ok := ctx.Get(BearerToken); ok {
+			if token, ok := claims.(*pkgjwt.JwtResponse); ok && token != nil {
+				event = event.Str("user_id", token.Sub)
+			}
+		}
+		if client, ok := GetClient(ctx); ok {
+			event = event.Int64("client_id", client.Id).Str("client_scheme", client.Scheme)
+		}
+
+		headers, logged := zerolog.Dict(), false
+		for _, name := range config.Headers {
+			if value := ctx.GetHeader(name); value != "" {
+				if redact[strings.ToLower(name)] {
+					value = logging.Redacted
+				}
+				headers, logged = headers.Str(name, value), true
+			}
+		}
+		if logged {
+			event = event.Dict("headers", headers)
+		}
+
+		event.Msg("request")
+	}
+}
+
+// redactQuery hides the values of the sensitive query parameters.
+func redactQuery(rawQuery string, redact map[string]bool) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return logging.Redacted
+	}
+
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	parts := make([]string, 0, len(query))
+	for _, name := range names {
+		for _, value := range query[name] {
+			if redact[strings.ToLower(name)] {
+				parts = append(parts, url.QueryEscape(name)+"="+logging.Redacted)
+			} else {
+				parts = append(parts, url.QueryEscape(name)+"="+url.QueryEscape(value))
+			}
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
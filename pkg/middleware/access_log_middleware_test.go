@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// serveAccessLogged serves the request behind the access log and returns the
+// logged entries.
+func serveAccessLogged(t *testing.T, config AccessLogConfig, request *http.Request) []map[string]any {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var out bytes.Buffer
+	logger := log.Logger
+	log.Logger = zerolog.New(&out)
+	t.Cleanup(func() { log.Logger = logger })
+
+	router := gin.New()
+	router.Use(AccessLog(config))
+	router.GET("/orders/:id", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "order")
+	})
+	router.GET("/health", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	router.GET("/failing", func(ctx *gin.Context) {
+		ctx.Status(http.StatusInternalServerError)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestAccessLog(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/orders/42?page=2&token=secret-token", nil)
+	request.Header.Set("User-Agent", "k6")
+
+	entries := serveAccessLogged(t, AccessLogConfig{}, request)
+	if len(entries) != 1 {
+		t.Fatalf("entries = %v, want 1", entries)
+	}
+	entry := entries[0]
+
+	want := map[string]any{
+		"level":   "info",
+		"message": "request",
+		"method":  http.MethodGet,
+		"route":   "/orders/:id",
+		"path":    "/orders/42",
+		"status":  float64(http.StatusOK),
+		"bytes":   float64(len("order")),
+		"query":   "page=2&token=[REDACTED]",
+	}
+	for name, value := range want {
+		if entry[name] != value {
+			t.Errorf("entry %s = %v, want %v", name, entry[name], value)
+		}
+	}
+
+	headers, _ := entry["headers"].(map[string]any)
+	if headers["User-Agent"] != "k6" {
+		t.Errorf("entry headers = %v, want the user agent", entry["headers"])
+	}
+	if _, ok := entry["latency"]; !ok {
+		t.Error("entry has no latency")
+	}
+}
+
+func TestAccessLogRedactsHeaders(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/orders/42?Session=abc", nil)
+	request.Header.Set(header, "Bearer secret-token")
+	request.Header.Set("X-Tenant", "tenant-1")
+
+	entries := serveAccessLogged(t, AccessLogConfig{Headers: []string{header, "X-Tenant"}, Redact: []string{"session"}}, request)
+	if len(entries) != 1 {
+		t.Fatalf("entries = %v, want 1", entries)
+	}
+
+	headers, _ := entries[0]["headers"].(map[string]any)
+	if headers[header] != "[REDACTED]" || headers["X-Tenant"] != "tenant-1" {
+		t.Errorf("entry headers = %v", headers)
+	}
+	if entries[0]["query"] != "Session=[REDACTED]" {
+		t.Errorf("entry query = %v", entries[0]["query"])
+	}
+}
+
+func TestAccessLogSkipsAndSamples(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    AccessLogConfig
+		path      string
+		wantLevel string
+	}{
+		{name: "skipped health", path: "/health"},
+		{name: "custom skip path", config: AccessLogConfig{SkipPaths: []string{"/orders/42"}}, path: "/orders/42"},
+		{name: "health without skip paths", config: AccessLogConfig{SkipPaths: []string{}}, path: "/health", wantLevel: "info"},
+		{name: "failed requests are always logged", config: AccessLogConfig{SkipPaths: []string{"/failing"}, SampleRate: 1e-12}, path: "/failing", wantLevel: "error"},
+		{name: "unmatched route", path: "/unknown", wantLevel: "warn"},
+		{name: "sampled out", config: AccessLogConfig{SampleRate: 1e-12}, path: "/orders/42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := serveAccessLogged(t, tt.config, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if tt.wantLevel == "" {
+				if len(entries) != 0 {
+					t.Errorf("entries = %v, want none", entries)
+				}
+				return
+			}
+
+			if len(entries) != 1 || entries[0]["level"] != tt.wantLevel {
+				t.Errorf("entries = %v, want one at %s", entries, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	redact := map[string]bool{"token": true}
+
+	tests := []struct {
+		rawQuery string
+		want     string
+	}{
+		{rawQuery: "", want: ""},
+		{rawQuery: "b=2&a=1", want: "a=1&b=2"},
+		{rawQuery: "token=1&token=2", want: "token=[REDACTED]&token=[REDACTED]"},
+		{rawQuery: "q=a+b", want: "q=a+b"},
+		{rawQuery: "bad=%zz", want: "[REDACTED]"},
+	}
+
+	for _, tt := range tests {
+		if got := redactQuery(tt.rawQuery, redact); got != tt.want {
+			t.Errorf("redactQuery(%q) = %q, want %q", tt.rawQuery, got, tt.want)
+		}
+	}
+}
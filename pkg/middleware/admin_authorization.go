@@ -1,22 +1,76 @@
 package middleware
 
 import (
+	"application/app/enums"
 	pkgjwt "application/pkg/jwt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
+// AdminAuthorization only lets through bearer tokens carrying an admin role.
 func (a *Auth) AdminAuthorization() gin.HandlerFunc {
+	return a.RequireRole(enums.AdminRoles()...)
+}
+
+// RequireRole only lets through bearer tokens carrying any of the roles.
+func (a *Auth) RequireRole(roles ...enums.CodeAdminRole) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, ok := a.bearerClaims(ctx)
+		if !ok {
+			return
+		}
+
+		if !a.policy.HasRole(claims, roles...) {
+			log.Warn().Int64("id", claims.Id).Strs("roles", claims.Roles).Msg("[require role] forbidden")
+			abortWithStatus(ctx, http.StatusForbidden)
+			return
+		}
+
+		ctx.Set(Session, claims)
+		ctx.Next()
+	}
+}
+
+// RequirePermission only lets through bearer tokens granted every permission.
+func (a *Auth) RequirePermission(permissions ...enums.CodePermission) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		value, exists := ctx.Get(BearerToken)
-		if !exists {
-			ctx.AbortWithStatus(http.StatusUnauthorized)
+		claims, ok := a.bearerClaims(ctx)
+		if !ok {
+			return
 		}
 
-		response := value.(*pkgjwt.JwtResponse)
+		allowed, err := a.policy.Can(ctx, claims, permissions...)
+		if err != nil {
+			log.Error().Err(err).Msg("[require permission] failed to resolve permissions")
+			abortWithStatus(ctx, http.StatusInternalServerError)
+			return
+		}
 
-		ctx.Set(Session, response)
+		if !allowed {
+			log.Warn().Int64("id", claims.Id).Interface("permissions", permissions).Msg("[require permission] forbidden")
+			abortWithStatus(ctx, http.StatusForbidden)
+			return
+		}
+
+		ctx.Set(Session, claims)
 		ctx.Next()
 	}
 }
+
+func (a *Auth) bearerClaims(ctx *gin.Context) (*pkgjwt.JwtResponse, bool) {
+	value, exists := ctx.Get(BearerToken)
+	if !exists {
+		abortWithStatus(ctx, http.StatusUnauthorized)
+		return nil, false
+	}
+
+	claims, ok := value.(*pkgjwt.JwtResponse)
+	if !ok {
+		abortWithStatus(ctx, http.StatusUnauthorized)
+		return nil, false
+	}
+
+	return claims, true
+}
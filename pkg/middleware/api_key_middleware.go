@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"application/pkg/apikey"
+	"application/pkg/lockout"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// authenticateApiKey stores the client owning the key under Client. Failed
+// attempts count towards the lockout of the IP address.
+func (a *Auth) authenticateApiKey(ctx *gin.Context, value string) bool {
+	ipAddress := ctx.ClientIP()
+
+	// the key is verified either way, so a failing lockout check only logs as
+	// for basic auth instead of failing every partner call
+	var locked *lockout.LockedError
+	if err := a.lockout.Check(ctx, "", ipAddress); errors.As(err, &locked) {
+		ctx.Header("Retry-After", strconv.Itoa(locked.RetryAfter()))
+		abortWithStatus(ctx, http.StatusTooManyRequests)
+		return false
+	} else if err != nil {
+		log.Error().Err(err).Msg("[api key] failed to check lockout")
+	}
+
+	key, err := a.apiKeys.Authenticate(ctx, value)
+	if err != nil {
+		if !errors.Is(err, apikey.ErrInvalidKey) && !errors.Is(err, apikey.ErrKeyRevoked) && !errors.Is(err, apikey.ErrKeyExpired) {
+			log.Error().Err(err).Msg("[api key] failed to authenticate")
+			abortWithStatus(ctx, http.StatusInternalServerError)
+			return false
+		}
+
+		log.Warn().Err(err).Str("ip_address", ipAddress).Msg("[api key] rejected")
+		if _, err := a.lockout.Failure(ctx, "", ipAddress); err != nil {
+			log.Error().Err(err).Msg("[api key] failed to count failed attempt")
+		}
+
+		abortWithStatus(ctx, http.StatusUnauthorized)
+		return false
+	}
+
+	ctx.Set(Client, &AuthenticatedClient{
+		Id:            key.Id,
+		Name:          key.Name,
+		Scheme:        ApiKey,
+		Scopes:        key.Scopes,
+		RateLimitTier: key.RateLimitTier,
+	})
+
+	return true
+}
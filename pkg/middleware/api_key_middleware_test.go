@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"application/app/models"
+	"application/app/repositories/repositorytest"
+	"application/pkg/apikey"
+	"application/pkg/lockout"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newApiKeyTestRouter serves GET /orders behind the authentication middleware
+// and returns an active key of the tier PREMIUM.
+func newApiKeyTestRouter(t *testing.T, tables ...any) (*gin.Engine, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repo, db := repositorytest.Open(t, append(tables, &models.ApiKey{})...)
+
+	key, prefix, hash := apikey.Generate()
+	if err := db.Create(&models.ApiKey{Name: "partner", Prefix: prefix, KeyHash: hash, RateLimitTier: "PREMIUM"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	auth := &Auth{
+		lockout: lockout.NewGuard(repo, lockout.Config{IpThreshold: 2}),
+		apiKeys: apikey.NewStore(repo, 0),
+	}
+
+	router := gin.New()
+	router.GET("/orders", auth.Authentication(), func(ctx *gin.Context) {
+		client, _ := GetClient(ctx)
+		ctx.String(http.StatusOK, client.RateLimitTier)
+	})
+
+	return router, key
+}
+
+func apiKeyRequest(t *testing.T, router *gin.Engine, key string, ipAddress string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	request.RemoteAddr = ipAddress + ":1234"
+	request.Header.Set(apiKeyHeader, key)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestApiKeyAuthentication(t *testing.T) {
+	router, key := newApiKeyTestRouter(t, &models.LoginAttempt{})
+
+	recorder := apiKeyRequest(t, router, key, "10.0.0.1")
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "PREMIUM" {
+		t.Fatalf("response = %d %q, want the client with its tier", recorder.Code, recorder.Body.String())
+	}
+
+	for range 2 {
+		if code := apiKeyRequest(t, router, "ak_unknown1_secret", "10.0.0.2").Code; code != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want %d", code, http.StatusUnauthorized)
+		}
+	}
+
+	recorder = apiKeyRequest(t, router, key, "10.0.0.2")
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") == "" {
+		t.Errorf("status of a locked address = %d, want %d with Retry-After", recorder.Code, http.StatusTooManyRequests)
+	}
+
+	if code := apiKeyRequest(t, router, key, "10.0.0.1").Code; code != http.StatusOK {
+		t.Errorf("status of another address = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestApiKeyFailsOpenWhenTheLockoutFails(t *testing.T) {
+	// without the login_attempts table every lockout query fails
+	router, key := newApiKeyTestRouter(t)
+
+	if code := apiKeyRequest(t, router, key, "10.0.0.1").Code; code != http.StatusOK {
+		t.Errorf("status = %d, want %d", code, http.StatusOK)
+	}
+	if code := apiKeyRequest(t, router, "ak_unknown1_secret", "10.0.0.1").Code; code != http.StatusUnauthorized {
+		t.Errorf("status with an unknown key = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
@@ -2,10 +2,19 @@ package middleware
 
 import (
 	"application/app/repositories"
+	"application/app/services"
 	"application/config"
+	"application/pkg/apikey"
+	"application/pkg/audit"
+	"application/pkg/basicauth"
+	"application/pkg/i18n"
 	pkgjwt "application/pkg/jwt"
+	"application/pkg/lockout"
+	"application/pkg/oauth"
+	"application/pkg/rbac"
+	"application/pkg/revocation"
+	"application/pkg/signature"
 	"context"
-	"fmt"
 	"net/http"
 	"strings"
 
@@ -14,48 +23,57 @@ import (
 )
 
 type Auth struct {
-	cfg     *config.Config
-	adapter *pkgjwt.JwtAdapter
-	repo    *repositories.RepositoryContext
+	cfg          *config.Config
+	adapter      *pkgjwt.JwtAdapter
+	revocations  *revocation.Store
+	policy       *rbac.Policy
+	lockout      *lockout.Guard
+	audit        *audit.Recorder
+	basicClients *basicauth.Store
+	apiKeys      *apikey.Store
+	signatures   *signature.Verifier
+	oauthClients *oauth.Store
+	repo         *repositories.RepositoryContext
 }
 
-func NewAuth(cfg *config.Config, repo *repositories.RepositoryContext) *Auth {
-	adapter := pkgjwt.NewJwtAdapter(cfg.JwtIssuer, cfg.JwtSecret)
+func NewAuth(cfg *config.Config, repo *repositories.RepositoryContext, deps *services.Dependencies) *Auth {
 	return &Auth{
-		cfg:     cfg,
-		adapter: adapter,
-		repo:    repo,
+		cfg:          cfg,
+		adapter:      deps.Jwt,
+		revocations:  deps.Revocation,
+		policy:       deps.Policy,
+		lockout:      deps.Lockout,
+		audit:        deps.Audit,
+		basicClients: deps.BasicClients,
+		apiKeys:      deps.ApiKeys,
+		signatures:   deps.Signatures,
+		oauthClients: deps.OAuthClients,
+		repo:         repo,
 	}
 }
 
 func (a *Auth) Authentication() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authType, v := a.getAuthorizationHeaderValue(ctx, header)
-		log.Info().Msg(fmt.Sprintf("[authentication] auth type = [%v] with value = [%v]", authType, v))
+		if key := ctx.GetHeader(apiKeyHeader); key != "" {
+			authType, v = ApiKey, key
+		}
 		if authType == "" && v == "" {
-			ctx.AbortWithStatus(http.StatusUnauthorized)
+			abortWithStatus(ctx, http.StatusUnauthorized)
 			return
 		}
 
 		switch authType {
 		case Basic:
 			ctxAuth := a.getValueBasicAuth(ctx, v)
-			log.Debug().Msg(fmt.Sprintf("[case basic] [get value basic auth] contect auth = [%v]", ctxAuth))
 
 			basicAuth := a.getBasicAuth(ctxAuth)
 			if basicAuth == nil {
-				log.Warn().Msg(fmt.Sprintf("[case basic] [get value basic auth] basic auth = [%v]", basicAuth))
-				ctx.AbortWithStatus(http.StatusUnauthorized)
+				abortWithStatus(ctx, http.StatusUnauthorized)
 				return
 			}
 
-			match := a.matchUsernamePassword(basicAuth)
-			if !match {
-				log.Warn().Msg(fmt.Sprintf("[case basic] [match username password value basic auth] match = [%v] with context = [%v] ",
-					match,
-					basicAuth),
-				)
-				ctx.AbortWithStatus(http.StatusUnauthorized)
+			if !a.authenticateBasic(ctx, basicAuth) {
 				return
 			}
 			ctx.Next()
@@ -65,20 +83,40 @@ func (a *Auth) Authentication() gin.HandlerFunc {
 			token := a.getBearerToken(ctxToken)
 
 			if token == "" {
-				ctx.AbortWithStatus(http.StatusUnauthorized)
+				abortWithStatus(ctx, http.StatusUnauthorized)
+				return
+			}
+
+			if pkgjwt.TokenUse(token) == pkgjwt.TokenUseClientCredentials {
+				client := a.clientTokenValidation(ctx, token)
+				if client == nil {
+					abortWithStatus(ctx, http.StatusUnauthorized)
+					return
+				}
+
+				ctx.Set(Client, client)
+				ctx.Next()
 				return
 			}
 
 			validation := a.tokenValidation(token)
 			if validation == nil {
-				ctx.AbortWithStatus(http.StatusUnauthorized)
+				abortWithStatus(ctx, http.StatusUnauthorized)
 				return
 			}
 
 			ctx.Set(BearerToken, validation)
+			if validation.Locale != "" {
+				ctx.Set(i18n.LocaleKey, validation.Locale)
+			}
+			ctx.Next()
+		case ApiKey:
+			if !a.authenticateApiKey(ctx, strings.TrimSpace(v)) {
+				return
+			}
 			ctx.Next()
 		default:
-			ctx.AbortWithStatus(http.StatusUnauthorized)
+			abortWithStatus(ctx, http.StatusUnauthorized)
 			return
 		}
 	}
@@ -86,7 +124,6 @@ func (a *Auth) Authentication() gin.HandlerFunc {
 
 func (a *Auth) getAuthorizationHeaderValue(ctx *gin.Context, header string) (authType string, value string) {
 	getHeader := ctx.GetHeader(header)
-	log.Info().Msg(fmt.Sprintf("[get authorization header value] with value = [%v]", getHeader))
 	if getHeader == "" {
 		return "", ""
 	}
@@ -100,8 +137,6 @@ func (a *Auth) getAuthorizationHeaderValue(ctx *gin.Context, header string) (aut
 }
 
 func (a *Auth) getValueBearerToken(ctx context.Context, token string) context.Context {
-	log.Info().Str("token", token).Msg(fmt.Sprintf("[get value bearer token] with value = [%v]", token))
-
 	if token == "" {
 		return ctx
 	}
@@ -110,8 +145,7 @@ func (a *Auth) getValueBearerToken(ctx context.Context, token string) context.Co
 }
 
 func (a *Auth) getBearerToken(ctx context.Context) string {
-	s := ctx.Value(Bearer).(string)
-	log.Info().Str("s", s).Msg(fmt.Sprintf("[get value bearer token] with value = [%v]", s))
+	s, _ := ctx.Value(Bearer).(string)
 
 	return s
 }
@@ -123,5 +157,10 @@ func (a *Auth) tokenValidation(token string) *pkgjwt.JwtResponse {
 		return nil
 	}
 
+	if a.revocations.IsRevoked(jwt.Jti, jwt.Id, jwt.IssuedAt) {
+		log.Warn().Str("jti", jwt.Jti).Int64("id", jwt.Id).Msg("revoked jwt token")
+		return nil
+	}
+
 	return jwt
 }
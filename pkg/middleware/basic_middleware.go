@@ -1,13 +1,25 @@
 package middleware
 
 import (
+	"application/app/enums"
+	"application/pkg/audit"
+	"application/pkg/basicauth"
+	"application/pkg/lockout"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
 	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
+// basicAccountPrefix keeps basic auth usernames apart from admin usernames in the lockout counters.
+const basicAccountPrefix = "basic:"
+
 type BasicAuthRequest struct {
 	Username string
 	Password string
@@ -15,7 +27,6 @@ type BasicAuthRequest struct {
 
 func (a *Auth) getBasicAuth(ctx context.Context) *BasicAuthRequest {
 	value, ok := ctx.Value(BasicAuth).(BasicAuthRequest)
-	log.Debug().Msg(fmt.Sprintf("[get basic auth] value = [%v] and ok = [%v]", value, ok))
 	if !ok {
 		return nil
 	}
@@ -23,19 +34,7 @@ func (a *Auth) getBasicAuth(ctx context.Context) *BasicAuthRequest {
 	return &value
 }
 
-func (a *Auth) matchUsernamePassword(basicAuth *BasicAuthRequest) bool {
-	if basicAuth.Username == "" || basicAuth.Password == "" {
-		return false
-	} else if basicAuth.Username != a.cfg.BasicAuthUsername || basicAuth.Password != a.cfg.BasicAuthPassword {
-		return false
-	} else {
-		return true
-	}
-}
-
 func (a *Auth) getValueBasicAuth(ctx context.Context, v string) context.Context {
-	log.Debug().Msg(fmt.Sprintf("[get value basic auth] value v string = [%s]", v))
-
 	decodeString, err := base64.StdEncoding.DecodeString(v)
 	if err != nil {
 		log.Error().Msg(fmt.Sprintf("[decode string base 64] [get value basic auth] error = [%v]", err))
@@ -43,10 +42,8 @@ func (a *Auth) getValueBasicAuth(ctx context.Context, v string) context.Context
 	}
 
 	valueString := string(decodeString)
-	log.Debug().Msg(fmt.Sprintf("[get value basic auth] value string (decode string) = [%s]", valueString))
 
 	indexByte := strings.IndexByte(valueString, ':')
-	log.Debug().Msg(fmt.Sprintf("[get value basic auth] index byte (value string) = [%v]", valueString))
 	if indexByte < 0 {
 		return ctx
 	}
@@ -55,3 +52,80 @@ func (a *Auth) getValueBasicAuth(ctx context.Context, v string) context.Context
 
 	return context.WithValue(ctx, BasicAuth, BasicAuthRequest{username, password})
 }
+
+// authenticateBasic authenticates the client behind the brute-force guard and
+// stores it under Client. It aborts the request and returns false when the
+// credentials are rejected or the client may not call the route. Failures are
+// counted per client name only, since many services may share an IP address.
+func (a *Auth) authenticateBasic(ctx *gin.Context, basicAuth *BasicAuthRequest) bool {
+	account := basicAccountPrefix + strings.ToLower(basicAuth.Username)
+	ipAddress := ctx.ClientIP()
+
+	// the credentials are verified either way, so a failing lockout check only
+	// logs instead of failing every service-to-service call
+	var locked *lockout.LockedError
+	if err := a.lockout.Check(ctx, account, ""); errors.As(err, &locked) {
+		a.audit.Record(ctx, audit.Event{
+			Name:      enums.AuditEventLockedLoginDenied,
+			Actor:     account,
+			IpAddress: ipAddress,
+			Metadata:  map[string]any{"scope": locked.Scope, "locked_until": locked.Until},
+		})
+		ctx.Header("Retry-After", strconv.Itoa(locked.RetryAfter()))
+		abortWithStatus(ctx, http.StatusTooManyRequests)
+		return false
+	} else if err != nil {
+		log.Error().Err(err).Msg("[basic auth] failed to check lockout")
+	}
+
+	client, err := a.basicClients.Authenticate(ctx, basicAuth.Username, basicAuth.Password)
+	if err != nil && !errors.Is(err, basicauth.ErrInvalidCredentials) && !errors.Is(err, basicauth.ErrClientDisabled) {
+		log.Error().Err(err).Msg("[basic auth] failed to authenticate client")
+		abortWithStatus(ctx, http.StatusInternalServerError)
+		return false
+	}
+
+	if err != nil {
+		a.audit.Record(ctx, audit.Event{
+			Name:      enums.AuditEventBasicAuthFailed,
+			Actor:     account,
+			IpAddress: ipAddress,
+			Metadata:  map[string]any{"reason": err.Error()},
+		})
+
+		locked, err := a.lockout.Failure(ctx, account, "")
+		if err != nil {
+			log.Error().Err(err).Msg("[basic auth] failed to count failed attempt")
+		}
+		for _, lock := range locked {
+			a.audit.Record(ctx, audit.Event{
+				Name:      enums.AuditEventAccountLocked,
+				Actor:     account,
+				IpAddress: ipAddress,
+				Metadata:  map[string]any{"locked_until": lock.Until},
+			})
+		}
+
+		abortWithStatus(ctx, http.StatusUnauthorized)
+		return false
+	}
+
+	if err := a.lockout.Success(ctx, account); err != nil {
+		log.Error().Err(err).Msg("[basic auth] failed to reset failed attempts")
+	}
+
+	if !basicauth.AllowsRoute(client, ctx.Request.Method, ctx.FullPath()) {
+		log.Warn().Str("client", client.Name).Str("route", ctx.FullPath()).Msg("[basic auth] route not allowed")
+		abortWithStatus(ctx, http.StatusForbidden)
+		return false
+	}
+
+	ctx.Set(Client, &AuthenticatedClient{
+		Id:     client.Id,
+		Name:   client.Name,
+		Scheme: Basic,
+		Scopes: client.Scopes,
+	})
+
+	return true
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"application/app/models"
+	"application/app/repositories/repositorytest"
+	"application/pkg/audit"
+	"application/pkg/basicauth"
+	"application/pkg/lockout"
+	"application/pkg/util"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testClientKey = []byte("client-secret-key")
+
+// newBasicTestRouter serves GET /orders behind the authentication middleware
+// with the basic auth clients "orders" and "billing" on an in-memory database.
+func newBasicTestRouter(t *testing.T, tables ...any) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repo, db := repositorytest.Open(t, append(tables, &models.BasicAuthClient{}, &models.AuditEvent{})...)
+	for _, name := range []string{"orders", "billing"} {
+		client := &models.BasicAuthClient{Name: name, SecretHash: util.HashSecret(testClientKey, name+"-secret"), IsEnabled: true}
+		if err := db.Create(client).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	auth := &Auth{
+		lockout:      lockout.NewGuard(repo, lockout.Config{Threshold: 2, IpThreshold: 2}),
+		audit:        audit.NewRecorder(repo),
+		basicClients: basicauth.NewStore(repo, testClientKey, 0),
+	}
+
+	router := gin.New()
+	router.GET("/orders", auth.Authentication(), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	return router
+}
+
+func basicRequest(t *testing.T, router *gin.Engine, name string, secret string, ipAddress string) int {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	request.RemoteAddr = ipAddress + ":1234"
+	request.Header.Set(header, Basic+" "+base64.StdEncoding.EncodeToString([]byte(name+":"+secret)))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder.Code
+}
+
+func TestBasicAuthLocksTheClientNotTheAddress(t *testing.T) {
+	router := newBasicTestRouter(t, &models.LoginAttempt{})
+
+	for range 2 {
+		if code := basicRequest(t, router, "orders", "wrong", "10.0.0.1"); code != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want %d", code, http.StatusUnauthorized)
+		}
+	}
+
+	// the client is locked from every address
+	if code := basicRequest(t, router, "orders", "orders-secret", "10.0.0.2"); code != http.StatusTooManyRequests {
+		t.Errorf("status of the locked client = %d, want %d", code, http.StatusTooManyRequests)
+	}
+
+	// another service behind the same address is not
+	if code := basicRequest(t, router, "billing", "billing-secret", "10.0.0.1"); code != http.StatusOK {
+		t.Errorf("status of another client on the address = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestBasicAuthFailsOpenWhenTheLockoutFails(t *testing.T) {
+	// without the login_attempts table every lockout query fails
+	router := newBasicTestRouter(t)
+
+	if code := basicRequest(t, router, "orders", "orders-secret", "10.0.0.1"); code != http.StatusOK {
+		t.Errorf("status = %d, want %d", code, http.StatusOK)
+	}
+	if code := basicRequest(t, router, "orders", "wrong", "10.0.0.1"); code != http.StatusUnauthorized {
+		t.Errorf("status with a wrong secret = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
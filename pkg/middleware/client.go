@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"application/pkg/rbac"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// AuthenticatedClient is the service or partner that authenticated the request.
+type AuthenticatedClient struct {
+	Id     int64    `json:"id"`
+	Name   string   `json:"name"`
+	Scheme string   `json:"scheme"`
+	Scopes []string `json:"scopes"`
+	// RateLimitTier is set for API keys. It is metadata only, requests are not
+	// limited by it.
+	RateLimitTier string `json:"rateLimitTier,omitempty"`
+}
+
+// GetClient returns the client stored under Client by the authentication middleware.
+func GetClient(ctx *gin.Context) (*AuthenticatedClient, bool) {
+	value, exists := ctx.Get(Client)
+	if !exists {
+		return nil, false
+	}
+
+	client, ok := value.(*AuthenticatedClient)
+	return client, ok
+}
+
+// RequireScope only lets through clients granted every scope: the scopes of a
+// basic auth client or an API key, or the scopes of an OAuth2 access token.
+// Scopes follow the "resource:action" format of permissions, wildcards included.
+func (a *Auth) RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		client, ok := GetClient(ctx)
+		if !ok {
+			abortWithStatus(ctx, http.StatusUnauthorized)
+			return
+		}
+
+		for _, scope := range scopes {
+			if !rbac.Matches(client.Scopes, scope) {
+				log.Warn().Str("client", client.Name).Str("scope", scope).Msg("[require scope] forbidden")
+				abortWithStatus(ctx, http.StatusForbidden)
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
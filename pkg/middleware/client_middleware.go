@@ -1,12 +1,86 @@
 package middleware
 
 import (
+	"application/pkg/signature"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
+// maxSignedBodyBytes bounds the body read into memory to verify its hash.
+const maxSignedBodyBytes = 10 << 20
+
+// ClientMiddleware authenticates partner clients signing their requests with
+// HMAC-SHA256 and stores the client under Client.
 func (a *Auth) ClientMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		if a.signatures == nil {
+			log.Error().Msg("[client middleware] request signing is not configured")
+			abortWithStatus(ctx, http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxSignedBodyBytes+1))
+		if err != nil {
+			abortWithStatus(ctx, http.StatusBadRequest)
+			return
+		}
+		if len(body) > maxSignedBodyBytes {
+			abortWithStatus(ctx, http.StatusRequestEntityTooLarge)
+			return
+		}
+		// handlers read the body again
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		client, err := a.signatures.Verify(ctx, &signature.Request{
+			ClientId:  ctx.GetHeader(signature.HeaderClientId),
+			Timestamp: ctx.GetHeader(signature.HeaderTimestamp),
+			Nonce:     ctx.GetHeader(signature.HeaderNonce),
+			Signature: ctx.GetHeader(signature.HeaderSignature),
+			Method:    ctx.Request.Method,
+			Path:      ctx.Request.URL.RequestURI(),
+			Body:      body,
+		})
+		if err != nil {
+			if isSignatureRejection(err) {
+				log.Warn().Err(err).Str("client_id", ctx.GetHeader(signature.HeaderClientId)).Msg("[client middleware] signature rejected")
+				abortWithStatus(ctx, http.StatusUnauthorized)
+				return
+			}
+
+			log.Error().Err(err).Msg("[client middleware] failed to verify signature")
+			abortWithStatus(ctx, http.StatusInternalServerError)
+			return
+		}
 
+		ctx.Set(Client, &AuthenticatedClient{
+			Id:     client.Id,
+			Name:   client.ClientId,
+			Scheme: Signature,
+			Scopes: client.Scopes,
+		})
 		ctx.Next()
 	}
 }
+
+func isSignatureRejection(err error) bool {
+	for _, rejection := range []error{
+		signature.ErrMissingHeaders,
+		signature.ErrUnknownClient,
+		signature.ErrClientDisabled,
+		signature.ErrTimestampOutOfWindow,
+		signature.ErrInvalidNonce,
+		signature.ErrReplayedNonce,
+		signature.ErrInvalidSignature,
+	} {
+		if errors.Is(err, rejection) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"application/app/models"
+	"application/app/repositories/repositorytest"
+	"application/pkg/secretbox"
+	"application/pkg/signature"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testSigningSecret = []byte("partner-secret")
+
+// newSignedTestRouter serves POST /callback behind the client middleware with
+// the signing client "acme", granted payments:callback, and "reports". The
+// handler echoes the body it reads.
+func newSignedTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	box, err := secretbox.NewBox(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := box.Seal(testSigningSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, db := repositorytest.Open(t, &models.SigningClient{}, &models.RequestNonce{})
+	clients := []*models.SigningClient{
+		{ClientId: "acme", SecretCiphertext: ciphertext, Scopes: models.StringList{signature.ScopePaymentCallback}, IsEnabled: true},
+		{ClientId: "reports", SecretCiphertext: ciphertext, Scopes: models.StringList{"reports:read"}, IsEnabled: true},
+	}
+	if err := db.Create(clients).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	auth := &Auth{signatures: signature.NewVerifier(repo, box, time.Minute)}
+
+	router := gin.New()
+	router.POST("/callback", auth.ClientMiddleware(), auth.RequireScope(signature.ScopePaymentCallback), func(ctx *gin.Context) {
+		client, _ := GetClient(ctx)
+		body, _ := io.ReadAll(ctx.Request.Body)
+		ctx.String(http.StatusOK, client.Name+" "+client.Scheme+" "+string(body))
+	})
+
+	return router
+}
+
+func signedRequest(clientId string, nonce string, body string) *http.Request {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	request := httptest.NewRequest(http.MethodPost, "/callback", bytes.NewBufferString(body))
+	request.Header.Set(signature.HeaderClientId, clientId)
+	request.Header.Set(signature.HeaderTimestamp, timestamp)
+	request.Header.Set(signature.HeaderNonce, nonce)
+	request.Header.Set(signature.HeaderSignature, signature.Sign(testSigningSecret, signature.Canonical(http.MethodPost, "/callback", timestamp, nonce, []byte(body))))
+
+	return request
+}
+
+func TestClientMiddleware(t *testing.T) {
+	router := newSignedTestRouter(t)
+
+	unsigned := httptest.NewRequest(http.MethodPost, "/callback", bytes.NewBufferString("{}"))
+	tampered := signedRequest("acme", "tampered", `{"status":"FAILED"}`)
+	tampered.Body = io.NopCloser(bytes.NewBufferString(`{"status":"PAID"}`))
+
+	tests := []struct {
+		name    string
+		request *http.Request
+		want    int
+	}{
+		{"signed", signedRequest("acme", "first", `{"status":"PAID"}`), http.StatusOK},
+		{"replayed", signedRequest("acme", "first", `{"status":"PAID"}`), http.StatusUnauthorized},
+		{"unsigned", unsigned, http.StatusUnauthorized},
+		{"tampered body", tampered, http.StatusUnauthorized},
+		{"unknown client", signedRequest("unknown", "second", "{}"), http.StatusUnauthorized},
+		{"scope not granted", signedRequest("reports", "third", "{}"), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, tt.request)
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientMiddlewareRestoresTheBody(t *testing.T) {
+	router := newSignedTestRouter(t)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, signedRequest("acme", "nonce", `{"status":"PAID"}`))
+
+	if want := `acme Signature {"status":"PAID"}`; recorder.Body.String() != want {
+		t.Errorf("body = %q, want %q", recorder.Body.String(), want)
+	}
+}
+
+func TestClientMiddlewareRejectsLargeBodies(t *testing.T) {
+	router := newSignedTestRouter(t)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, signedRequest("acme", "nonce", string(make([]byte, maxSignedBodyBytes+1))))
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestClientMiddlewareWithoutSigning(t *testing.T) {
+	auth := &Auth{}
+	router := gin.New()
+	router.POST("/callback", auth.ClientMiddleware(), func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, signedRequest("acme", "nonce", "{}"))
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusServiceUnavailable)
+	}
+}
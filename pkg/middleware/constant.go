@@ -1,11 +1,15 @@
 package middleware
 
 const (
-	header      = "Authorization"
-	Basic       = "Basic"
-	BasicAuth   = "BasicAuth"
-	Bearer      = "Bearer"
-	BearerToken = "BearerToken"
-	Session     = "Session"
-	Client      = "Client"
+	header       = "Authorization"
+	apiKeyHeader = "X-API-Key"
+	Basic        = "Basic"
+	BasicAuth    = "BasicAuth"
+	Bearer       = "Bearer"
+	BearerToken  = "BearerToken"
+	ApiKey       = "ApiKey"
+	Signature    = "Signature"
+	OAuth        = "OAuth"
+	Session      = "Session"
+	Client       = "Client"
 )
@@ -1,67 +1,53 @@
 package middleware
 
 import (
-	"errors"
+	"application/app/enums"
+	apperror "application/app/error"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
 )
 
-type ErrorTrace struct {
-	Err        error  `json:"-"`
-	Message    string `json:"message"`
-	StatusCode int    `json:"statusCode"`
-}
-
-func NewErrorTrace(err error) *ErrorTrace {
-	return &ErrorTrace{
-		Err:        err,
-		Message:    err.Error(),
-		StatusCode: http.StatusInternalServerError,
-	}
-}
+var (
+	ErrAuthenticationRequired = apperror.New(enums.ErrorCodeAuthenticationRequired, "authentication required")
+	ErrAccessDenied           = apperror.New(enums.ErrorCodeAccessDenied, "access denied")
+	ErrTooManyAttempts        = apperror.New(enums.ErrorCodeAccountLocked, "too many failed attempts")
+)
 
-func (e *ErrorTrace) Error() string {
-	return e.Message
-}
+// ErrorHandler answers with the last error a handler added with ctx.Error, in
+// the same envelope as apperror.ErrorResponse. Handlers that already wrote a
+// response are left alone.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
 
-func (e *ErrorTrace) Unwrap() error {
-	return e.Err
-}
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
 
-func (e *ErrorTrace) SetStatusCode(code int) *ErrorTrace {
-	e.StatusCode = code
-	return e
+		apperror.ErrorResponse(c, c.Errors.Last().Err)
+	}
 }
 
-func HandleError(c *gin.Context, err error) {
-	log.Error().Err(err).Msg(err.Error())
-
-	var trace *ErrorTrace
-	if errors.As(err, &trace) {
-		log.Error().Err(err).Int("http-status", trace.StatusCode).Msg(trace.Message)
-		c.JSON(trace.StatusCode, gin.H{
-			"success": false,
-			"error":   trace,
-		})
-		return
+// abortWithStatus ends a request the middleware refused with the error
+// envelope and the catalog message of the status.
+func abortWithStatus(ctx *gin.Context, status int) {
+	var err error
+	switch status {
+	case http.StatusUnauthorized:
+		err = ErrAuthenticationRequired
+	case http.StatusForbidden:
+		err = ErrAccessDenied
+	case http.StatusTooManyRequests:
+		err = ErrTooManyAttempts
+	default:
+		err = apperror.New(enums.ErrorCodeForStatus(status), http.StatusText(status))
 	}
 
-	c.JSON(http.StatusInternalServerError, gin.H{
-		"success": false,
-		"error": gin.H{
-			"message": "Internal server error",
-		},
-	})
-}
-
-func ErrorHandler() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Next()
-		if len(c.Errors) > 0 {
-			HandleError(c, c.Errors.Last().Err)
-			c.Abort()
-		}
+	trace := apperror.NewErrorTrace(err, "middleware").Status(status)
+	if retryAfter := ctx.Writer.Header().Get("Retry-After"); retryAfter != "" {
+		trace.WithParams(map[string]any{"retryAfter": retryAfter})
 	}
+
+	apperror.ErrorResponse(ctx, trace)
 }
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	apperror "application/app/error"
+	"application/app/web"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/missing", func(ctx *gin.Context) {
+		_ = ctx.Error(apperror.NewErrorTrace(errors.New("order not found"), "find order").Status(http.StatusNotFound))
+	})
+	router.GET("/written", func(ctx *gin.Context) {
+		_ = ctx.Error(errors.New("logged only"))
+		ctx.JSON(http.StatusAccepted, web.ResponseWeb{Success: true, Message: "accepted"})
+	})
+
+	tests := []struct {
+		path        string
+		wantStatus  int
+		wantMessage string
+		wantCode    string
+	}{
+		{path: "/missing", wantStatus: http.StatusNotFound, wantMessage: "order not found", wantCode: "NOT_FOUND"},
+		{path: "/written", wantStatus: http.StatusAccepted, wantMessage: "accepted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			var response web.ResponseWeb
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+
+			if recorder.Code != tt.wantStatus || response.Message != tt.wantMessage || response.Code != tt.wantCode {
+				t.Errorf("response = %d %+v, want %d %q %q", recorder.Code, response, tt.wantStatus, tt.wantMessage, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"application/pkg/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics counts the requests and observes their duration by method, route
+// template and status. Unmatched paths share one route to bound the series.
+func Metrics() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(ctx.Writer.Status())
+
+		metrics.HttpRequests.WithLabelValues(ctx.Request.Method, route, status).Inc()
+		metrics.HttpDuration.WithLabelValues(ctx.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
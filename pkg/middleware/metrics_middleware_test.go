@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"application/pkg/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsLabels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/orders/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	router.POST("/orders/:id/pay", func(ctx *gin.Context) {
+		ctx.Status(http.StatusConflict)
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		route  string
+		status string
+	}{
+		{method: http.MethodGet, path: "/orders/42", route: "/orders/:id", status: "200"},
+		{method: http.MethodPost, path: "/orders/42/pay", route: "/orders/:id/pay", status: "409"},
+		{method: http.MethodGet, path: "/wp-login.php", route: "unmatched", status: "404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			requests := metrics.HttpRequests.WithLabelValues(tt.method, tt.route, tt.status)
+			before := testutil.ToFloat64(requests)
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+			if got := testutil.ToFloat64(requests) - before; got != 1 {
+				t.Errorf("requests{%s %s %s} = %v, want 1", tt.method, tt.route, tt.status, got)
+			}
+		})
+	}
+
+	// the raw path must never become a label value
+	if got := testutil.ToFloat64(metrics.HttpRequests.WithLabelValues(http.MethodGet, "/orders/42", "200")); got != 0 {
+		t.Errorf("requests by raw path = %v, want 0", got)
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// clientTokenValidation returns the OAuth2 client of a client credentials
+// token, or nil when the token is invalid, revoked or its client disabled.
+func (a *Auth) clientTokenValidation(ctx *gin.Context, token string) *AuthenticatedClient {
+	claims, err := a.adapter.VerifyClientJwt(token)
+	if err != nil {
+		log.Error().Err(err).Msg("verify client jwt token error")
+		return nil
+	}
+
+	if a.revocations.IsRevoked(claims.Jti, 0, claims.IssuedAt) {
+		log.Warn().Str("jti", claims.Jti).Str("client_id", claims.ClientId).Msg("revoked client jwt token")
+		return nil
+	}
+
+	client, err := a.oauthClients.ActiveClient(ctx, claims.ClientId)
+	if err != nil {
+		log.Error().Err(err).Str("client_id", claims.ClientId).Msg("failed to look up oauth client")
+		return nil
+	}
+
+	if client == nil {
+		log.Warn().Str("client_id", claims.ClientId).Msg("oauth client is disabled")
+		return nil
+	}
+
+	return &AuthenticatedClient{
+		Id:     client.Id,
+		Name:   client.ClientId,
+		Scheme: OAuth,
+		Scopes: claims.Scopes,
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"application/app/enums"
+	apperror "application/app/error"
+	"application/pkg/errorsink"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/metrics"
+	"application/pkg/requestid"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrPanic is answered for a request whose handler panicked.
+var ErrPanic = apperror.New(enums.ErrorCodeInternal, "internal server error")
+
+// Recovery replaces gin.Recovery: the panic is logged with its stack, counted,
+// reported to the sink when one is configured and answered with the error
+// envelope. A client that went away is not answered.
+func Recovery(sink errorsink.Sink, node string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			route := ctx.FullPath()
+			if route == "" {
+				route = "unmatched"
+			}
+			metrics.Panics.WithLabelValues(route).Inc()
+
+			stack := string(debug.Stack())
+			event := &errorsink.Event{
+				Time:      time.Now(),
+				Message:   fmt.Sprint(recovered),
+				Stack:     stack,
+				RequestId: apperror.RequestId(ctx),
+				Method:    ctx.Request.Method,
+				Route:     route,
+				Path:      ctx.Request.URL.Path,
+				User:      principal(ctx),
+				Node:      node,
+			}
+
+			logger := requestid.Logger(ctx)
+			logger.Error().
+				Str("method", event.Method).
+				Str("route", event.Route).
+				Str("user", event.User).
+				Str("panic", event.Message).
+				Str("stack", stack).
+				Msg("[recovery] panic recovered")
+
+			if sink != nil {
+				if err := sink.Report(context.WithoutCancel(ctx), event); err != nil {
+					logger.Error().Err(err).Msg("[recovery] failed to report panic")
+				}
+			}
+
+			if brokenPipe(recovered) {
+				_ = ctx.Error(fmt.Errorf("%v", recovered))
+				ctx.Abort()
+				return
+			}
+
+			if ctx.Writer.Written() {
+				ctx.Abort()
+				return
+			}
+
+			apperror.ErrorResponse(ctx, apperror.NewErrorTrace(ErrPanic, "recovery"))
+		}()
+
+		ctx.Next()
+	}
+}
+
+// principal names the authenticated user or client of the request, if any.
+func principal(ctx *gin.Context) string {
+	if value, ok := ctx.Get(BearerToken); ok {
+		if claims, ok := value.(*pkgjwt.JwtResponse); ok && claims != nil {
+			return "user:" + claims.Sub
+		}
+	}
+
+	if client, ok := GetClient(ctx); ok {
+		return strings.ToLower(client.Scheme) + ":" + client.Name
+	}
+
+	return ""
+}
+
+// brokenPipe tells whether the panic comes from writing to a closed connection.
+func brokenPipe(recovered any) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+
+	var syscallErr *os.SyscallError
+	if errors.As(opErr, &syscallErr) {
+		return errors.Is(syscallErr.Err, syscall.EPIPE) || errors.Is(syscallErr.Err, syscall.ECONNRESET)
+	}
+
+	return false
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"application/app/web"
+	"application/pkg/errorsink"
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/metrics"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type memorySink struct {
+	mu     sync.Mutex
+	events []*errorsink.Event
+}
+
+func (s *memorySink) Report(_ context.Context, event *errorsink.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+	return nil
+}
+
+func newRecoveryTestRouter(sink errorsink.Sink) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Recovery(sink, "node-1"))
+	router.GET("/orders/:id", func(ctx *gin.Context) {
+		ctx.Set(BearerToken, &pkgjwt.JwtResponse{Sub: "admin"})
+		panic("nil map")
+	})
+	router.GET("/written", func(ctx *gin.Context) {
+		ctx.String(http.StatusAccepted, "accepted")
+		panic("after the response")
+	})
+	router.GET("/broken", func(ctx *gin.Context) {
+		panic(&net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EPIPE)})
+	})
+
+	return router
+}
+
+func TestRecovery(t *testing.T) {
+	sink := &memorySink{}
+	router := newRecoveryTestRouter(sink)
+	before := testutil.ToFloat64(metrics.Panics.WithLabelValues("/orders/:id"))
+
+	request := httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+	request.Header.Set("X-Request-ID", "request-1")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	var response web.ResponseWeb
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Code != http.StatusInternalServerError || response.Code != "INTERNAL_ERROR" || response.Message != "internal server error" {
+		t.Errorf("response = %d %+v, want an internal error", recorder.Code, response)
+	}
+
+	if got := testutil.ToFloat64(metrics.Panics.WithLabelValues("/orders/:id")) - before; got != 1 {
+		t.Errorf("panics of the route = %v, want 1", got)
+	}
+
+	if len(sink.events) != 1 {
+		t.Fatalf("reported events = %d, want 1", len(sink.events))
+	}
+	event := sink.events[0]
+	if event.Message != "nil map" || event.Route != "/orders/:id" || event.Path != "/orders/42" || event.Method != http.MethodGet ||
+		event.RequestId != "request-1" || event.User != "user:admin" || event.Node != "node-1" || event.Stack == "" {
+		t.Errorf("event = %+v", event)
+	}
+}
+
+func TestRecoveryKeepsTheWrittenResponse(t *testing.T) {
+	router := newRecoveryTestRouter(nil)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/written", nil))
+
+	if recorder.Code != http.StatusAccepted || recorder.Body.String() != "accepted" {
+		t.Errorf("response = %d %q, want the written response", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestRecoveryOfABrokenPipe(t *testing.T) {
+	sink := &memorySink{}
+	router := newRecoveryTestRouter(sink)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/broken", nil))
+
+	if recorder.Body.Len() != 0 {
+		t.Errorf("body = %q, want no answer to a client that went away", recorder.Body.String())
+	}
+	if len(sink.events) != 1 {
+		t.Errorf("reported events = %d, want 1", len(sink.events))
+	}
+}
+
+func TestBrokenPipe(t *testing.T) {
+	tests := []struct {
+		name      string
+		recovered any
+		want      bool
+	}{
+		{name: "broken pipe", recovered: &net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EPIPE)}, want: true},
+		{name: "connection reset", recovered: &net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.ECONNRESET)}, want: true},
+		{name: "other network error", recovered: &net.OpError{Op: "dial", Err: errors.New("refused")}, want: false},
+		{name: "other error", recovered: errors.New("boom"), want: false},
+		{name: "not an error", recovered: "boom", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := brokenPipe(tt.recovered); got != tt.want {
+				t.Errorf("brokenPipe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"application/pkg/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestId reuses the X-Request-ID of the client, or generates one, echoes it
+// in the response and stores it with a logger in the request context. The
+// engine must enable ContextWithFallback for the gin context to expose them.
+func RequestId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		ctx.Header(requestid.Header, id)
+		ctx.Request = ctx.Request.WithContext(requestid.NewContext(ctx.Request.Context(), id))
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"application/pkg/requestid"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(RequestId())
+	router.GET("/orders", func(ctx *gin.Context) {
+		// the gin context exposes the request context
+		ctx.String(http.StatusOK, requestid.FromContext(ctx))
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		reused bool
+	}{
+		{name: "reused", header: "request-1", reused: true},
+		{name: "generated", header: ""},
+		{name: "invalid", header: "request 1\nforged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.header != "" {
+				request.Header.Set(requestid.Header, tt.header)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			id := recorder.Header().Get(requestid.Header)
+			if !requestid.Valid(id) || recorder.Body.String() != id {
+				t.Errorf("response id = %q, context id = %q, want the same valid id", id, recorder.Body.String())
+			}
+			if (id == tt.header) != tt.reused {
+				t.Errorf("response id = %q, reused %v, want %v", id, id == tt.header, tt.reused)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package oauth
+
+import (
+	"application/app/models"
+	"application/pkg/cache"
+	"application/pkg/rbac"
+	"application/pkg/util"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	GrantTypeClientCredentials = "client_credentials"
+
+	// ScopeIntrospect lets a client, such as the gateway, introspect tokens.
+	ScopeIntrospect = "oauth:introspect"
+
+	// DefaultCacheTTL is how long a verified secret or a looked up client is trusted.
+	DefaultCacheTTL = time.Minute
+)
+
+// Error is an error response of RFC 6749 section 5.2.
+type Error struct {
+	Code        string
+	Description string
+	StatusCode  int
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+var (
+	ErrInvalidRequest       = &Error{Code: "invalid_request", Description: "the request is missing a required parameter", StatusCode: http.StatusBadRequest}
+	ErrInvalidClient        = &Error{Code: "invalid_client", Description: "client authentication failed", StatusCode: http.StatusUnauthorized}
+	ErrUnauthorizedClient   = &Error{Code: "unauthorized_client", Description: "the client is disabled", StatusCode: http.StatusBadRequest}
+	ErrUnsupportedGrantType = &Error{Code: "unsupported_grant_type", Description: "only the client_credentials grant is supported", StatusCode: http.StatusBadRequest}
+	ErrInvalidScope         = &Error{Code: "invalid_scope", Description: "the requested scope exceeds the scopes of the client", StatusCode: http.StatusBadRequest}
+	ErrInsufficientScope    = &Error{Code: "insufficient_scope", Description: "the client is not allowed to introspect tokens", StatusCode: http.StatusForbidden}
+)
+
+// Backend loads the registered clients.
+type Backend interface {
+	FindOAuthClientByClientId(ctx context.Context, clientId string) (*models.OAuthClient, error)
+}
+
+// Store authenticates OAuth2 clients. Secrets are stored as HMAC-SHA256
+// digests under the secret key, cheap enough that the token endpoint cannot be
+// used to exhaust the CPU. Verified credentials and clients are cached, so
+// disabling a client takes effect within the cache ttl.
+type Store struct {
+	backend  Backend
+	key      []byte
+	ttl      time.Duration
+	verified *cache.Cache[string, *models.OAuthClient]
+	clients  *cache.Cache[string, *models.OAuthClient]
+}
+
+func NewStore(backend Backend, key []byte, ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	return &Store{
+		backend:  backend,
+		key:      key,
+		ttl:      ttl,
+		verified: cache.New[string, *models.OAuthClient](),
+		clients:  cache.New[string, *models.OAuthClient](),
+	}
+}
+
+func (s *Store) StartJanitor(ctx context.Context) {
+	s.verified.StartJanitor(ctx, s.ttl)
+	s.clients.StartJanitor(ctx, s.ttl)
+}
+
+// Authenticate returns the enabled client matching the credentials.
+func (s *Store) Authenticate(ctx context.Context, clientId string, secret string) (*models.OAuthClient, error) {
+	if clientId == "" || secret == "" {
+		return nil, ErrInvalidClient
+	}
+
+	key := cacheKey(clientId, secret)
+	if client, ok := s.verified.Get(key); ok {
+		return client, nil
+	}
+
+	client, err := s.backend.FindOAuthClientByClientId(ctx, clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil || !util.CompareSecret(s.key, client.SecretHash, secret) {
+		return nil, ErrInvalidClient
+	}
+
+	if !client.IsEnabled {
+		return nil, ErrUnauthorizedClient
+	}
+
+	s.verified.Set(key, client, s.ttl)
+
+	return client, nil
+}
+
+// ActiveClient returns the client of a token, or nil when it was deleted or
+// disabled so that its tokens stop being accepted.
+func (s *Store) ActiveClient(ctx context.Context, clientId string) (*models.OAuthClient, error) {
+	client, ok := s.clients.Get(clientId)
+	if !ok {
+		found, err := s.backend.FindOAuthClientByClientId(ctx, clientId)
+		if err != nil {
+			return nil, err
+		}
+
+		client = found
+		s.clients.Set(clientId, client, s.ttl)
+	}
+
+	if client == nil || !client.IsEnabled {
+		return nil, nil
+	}
+
+	return client, nil
+}
+
+// GrantScopes returns the scopes of the token. Without a requested scope the
+// client gets all of its scopes; otherwise every requested scope must be
+// covered by the scopes of the client, wildcards included.
+func GrantScopes(client *models.OAuthClient, requested string) ([]string, error) {
+	scopes := strings.Fields(requested)
+	if len(scopes) == 0 {
+		return client.Scopes, nil
+	}
+
+	for _, scope := range scopes {
+		if !rbac.Matches(client.Scopes, scope) {
+			return nil, ErrInvalidScope
+		}
+	}
+
+	return scopes, nil
+}
+
+func cacheKey(clientId string, secret string) string {
+	sum := sha256.Sum256([]byte(clientId + "\x00" + secret))
+	return hex.EncodeToString(sum[:])
+}
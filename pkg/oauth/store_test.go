@@ -0,0 +1,148 @@
+package oauth
+
+import (
+	"application/app/models"
+	"application/pkg/util"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+var testKey = []byte("client-secret-key")
+
+// memoryBackend keeps the clients in a map and counts the lookups.
+type memoryBackend struct {
+	clients map[string]*models.OAuthClient
+	reads   int
+}
+
+func (b *memoryBackend) FindOAuthClientByClientId(_ context.Context, clientId string) (*models.OAuthClient, error) {
+	b.reads++
+	return b.clients[clientId], nil
+}
+
+func newTestStore() (*Store, *memoryBackend) {
+	backend := &memoryBackend{clients: map[string]*models.OAuthClient{
+		"gateway":  {Id: 1, ClientId: "gateway", SecretHash: util.HashSecret(testKey, "gateway-secret"), Scopes: models.StringList{ScopeIntrospect, "orders:*"}, IsEnabled: true},
+		"disabled": {Id: 2, ClientId: "disabled", SecretHash: util.HashSecret(testKey, "disabled-secret")},
+	}}
+
+	return NewStore(backend, testKey, 0), backend
+}
+
+func TestStoreAuthenticate(t *testing.T) {
+	tests := []struct {
+		name     string
+		clientId string
+		secret   string
+		want     error
+	}{
+		{"valid", "gateway", "gateway-secret", nil},
+		{"wrong secret", "gateway", "disabled-secret", ErrInvalidClient},
+		{"unknown client", "unknown", "gateway-secret", ErrInvalidClient},
+		{"missing secret", "gateway", "", ErrInvalidClient},
+		{"disabled client", "disabled", "disabled-secret", ErrUnauthorizedClient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := newTestStore()
+
+			client, err := store.Authenticate(context.Background(), tt.clientId, tt.secret)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && client.ClientId != tt.clientId {
+				t.Errorf("Authenticate() = %s, want %s", client.ClientId, tt.clientId)
+			}
+		})
+	}
+}
+
+func TestStoreAuthenticateRejectsOtherKeys(t *testing.T) {
+	store, backend := newTestStore()
+	backend.clients["gateway"].SecretHash = util.HashSecret([]byte("other key"), "gateway-secret")
+
+	if _, err := store.Authenticate(context.Background(), "gateway", "gateway-secret"); !errors.Is(err, ErrInvalidClient) {
+		t.Errorf("Authenticate() error = %v, want %v", err, ErrInvalidClient)
+	}
+}
+
+func TestStoreCachesVerifiedCredentials(t *testing.T) {
+	ctx := context.Background()
+	store, backend := newTestStore()
+
+	for range 3 {
+		if _, err := store.Authenticate(ctx, "gateway", "gateway-secret"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if backend.reads != 1 {
+		t.Errorf("reads = %d, want the verified credentials cached", backend.reads)
+	}
+
+	// a wrong secret is never served from the cache
+	if _, err := store.Authenticate(ctx, "gateway", "wrong"); !errors.Is(err, ErrInvalidClient) {
+		t.Errorf("Authenticate() with a wrong secret error = %v, want %v", err, ErrInvalidClient)
+	}
+}
+
+func TestStoreActiveClient(t *testing.T) {
+	ctx := context.Background()
+	store, backend := newTestStore()
+
+	tests := []struct {
+		clientId string
+		active   bool
+	}{
+		{"gateway", true},
+		{"disabled", false},
+		{"unknown", false},
+	}
+
+	for _, tt := range tests {
+		client, err := store.ActiveClient(ctx, tt.clientId)
+		if err != nil {
+			t.Fatalf("ActiveClient(%q) error = %v", tt.clientId, err)
+		}
+		if (client != nil) != tt.active {
+			t.Errorf("ActiveClient(%q) = %v, want active %v", tt.clientId, client, tt.active)
+		}
+	}
+
+	// unknown clients are cached too
+	reads := backend.reads
+	if _, err := store.ActiveClient(ctx, "unknown"); err != nil || backend.reads != reads {
+		t.Errorf("ActiveClient() read the backend again (%d reads), want the cache", backend.reads)
+	}
+}
+
+func TestGrantScopes(t *testing.T) {
+	client := &models.OAuthClient{Scopes: models.StringList{"orders:*", "reports:read"}}
+
+	tests := []struct {
+		name      string
+		requested string
+		want      []string
+		err       error
+	}{
+		{"every scope", "", []string{"orders:*", "reports:read"}, nil},
+		{"narrowed", "reports:read", []string{"reports:read"}, nil},
+		{"wildcard", "orders:read  orders:write", []string{"orders:read", "orders:write"}, nil},
+		{"exceeding", "reports:write", nil, ErrInvalidScope},
+		{"partly exceeding", "orders:read users:read", nil, ErrInvalidScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scopes, err := GrantScopes(client, tt.requested)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("GrantScopes() error = %v, want %v", err, tt.err)
+			}
+			if !slices.Equal(scopes, tt.want) {
+				t.Errorf("GrantScopes() = %v, want %v", scopes, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// CodeChallengeMethod is the only PKCE method used, as recommended by RFC 7636.
+const CodeChallengeMethod = "S256"
+
+// RandomValue returns 32 random bytes encoded for URLs, used as PKCE code
+// verifier (43 characters as required by RFC 7636), state and nonce.
+func RandomValue() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// CodeChallenge derives the S256 challenge sent with the authorization request.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
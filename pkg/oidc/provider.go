@@ -0,0 +1,379 @@
+package oidc
+
+import (
+	pkgjwt "application/pkg/jwt"
+	"application/pkg/requestid"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDiscoveryTtl is how long the discovery document is kept before it is loaded again.
+	DefaultDiscoveryTtl = time.Hour
+
+	discoveryPath = "/.well-known/openid-configuration"
+
+	// jwksRefreshInterval limits how often an unknown key id triggers a JWKS download.
+	jwksRefreshInterval = time.Minute
+
+	maxResponseSize = 1 << 20
+)
+
+var (
+	ErrDiscovery        = errors.New("failed to discover the identity provider")
+	ErrExchange         = errors.New("failed to exchange the authorization code")
+	ErrMissingIdToken   = errors.New("token response has no id_token")
+	ErrInvalidNonce     = errors.New("id token has invalid nonce")
+	ErrInvalidAuthParty = errors.New("id token has invalid authorized party")
+)
+
+type Config struct {
+	// IssuerUrl is the issuer of the provider, its discovery document is served below it.
+	IssuerUrl    string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+	// Scopes are requested in addition to openid.
+	Scopes []string
+	// Leeway tolerates clock skew between the provider and this service.
+	Leeway time.Duration
+	// DiscoveryTtl is how long the discovery document is cached, DefaultDiscoveryTtl when zero.
+	DiscoveryTtl time.Duration
+}
+
+// Metadata is the part of the discovery document of OpenID Connect Discovery 1.0 in use here.
+type Metadata struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	JwksUri                       string   `json:"jwks_uri"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// TokenResponse is the answer of the token endpoint.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IdToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// IdTokenClaims are the claims of an ID token used to map the user. Claims
+// is the whole payload, to read a configurable role claim.
+type IdTokenClaims struct {
+	Subject           string         `json:"-"`
+	Nonce             string         `json:"nonce"`
+	AuthorizedParty   string         `json:"azp,omitempty"`
+	Email             string         `json:"email,omitempty"`
+	EmailVerified     Bool           `json:"email_verified,omitempty"`
+	Name              string         `json:"name,omitempty"`
+	PreferredUsername string         `json:"preferred_username,omitempty"`
+	Claims            map[string]any `json:"-"`
+}
+
+// Bool decodes a boolean claim that some providers send as a string.
+type Bool bool
+
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	*b = Bool(value == "true")
+
+	return nil
+}
+
+// Provider runs the authorization code flow against an OpenID Connect
+// provider. The discovery document is loaded on first use and again once it
+// is older than the discovery TTL, the keys of the provider are downloaded
+// again when a token is signed with an unknown key.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu           sync.Mutex
+	metadata     *Metadata
+	discoveredAt time.Time
+	verifier     *pkgjwt.JwtAdapter
+	refreshedAt  time.Time
+}
+
+func NewProvider(config Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second, Transport: &requestid.Transport{}}
+	}
+
+	config.IssuerUrl = strings.TrimSuffix(config.IssuerUrl, "/")
+	if config.DiscoveryTtl <= 0 {
+		config.DiscoveryTtl = DefaultDiscoveryTtl
+	}
+
+	return &Provider{config: config, client: client}
+}
+
+// AuthCodeUrl is where the browser is sent to sign in.
+func (p *Provider) AuthCodeUrl(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint: %v", ErrDiscovery, err)
+	}
+
+	query := endpoint.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientId)
+	query.Set("redirect_uri", p.config.RedirectUrl)
+	query.Set("scope", strings.Join(p.scopes(), " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", CodeChallengeMethod)
+	endpoint.RawQuery = query.Encode()
+
+	return endpoint.String(), nil
+}
+
+// Exchange redeems the authorization code with the PKCE code verifier.
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (*TokenResponse, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectUrl},
+		"code_verifier": {codeVerifier},
+	}
+
+	// public clients send their id in the form, confidential clients authenticate with basic auth
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientId)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(p.config.ClientId), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		var failure struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &failure)
+		return nil, fmt.Errorf("%w: status %d %s %s", ErrExchange, response.StatusCode, failure.Error, failure.Description)
+	}
+
+	token := new(TokenResponse)
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+
+	if token.IdToken == "" {
+		return nil, ErrMissingIdToken
+	}
+
+	return token, nil
+}
+
+// VerifyIdToken checks the signature against the keys of the provider, the
+// issuer, the audience, the time based claims and the nonce of the flow.
+func (p *Provider) VerifyIdToken(ctx context.Context, idToken string, nonce string) (*IdTokenClaims, error) {
+	verifier, err := p.keys(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := pkgjwt.VerifyClaims[map[string]any](verifier, idToken)
+	if errors.Is(err, pkgjwt.ErrUnknownKeyId) {
+		// the provider may have rotated its keys
+		if verifier, err = p.keys(ctx, true); err != nil {
+			return nil, err
+		}
+		claims, err = pkgjwt.VerifyClaims[map[string]any](verifier, idToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(claims.Custom)
+	if err != nil {
+		return nil, err
+	}
+
+	idClaims := &IdTokenClaims{Subject: claims.Subject, Claims: claims.Custom}
+	if err := json.Unmarshal(payload, idClaims); err != nil {
+		return nil, fmt.Errorf("invalid id token claims: %w", err)
+	}
+
+	if idClaims.Subject == "" {
+		return nil, pkgjwt.Error(errors.New("token has no subject"))
+	}
+
+	if subtle.ConstantTimeCompare([]byte(idClaims.Nonce), []byte(nonce)) != 1 {
+		return nil, ErrInvalidNonce
+	}
+
+	if len(claims.Audience) > 1 || idClaims.AuthorizedParty != "" {
+		if idClaims.AuthorizedParty != p.config.ClientId {
+			return nil, ErrInvalidAuthParty
+		}
+	}
+
+	return idClaims, nil
+}
+
+func (p *Provider) scopes() []string {
+	scopes := []string{"openid"}
+	for _, scope := range p.config.Scopes {
+		if scope = strings.TrimSpace(scope); scope != "" && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
+
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.discoverLocked(ctx)
+}
+
+func (p *Provider) discoverLocked(ctx context.Context) (*Metadata, error) {
+	if p.metadata != nil && time.Since(p.discoveredAt) < p.config.DiscoveryTtl {
+		return p.metadata, nil
+	}
+
+	metadata := new(Metadata)
+	if err := p.getJson(ctx, p.config.IssuerUrl+discoveryPath, metadata); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	if strings.TrimSuffix(metadata.Issuer, "/") != p.config.IssuerUrl {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", ErrDiscovery, metadata.Issuer, p.config.IssuerUrl)
+	}
+
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JwksUri == "" {
+		return nil, fmt.Errorf("%w: incomplete discovery document", ErrDiscovery)
+	}
+
+	if len(metadata.CodeChallengeMethodsSupported) > 0 && !slices.Contains(metadata.CodeChallengeMethodsSupported, CodeChallengeMethod) {
+		return nil, fmt.Errorf("%w: provider does not support PKCE %s", ErrDiscovery, CodeChallengeMethod)
+	}
+
+	if p.metadata != nil && p.metadata.JwksUri != metadata.JwksUri {
+		// the keys are downloaded again from the new location
+		p.verifier = nil
+	}
+
+	p.metadata = metadata
+	p.discoveredAt = time.Now()
+
+	return metadata, nil
+}
+
+// keys returns the verifier of the ID tokens, downloading the JWKS on first
+// use or when refresh is asked and the last download is old enough.
+func (p *Provider) keys(ctx context.Context, refresh bool) (*pkgjwt.JwtAdapter, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.verifier != nil && (!refresh || time.Since(p.refreshedAt) < jwksRefreshInterval) {
+		return p.verifier, nil
+	}
+
+	metadata, err := p.discoverLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks pkgjwt.JWKS
+	if err := p.getJson(ctx, metadata.JwksUri, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to download the provider keys: %w", err)
+	}
+
+	keys := pkgjwt.NewKeySet()
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.Key()
+		if err != nil {
+			// skip keys of unsupported types, the provider may publish several
+			continue
+		}
+
+		keys.Add(key)
+	}
+
+	switch usable := keys.Keys(); len(usable) {
+	case 0:
+		return nil, errors.New("the provider publishes no usable signing key")
+	case 1:
+		// ID tokens without a kid header are verified with the only key
+		keys.SetSigningKey(usable[0])
+	}
+
+	p.verifier = &pkgjwt.JwtAdapter{
+		Issuer:   metadata.Issuer,
+		Audience: []string{p.config.ClientId},
+		Leeway:   p.config.Leeway,
+		Keys:     keys,
+	}
+	p.refreshedAt = time.Now()
+
+	return p.verifier, nil
+}
+
+func (p *Provider) getJson(ctx context.Context, endpoint string, target any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", response.StatusCode, endpoint)
+	}
+
+	return json.NewDecoder(io.LimitReader(response.Body, maxResponseSize)).Decode(target)
+}
@@ -0,0 +1,334 @@
+package oidc
+
+import (
+	pkgjwt "application/pkg/jwt"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const testClientId = "admin-console"
+
+type testSigner struct {
+	key     *pkgjwt.Key
+	private *ecdsa.PrivateKey
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	t.Helper()
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := pkgjwt.NewAsymmetricKey(&private.PublicKey, private, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testSigner{key: key, private: private}
+}
+
+// testIdp serves the discovery document and the keys of its signers, and
+// answers the token endpoint with idToken.
+type testIdp struct {
+	server *httptest.Server
+
+	mu          sync.Mutex
+	signers     []*testSigner
+	idToken     string
+	form        url.Values
+	discoveries int
+	downloads   int
+}
+
+func newTestIdp(t *testing.T, signers ...*testSigner) *testIdp {
+	t.Helper()
+
+	idp := &testIdp{signers: signers}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		idp.mu.Lock()
+		idp.discoveries++
+		idp.mu.Unlock()
+
+		writeTestJson(w, http.StatusOK, Metadata{
+			Issuer:                        idp.server.URL,
+			AuthorizationEndpoint:         idp.server.URL + "/authorize",
+			TokenEndpoint:                 idp.server.URL + "/token",
+			JwksUri:                       idp.server.URL + "/jwks",
+			CodeChallengeMethodsSupported: []string{CodeChallengeMethod},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, _ *http.Request) {
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+
+		idp.downloads++
+		keys := pkgjwt.NewKeySet()
+		for _, signer := range idp.signers {
+			keys.Add(signer.key)
+		}
+		writeTestJson(w, http.StatusOK, keys.JWKS())
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+
+		idp.form = r.PostForm
+		if r.PostForm.Get("code") != "good-code" {
+			writeTestJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		writeTestJson(w, http.StatusOK, TokenResponse{AccessToken: "access", TokenType: "Bearer", IdToken: idp.idToken})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func (idp *testIdp) provider() *Provider {
+	return NewProvider(Config{
+		IssuerUrl:   idp.server.URL,
+		ClientId:    testClientId,
+		RedirectUrl: "https://admin.example.com/sso",
+		Scopes:      []string{"email", "openid"},
+	}, idp.server.Client())
+}
+
+// sign issues an ID token, with the kid header of the signer unless withKid is false.
+func (idp *testIdp) sign(t *testing.T, signer *testSigner, withKid bool, claims jwt.MapClaims) string {
+	t.Helper()
+
+	now := time.Now()
+	payload := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"aud":   testClientId,
+		"sub":   "user-1",
+		"nonce": "nonce",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Minute).Unix(),
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(payload, name)
+			continue
+		}
+		payload[name] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, payload)
+	if withKid {
+		token.Header["kid"] = signer.key.Id
+	}
+
+	signed, err := token.SignedString(signer.private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func writeTestJson(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestAuthCodeUrl(t *testing.T) {
+	idp := newTestIdp(t, newTestSigner(t))
+
+	authorizationUrl, err := idp.provider().AuthCodeUrl(context.Background(), "state", "nonce", "challenge")
+	if err != nil {
+		t.Fatalf("AuthCodeUrl() error = %v", err)
+	}
+
+	parsed, err := url.Parse(authorizationUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientId,
+		"redirect_uri":          "https://admin.example.com/sso",
+		"scope":                 "openid email",
+		"state":                 "state",
+		"nonce":                 "nonce",
+		"code_challenge":        "challenge",
+		"code_challenge_method": CodeChallengeMethod,
+	}
+	for name, value := range want {
+		if got := parsed.Query().Get(name); got != value {
+			t.Errorf("AuthCodeUrl() %s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	idp := newTestIdp(t, newTestSigner(t))
+	idp.idToken = "id-token"
+	provider := idp.provider()
+
+	token, err := provider.Exchange(context.Background(), "good-code", "verifier")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if token.IdToken != "id-token" {
+		t.Errorf("Exchange() id token = %q, want %q", token.IdToken, "id-token")
+	}
+	if idp.form.Get("code_verifier") != "verifier" || idp.form.Get("client_id") != testClientId {
+		t.Errorf("Exchange() sent %v", idp.form)
+	}
+
+	if _, err := provider.Exchange(context.Background(), "bad-code", "verifier"); !errors.Is(err, ErrExchange) {
+		t.Errorf("Exchange(bad code) error = %v, want %v", err, ErrExchange)
+	}
+
+	idp.idToken = ""
+	if _, err := provider.Exchange(context.Background(), "good-code", "verifier"); !errors.Is(err, ErrMissingIdToken) {
+		t.Errorf("Exchange(no id token) error = %v, want %v", err, ErrMissingIdToken)
+	}
+}
+
+func TestVerifyIdToken(t *testing.T) {
+	signer, other := newTestSigner(t), newTestSigner(t)
+
+	tests := []struct {
+		name    string
+		signers []*testSigner
+		withKid bool
+		claims  jwt.MapClaims
+		wantErr bool
+	}{
+		{name: "valid", signers: []*testSigner{signer}, withKid: true},
+		{name: "no kid with a single key", signers: []*testSigner{signer}},
+		{name: "no kid with several keys", signers: []*testSigner{signer, other}, wantErr: true},
+		{name: "wrong nonce", signers: []*testSigner{signer}, withKid: true, claims: jwt.MapClaims{"nonce": "other"}, wantErr: true},
+		{name: "wrong audience", signers: []*testSigner{signer}, withKid: true, claims: jwt.MapClaims{"aud": "other"}, wantErr: true},
+		{name: "wrong issuer", signers: []*testSigner{signer}, withKid: true, claims: jwt.MapClaims{"iss": "https://evil.example.com"}, wantErr: true},
+		{name: "expired", signers: []*testSigner{signer}, withKid: true, claims: jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}, wantErr: true},
+		{name: "no subject", signers: []*testSigner{signer}, withKid: true, claims: jwt.MapClaims{"sub": nil}, wantErr: true},
+		{name: "several audiences without azp", signers: []*testSigner{signer}, withKid: true, claims: jwt.MapClaims{"aud": []string{testClientId, "other"}}, wantErr: true},
+		{name: "several audiences with azp", signers: []*testSigner{signer}, withKid: true, claims: jwt.MapClaims{"aud": []string{testClientId, "other"}, "azp": testClientId}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newTestIdp(t, tt.signers...)
+
+			claims, err := idp.provider().VerifyIdToken(context.Background(), idp.sign(t, signer, tt.withKid, tt.claims), "nonce")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyIdToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && claims.Subject != "user-1" {
+				t.Errorf("VerifyIdToken() subject = %q, want %q", claims.Subject, "user-1")
+			}
+		})
+	}
+}
+
+func TestVerifyIdTokenDecodesClaims(t *testing.T) {
+	signer := newTestSigner(t)
+	idp := newTestIdp(t, signer)
+
+	idToken := idp.sign(t, signer, true, jwt.MapClaims{
+		"email":              "jane@example.com",
+		"email_verified":     "true",
+		"preferred_username": "jane",
+		"groups":             []string{"admins"},
+	})
+
+	claims, err := idp.provider().VerifyIdToken(context.Background(), idToken, "nonce")
+	if err != nil {
+		t.Fatalf("VerifyIdToken() error = %v", err)
+	}
+	if claims.Email != "jane@example.com" || !bool(claims.EmailVerified) || claims.PreferredUsername != "jane" {
+		t.Errorf("VerifyIdToken() claims = %+v", claims)
+	}
+	if groups, ok := claims.Claims["groups"].([]any); !ok || len(groups) != 1 || groups[0] != "admins" {
+		t.Errorf("VerifyIdToken() groups = %v, want [admins]", claims.Claims["groups"])
+	}
+}
+
+func TestVerifyIdTokenDownloadsRotatedKeys(t *testing.T) {
+	signer, rotated := newTestSigner(t), newTestSigner(t)
+	idp := newTestIdp(t, signer)
+	provider := idp.provider()
+
+	if _, err := provider.VerifyIdToken(context.Background(), idp.sign(t, signer, true, nil), "nonce"); err != nil {
+		t.Fatalf("VerifyIdToken() error = %v", err)
+	}
+
+	idp.mu.Lock()
+	idp.signers = []*testSigner{rotated}
+	idp.mu.Unlock()
+	// the keys were just downloaded, allow the refresh right away
+	provider.refreshedAt = time.Now().Add(-jwksRefreshInterval)
+
+	if _, err := provider.VerifyIdToken(context.Background(), idp.sign(t, rotated, true, nil), "nonce"); err != nil {
+		t.Fatalf("VerifyIdToken(rotated key) error = %v", err)
+	}
+
+	// an unknown kid does not download the keys again within the refresh interval
+	if _, err := provider.VerifyIdToken(context.Background(), idp.sign(t, signer, true, nil), "nonce"); err == nil {
+		t.Error("VerifyIdToken(removed key) error = nil, want an error")
+	}
+	if idp.downloads != 2 {
+		t.Errorf("JWKS downloads = %d, want 2", idp.downloads)
+	}
+}
+
+func TestDiscoveryIsCachedForTheTtl(t *testing.T) {
+	idp := newTestIdp(t, newTestSigner(t))
+	provider := idp.provider()
+
+	for range 2 {
+		if _, err := provider.AuthCodeUrl(context.Background(), "state", "nonce", "challenge"); err != nil {
+			t.Fatalf("AuthCodeUrl() error = %v", err)
+		}
+	}
+	if idp.discoveries != 1 {
+		t.Errorf("discoveries = %d, want 1", idp.discoveries)
+	}
+
+	provider.discoveredAt = time.Now().Add(-DefaultDiscoveryTtl)
+
+	if _, err := provider.AuthCodeUrl(context.Background(), "state", "nonce", "challenge"); err != nil {
+		t.Fatalf("AuthCodeUrl() error = %v", err)
+	}
+	if idp.discoveries != 2 {
+		t.Errorf("discoveries after the ttl = %d, want 2", idp.discoveries)
+	}
+}
+
+func TestDiscoveryRejectsAnotherIssuer(t *testing.T) {
+	idp := newTestIdp(t, newTestSigner(t))
+
+	// the same server under another name announces an issuer that does not match
+	issuer := strings.Replace(idp.server.URL, "127.0.0.1", "localhost", 1)
+	provider := NewProvider(Config{IssuerUrl: issuer, ClientId: testClientId}, idp.server.Client())
+
+	if _, err := provider.AuthCodeUrl(context.Background(), "state", "nonce", "challenge"); !errors.Is(err, ErrDiscovery) {
+		t.Errorf("AuthCodeUrl() error = %v, want %v", err, ErrDiscovery)
+	}
+}
@@ -0,0 +1,215 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+)
+
+const (
+	DefaultMinLength  = 12
+	DefaultMaxLength  = 128
+	DefaultMinClasses = 3
+
+	// DefaultGeneratedLength is the length of generated passwords, unless the
+	// policy requires longer ones.
+	DefaultGeneratedLength = 20
+
+	// generatedAlphabet mixes the four character classes without look-alikes.
+	generatedAlphabet   = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789!#%*+-=?@_"
+	maxGenerateAttempts = 100
+)
+
+var ErrPolicyViolation = errors.New("password does not meet the policy")
+
+// PolicyError lists every rule the password breaks.
+type PolicyError struct {
+	Violations []string
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrPolicyViolation, strings.Join(e.Violations, ", "))
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+type Config struct {
+	MinLength int
+	MaxLength int
+	// MinClasses is how many of lowercase, uppercase, digits and symbols are required.
+	MinClasses int
+	// BreachedListFile lists known breached passwords, one per line, either in
+	// clear or as SHA-1 hex digests optionally followed by ":count" as in the
+	// Have I Been Pwned downloads.
+	BreachedListFile string
+}
+
+// Policy validates new passwords.
+type Policy struct {
+	config   Config
+	breached map[string]struct{}
+}
+
+// NewPolicy loads the breached password list when configured. Zero config
+// values fall back to the defaults.
+func NewPolicy(config Config) (*Policy, error) {
+	if config.MinLength <= 0 {
+		config.MinLength = DefaultMinLength
+	}
+	if config.MaxLength <= 0 {
+		config.MaxLength = DefaultMaxLength
+	}
+	if config.MinClasses <= 0 {
+		config.MinClasses = DefaultMinClasses
+	}
+
+	policy := &Policy{config: config}
+
+	if config.BreachedListFile != "" {
+		breached, err := loadBreachedList(config.BreachedListFile)
+		if err != nil {
+			return nil, err
+		}
+		policy.breached = breached
+	}
+
+	return policy, nil
+}
+
+// Validate returns a *PolicyError when the password breaks any rule. The
+// identifiers of the account, such as the username and email, must not be
+// part of the password.
+func (p *Policy) Validate(password string, identifiers ...string) error {
+	var violations []string
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.config.MinLength))
+	}
+	if length > p.config.MaxLength {
+		violations = append(violations, fmt.Sprintf("must be at most %d characters", p.config.MaxLength))
+	}
+
+	if classes := characterClasses(password); classes < p.config.MinClasses {
+		violations = append(violations, fmt.Sprintf("must mix at least %d of lowercase, uppercase, digits and symbols", p.config.MinClasses))
+	}
+
+	lower := strings.ToLower(password)
+	for _, identifier := range identifiers {
+		if identifier = strings.ToLower(strings.TrimSpace(identifier)); len(identifier) >= 3 && strings.Contains(lower, identifier) {
+			violations = append(violations, "must not contain the username or email")
+			break
+		}
+	}
+
+	if p.IsBreached(password) {
+		violations = append(violations, "appears in a list of breached passwords")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// Generate returns a random password accepted by the policy for the account,
+// such as the initial password of an admin created from the command line.
+func (p *Policy) Generate(identifiers ...string) (string, error) {
+	length := min(max(DefaultGeneratedLength, p.config.MinLength), p.config.MaxLength)
+
+	for range maxGenerateAttempts {
+		candidate, err := gonanoid.Generate(generatedAlphabet, length)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password: %w", err)
+		}
+
+		if p.Validate(candidate, identifiers...) == nil {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to generate a password meeting the policy")
+}
+
+func (p *Policy) IsBreached(password string) bool {
+	if p.breached == nil {
+		return false
+	}
+
+	_, found := p.breached[sha1Hex(password)]
+	return found
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+
+	return lower + upper + digit + symbol
+}
+
+func loadBreachedList(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	breached := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if digest, _, _ := strings.Cut(line, ":"); isSha1Hex(digest) {
+			breached[strings.ToUpper(digest)] = struct{}{}
+			continue
+		}
+
+		breached[sha1Hex(line)] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	return breached, nil
+}
+
+func sha1Hex(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func isSha1Hex(value string) bool {
+	if len(value) != sha1.Size*2 {
+		return false
+	}
+
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
@@ -0,0 +1,136 @@
+package password
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	policy, err := NewPolicy(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		password   string
+		violations []string
+	}{
+		{"valid", "Correct-Horse-7", nil},
+		{"too short", "Sh0rt-pass", []string{"must be at least 12 characters"}},
+		{"too long", "Aa1-" + string(make([]byte, DefaultMaxLength)), []string{"must be at most 128 characters"}},
+		{"two classes", "correcthorsebattery", []string{"must mix at least 3 of lowercase, uppercase, digits and symbols"}},
+		{"contains the username", "Admin-Horse-77", []string{"must not contain the username or email"}},
+		{"contains the email", "x-admin@example.com-X", []string{"must not contain the username or email"}},
+		{"several rules", "admin", []string{
+			"must be at least 12 characters",
+			"must mix at least 3 of lowercase, uppercase, digits and symbols",
+			"must not contain the username or email",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, "admin", "admin@example.com")
+
+			if tt.violations == nil {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var policyErr *PolicyError
+			if !errors.As(err, &policyErr) || !errors.Is(err, ErrPolicyViolation) {
+				t.Fatalf("Validate() error = %v, want a *PolicyError", err)
+			}
+			if !slices.Equal(policyErr.Violations, tt.violations) {
+				t.Errorf("violations = %q, want %q", policyErr.Violations, tt.violations)
+			}
+		})
+	}
+}
+
+func TestPolicyShortIdentifiersAreIgnored(t *testing.T) {
+	policy, err := NewPolicy(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := policy.Validate("Correct-Horse-7", "or", ""); err != nil {
+		t.Errorf("Validate() with a two letter username error = %v", err)
+	}
+}
+
+func TestPolicyBreachedList(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "breached.txt")
+	list := "# known passwords\n" +
+		"Password-123!\n" +
+		// SHA-1 of "Summer-2024!" with a count, as in the Have I Been Pwned downloads
+		"c025465031730882720e768ce69aac3f07825c2a:42\n"
+	if err := os.WriteFile(file, []byte(list), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := NewPolicy(Config{BreachedListFile: file})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+
+	if !policy.IsBreached("Password-123!") {
+		t.Error("IsBreached() of a password in clear = false")
+	}
+	if !policy.IsBreached("Summer-2024!") {
+		t.Error("IsBreached() of a password listed by digest = false")
+	}
+	if policy.IsBreached("Correct-Horse-7") {
+		t.Error("IsBreached() of another password = true")
+	}
+
+	var policyErr *PolicyError
+	if err := policy.Validate("Password-123!"); !errors.As(err, &policyErr) ||
+		!slices.Equal(policyErr.Violations, []string{"appears in a list of breached passwords"}) {
+		t.Errorf("Validate() of a breached password error = %v", err)
+	}
+
+	if _, err := NewPolicy(Config{BreachedListFile: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Error("NewPolicy() with a missing list error = nil")
+	}
+}
+
+func TestPolicyGenerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		length int
+	}{
+		{"defaults", Config{}, DefaultGeneratedLength},
+		{"every class", Config{MinClasses: 4}, DefaultGeneratedLength},
+		{"longer minimum", Config{MinLength: 32}, 32},
+		{"shorter maximum", Config{MinLength: 8, MaxLength: 16}, 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewPolicy(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			password, err := policy.Generate("admin", "admin@example.com")
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			if len(password) != tt.length {
+				t.Errorf("Generate() length = %d, want %d", len(password), tt.length)
+			}
+			if err := policy.Validate(password, "admin", "admin@example.com"); err != nil {
+				t.Errorf("Validate() of a generated password error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package rbac
+
+import (
+	"application/app/enums"
+	"application/pkg/cache"
+	pkgjwt "application/pkg/jwt"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultCacheTTL        = 5 * time.Minute
+	DefaultRefreshInterval = 30 * time.Second
+)
+
+// Loader resolves the permissions granted to a set of roles.
+type Loader interface {
+	FindPermissionCodesByRoleCodes(ctx context.Context, roles []string) ([]string, error)
+	// FindRolePermissionPairs returns every grant as "role:permission", in a
+	// stable order, to detect edits of the roles.
+	FindRolePermissionPairs(ctx context.Context) ([]string, error)
+}
+
+// Policy answers role and permission checks for the claims of an access token.
+// Resolved permissions are cached per token until the roles are edited.
+type Policy struct {
+	loader Loader
+	ttl    time.Duration
+	cache  *cache.Cache[string, []string]
+
+	// generation changes whenever the cache is cleared, so that permissions
+	// loaded before an edit of the roles are not cached after it.
+	mu         sync.Mutex
+	grants     string
+	generation int64
+}
+
+func NewPolicy(loader Loader, ttl time.Duration) *Policy {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	return &Policy{
+		loader: loader,
+		ttl:    ttl,
+		cache:  cache.New[string, []string](),
+	}
+}
+
+// StartJanitor removes cached permissions of expired tokens until the context is done.
+func (p *Policy) StartJanitor(ctx context.Context) {
+	p.cache.StartJanitor(ctx, p.ttl)
+}
+
+// Start loads the grants of the roles and checks them every interval until the
+// context is done, so that roles edited in the database or by another instance
+// clear the cached permissions. A zero interval falls back to the default.
+func (p *Policy) Start(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+
+	if err := p.Refresh(ctx); err != nil {
+		return err
+	}
+
+	p.StartJanitor(ctx)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.Refresh(ctx); err != nil {
+					log.Error().Err(err).Msg("failed to refresh role permissions")
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Refresh reloads the grants of the roles and clears the cached permissions
+// when they changed since the last refresh.
+func (p *Policy) Refresh(ctx context.Context) error {
+	pairs, err := p.loader.FindRolePermissionPairs(ctx)
+	if err != nil {
+		return err
+	}
+
+	grants := strings.Join(pairs, ",")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if grants != p.grants {
+		p.grants = grants
+		p.clear()
+	}
+
+	return nil
+}
+
+// Invalidate clears the cached permissions, e.g. after editing the roles.
+func (p *Policy) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.clear()
+}
+
+func (p *Policy) clear() {
+	p.generation++
+	p.cache.Clear()
+}
+
+// HasRole reports whether the claims carry any of the roles.
+func (p *Policy) HasRole(claims *pkgjwt.JwtResponse, roles ...enums.CodeAdminRole) bool {
+	for _, role := range roles {
+		if claims.Role == role.String() || slices.Contains(claims.Roles, role.String()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Permissions returns the permissions of the token roles together with the
+// permissions carried by the token itself.
+func (p *Policy) Permissions(ctx context.Context, claims *pkgjwt.JwtResponse) ([]string, error) {
+	key := cacheKey(claims)
+	if permissions, ok := p.cache.Get(key); ok {
+		return permissions, nil
+	}
+
+	p.mu.Lock()
+	generation := p.generation
+	p.mu.Unlock()
+
+	permissions, err := p.loader.FindPermissionCodesByRoleCodes(ctx, roles(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, permission := range claims.Permissions {
+		if !slices.Contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	ttl := p.ttl
+	if claims.Exp > 0 {
+		if untilExpiry := time.Until(time.Unix(claims.Exp, 0)); untilExpiry < ttl {
+			ttl = untilExpiry
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ttl > 0 && generation == p.generation {
+		p.cache.Set(key, permissions, ttl)
+	}
+
+	return permissions, nil
+}
+
+// Can reports whether the claims are granted every permission.
+func (p *Policy) Can(ctx context.Context, claims *pkgjwt.JwtResponse, permissions ...enums.CodePermission) (bool, error) {
+	granted, err := p.Permissions(ctx, claims)
+	if err != nil {
+		return false, err
+	}
+
+	for _, permission := range permissions {
+		if !Grants(granted, permission) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Grants reports whether the permission is granted directly, by the "*"
+// wildcard or by a "resource:*" wildcard.
+func Grants(granted []string, permission enums.CodePermission) bool {
+	return Matches(granted, permission.String())
+}
+
+// Matches applies the wildcard rules of Grants to any "resource:action" code,
+// such as the scopes of a client.
+func Matches(granted []string, code string) bool {
+	resource, _, _ := strings.Cut(code, ":")
+
+	for _, value := range granted {
+		if value == code || value == enums.PermissionAll.String() || value == resource+":*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func roles(claims *pkgjwt.JwtResponse) []string {
+	roles := slices.Clone(claims.Roles)
+	if claims.Role != "" && !slices.Contains(roles, claims.Role) {
+		roles = append(roles, claims.Role)
+	}
+
+	return roles
+}
+
+// cacheKey identifies the token, falling back to its roles for tokens without a jti.
+func cacheKey(claims *pkgjwt.JwtResponse) string {
+	if claims.Jti != "" {
+		return claims.Jti
+	}
+
+	sorted := roles(claims)
+	sort.Strings(sorted)
+
+	return "roles:" + strings.Join(sorted, ",") + "|" + strings.Join(claims.Permissions, ",")
+}
@@ -0,0 +1,215 @@
+package rbac
+
+import (
+	"application/app/enums"
+	pkgjwt "application/pkg/jwt"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryLoader grants permissions from a map of role codes.
+type memoryLoader struct {
+	mu     sync.Mutex
+	grants map[string][]string
+	loads  int
+}
+
+func (l *memoryLoader) FindPermissionCodesByRoleCodes(_ context.Context, roles []string) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.loads++
+
+	var codes []string
+	for _, role := range roles {
+		for _, code := range l.grants[role] {
+			if !slices.Contains(codes, code) {
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	return codes, nil
+}
+
+func (l *memoryLoader) FindRolePermissionPairs(_ context.Context) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var pairs []string
+	for role, codes := range l.grants {
+		for _, code := range codes {
+			pairs = append(pairs, role+":"+code)
+		}
+	}
+	slices.Sort(pairs)
+
+	return pairs, nil
+}
+
+func (l *memoryLoader) grant(role string, codes ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.grants[role] = codes
+}
+
+func TestGrants(t *testing.T) {
+	tests := []struct {
+		name       string
+		granted    []string
+		permission enums.CodePermission
+		want       bool
+	}{
+		{"exact", []string{"users:read"}, "users:read", true},
+		{"other action", []string{"users:read"}, "users:write", false},
+		{"resource wildcard", []string{"users:*"}, "users:write", true},
+		{"wildcard of another resource", []string{"roles:*"}, "users:write", false},
+		{"everything", []string{enums.PermissionAll.String()}, "users:write", true},
+		{"nothing", nil, "users:read", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Grants(tt.granted, tt.permission); got != tt.want {
+				t.Errorf("Grants(%v, %s) = %v, want %v", tt.granted, tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyCan(t *testing.T) {
+	loader := &memoryLoader{grants: map[string][]string{
+		"ADMIN":   {"users:*"},
+		"AUDITOR": {"audit:read"},
+	}}
+	policy := NewPolicy(loader, time.Minute)
+
+	tests := []struct {
+		name        string
+		claims      *pkgjwt.JwtResponse
+		permissions []enums.CodePermission
+		want        bool
+	}{
+		{"role", &pkgjwt.JwtResponse{Jti: "1", Role: "ADMIN"}, []enums.CodePermission{"users:write"}, true},
+		{"one of the roles", &pkgjwt.JwtResponse{Jti: "2", Roles: []string{"AUDITOR", "ADMIN"}}, []enums.CodePermission{"users:read", "audit:read"}, true},
+		{"missing permission", &pkgjwt.JwtResponse{Jti: "3", Role: "AUDITOR"}, []enums.CodePermission{"users:read"}, false},
+		{"permission of the token", &pkgjwt.JwtResponse{Jti: "4", Role: "AUDITOR", Permissions: []string{"users:read"}}, []enums.CodePermission{"users:read"}, true},
+		{"no jti", &pkgjwt.JwtResponse{Role: "ADMIN"}, []enums.CodePermission{"users:read"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := policy.Can(context.Background(), tt.claims, tt.permissions...)
+			if err != nil || got != tt.want {
+				t.Errorf("Can(%v) = %v, %v, want %v", tt.permissions, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyCachesPerToken(t *testing.T) {
+	ctx := context.Background()
+	loader := &memoryLoader{grants: map[string][]string{"ADMIN": {"users:read"}}}
+	policy := NewPolicy(loader, time.Minute)
+	claims := &pkgjwt.JwtResponse{Jti: "jti", Role: "ADMIN", Exp: time.Now().Add(time.Hour).Unix()}
+
+	for range 3 {
+		if _, err := policy.Permissions(ctx, claims); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if loader.loads != 1 {
+		t.Errorf("loads = %d, want the permissions cached after the first", loader.loads)
+	}
+
+	expired := &pkgjwt.JwtResponse{Jti: "expired", Role: "ADMIN", Exp: time.Now().Add(-time.Second).Unix()}
+	for range 2 {
+		if _, err := policy.Permissions(ctx, expired); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if loader.loads != 3 {
+		t.Errorf("loads = %d, want the permissions of an expired token never cached", loader.loads)
+	}
+}
+
+func TestPolicyRefreshClearsTheCacheWhenRolesChange(t *testing.T) {
+	ctx := context.Background()
+	loader := &memoryLoader{grants: map[string][]string{"ADMIN": {"users:read"}}}
+	policy := NewPolicy(loader, time.Minute)
+	claims := &pkgjwt.JwtResponse{Jti: "jti", Role: "ADMIN"}
+
+	if err := policy.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _ := policy.Can(ctx, claims, "users:read"); !ok {
+		t.Fatal("Can(users:read) = false before the edit")
+	}
+
+	// an unchanged grant keeps the cache
+	if err := policy.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if policy.cache.Len() != 1 {
+		t.Errorf("cache entries = %d after a refresh without edits, want 1", policy.cache.Len())
+	}
+
+	loader.grant("ADMIN", "audit:read")
+
+	if ok, _ := policy.Can(ctx, claims, "users:read"); !ok {
+		t.Fatal("cached permissions were dropped before the refresh")
+	}
+
+	if err := policy.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _ := policy.Can(ctx, claims, "users:read"); ok {
+		t.Error("Can(users:read) = true after the permission was taken from the role")
+	}
+	if ok, _ := policy.Can(ctx, claims, "audit:read"); !ok {
+		t.Error("Can(audit:read) = false after the permission was granted to the role")
+	}
+}
+
+func TestPolicyInvalidateDropsPermissionsLoadedBefore(t *testing.T) {
+	ctx := context.Background()
+	loader := &memoryLoader{grants: map[string][]string{"ADMIN": {"users:read"}}}
+	policy := NewPolicy(loader, time.Minute)
+	claims := &pkgjwt.JwtResponse{Jti: "jti", Role: "ADMIN"}
+
+	if _, err := policy.Permissions(ctx, claims); err != nil {
+		t.Fatal(err)
+	}
+
+	loader.grant("ADMIN")
+	policy.Invalidate()
+
+	permissions, err := policy.Permissions(ctx, claims)
+	if err != nil || len(permissions) != 0 {
+		t.Errorf("Permissions() = %v, %v, want none after the invalidation", permissions, err)
+	}
+}
+
+func TestPolicyHasRole(t *testing.T) {
+	policy := NewPolicy(&memoryLoader{}, 0)
+	claims := &pkgjwt.JwtResponse{Role: "ADMIN", Roles: []string{"ADMIN", "AUDITOR"}}
+
+	if !policy.HasRole(claims, "SUPER_ADMIN", "AUDITOR") {
+		t.Error("HasRole(SUPER_ADMIN, AUDITOR) = false, want true")
+	}
+	if policy.HasRole(claims, "SUPER_ADMIN") {
+		t.Error("HasRole(SUPER_ADMIN) = true, want false")
+	}
+	if got := strings.Join(roles(claims), ","); got != "ADMIN,AUDITOR" {
+		t.Errorf("roles() = %s, want ADMIN,AUDITOR", got)
+	}
+}
@@ -0,0 +1,96 @@
+// Package requestid carries the id of a request through its context, so that
+// logs, database queries and calls to other services can be correlated.
+package requestid
+
+import (
+	"application/pkg/util"
+	"context"
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	Header = "X-Request-ID"
+
+	// maxLength bounds the ids accepted from clients.
+	maxLength = 128
+)
+
+type contextKey struct{}
+
+// New generates a request id.
+func New() string {
+	return util.GenerateRequestId()
+}
+
+// Valid tells whether an id sent by a client can be reused: not empty, not
+// too long and made of printable ASCII without spaces, so that it cannot
+// forge log lines or headers.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewContext returns a context carrying the request id and a logger adding it
+// to every entry.
+func NewContext(ctx context.Context, id string) context.Context {
+	logger := log.With().Str("request_id", id).Logger()
+
+	return logger.WithContext(context.WithValue(ctx, contextKey{}, id))
+}
+
+// FromContext returns the request id of the context, empty outside a request.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Logger returns the logger of the request, the global logger outside a request.
+func Logger(ctx context.Context) *zerolog.Logger {
+	if ctx != nil {
+		if logger := zerolog.Ctx(ctx); logger.GetLevel() != zerolog.Disabled {
+			return logger
+		}
+	}
+
+	return &log.Logger
+}
+
+// Transport forwards the request id of the context to the called service.
+type Transport struct {
+	// Base defaults to http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	id := FromContext(req.Context())
+	if id == "" || req.Header.Get(Header) != "" {
+		return base.RoundTrip(req)
+	}
+
+	// a RoundTripper must not modify the request
+	req = req.Clone(req.Context())
+	req.Header.Set(Header, id)
+
+	return base.RoundTrip(req)
+}
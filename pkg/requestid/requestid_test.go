@@ -0,0 +1,122 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "uuid", id: "0b7c4a6e-7f8e-4a35-9d5e-1c4f9f2f6a10", want: true},
+		{name: "printable", id: "req_42:retry/1", want: true},
+		{name: "empty", id: "", want: false},
+		{name: "too long", id: strings.Repeat("a", maxLength+1), want: false},
+		{name: "longest", id: strings.Repeat("a", maxLength), want: true},
+		{name: "space", id: "req 42", want: false},
+		{name: "new line", id: "req\n{\"level\":\"error\"}", want: false},
+		{name: "not ascii", id: "réq", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.id); got != tt.want {
+				t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+
+	if id := New(); !Valid(id) || id == New() {
+		t.Errorf("New() = %q, want a valid unique id", id)
+	}
+}
+
+func TestContext(t *testing.T) {
+	if id := FromContext(context.Background()); id != "" {
+		t.Errorf("FromContext(background) = %q, want none", id)
+	}
+
+	var out bytes.Buffer
+	logger := log.Logger
+	log.Logger = zerolog.New(&out)
+	t.Cleanup(func() { log.Logger = logger })
+
+	ctx := NewContext(context.Background(), "request-1")
+	if id := FromContext(ctx); id != "request-1" {
+		t.Errorf("FromContext() = %q, want %q", id, "request-1")
+	}
+
+	Logger(ctx).Info().Msg("in the request")
+	Logger(context.Background()).Info().Msg("outside a request")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log lines = %q, want 2", lines)
+	}
+
+	for i, want := range []string{"request-1", ""} {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := entry["request_id"].(string); got != want {
+			t.Errorf("request_id of %q = %q, want %q", entry["message"], got, want)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(Header))
+	}))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: &Transport{}}
+
+	send := func(ctx context.Context, header string) *http.Request {
+		t.Helper()
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header != "" {
+			request.Header.Set(Header, header)
+		}
+
+		response, err := client.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+
+		return request
+	}
+
+	request := send(NewContext(context.Background(), "request-1"), "")
+	send(NewContext(context.Background(), "request-1"), "explicit")
+	send(context.Background(), "")
+
+	want := []string{"request-1", "explicit", ""}
+	for i := range want {
+		if received[i] != want[i] {
+			t.Errorf("request %d sent id %q, want %q", i, received[i], want[i])
+		}
+	}
+
+	if request.Header.Get(Header) != "" {
+		t.Error("Transport modified the request of the caller")
+	}
+}
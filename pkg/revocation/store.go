@@ -0,0 +1,215 @@
+package revocation
+
+import (
+	"application/app/models"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultSyncInterval    = 10 * time.Second
+	DefaultCleanupInterval = time.Hour
+)
+
+// Backend persists revocations so every instance of the service shares them.
+type Backend interface {
+	CreateTokenRevocation(ctx context.Context, revocation *models.TokenRevocation) error
+	FindActiveTokenRevocations(ctx context.Context, now time.Time) ([]*models.TokenRevocation, error)
+	DeleteExpiredTokenRevocations(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Store is a denylist of access tokens. Revocations are kept in memory and
+// synchronised from the backend, so checking a token never hits the database.
+type Store struct {
+	backend         Backend
+	syncInterval    time.Duration
+	cleanupInterval time.Duration
+
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[int64]userRevocation
+}
+
+type userRevocation struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+// NewStore creates a revocation store. Zero intervals fall back to the defaults.
+func NewStore(backend Backend, syncInterval time.Duration, cleanupInterval time.Duration) *Store {
+	if syncInterval <= 0 {
+		syncInterval = DefaultSyncInterval
+	}
+
+	if cleanupInterval <= 0 {
+		cleanupInterval = DefaultCleanupInterval
+	}
+
+	return &Store{
+		backend:         backend,
+		syncInterval:    syncInterval,
+		cleanupInterval: cleanupInterval,
+		tokens:          make(map[string]time.Time),
+		users:           make(map[int64]userRevocation),
+	}
+}
+
+// Start loads the revocations and keeps them in sync until the context is done.
+// Expired revocations are removed from the backend on every cleanup interval.
+func (s *Store) Start(ctx context.Context) error {
+	if err := s.Sync(ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		syncTicker := time.NewTicker(s.syncInterval)
+		defer syncTicker.Stop()
+
+		cleanupTicker := time.NewTicker(s.cleanupInterval)
+		defer cleanupTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-syncTicker.C:
+				if err := s.Sync(ctx); err != nil {
+					log.Error().Err(err).Msg("failed to sync token revocations")
+				}
+			case <-cleanupTicker.C:
+				deleted, err := s.backend.DeleteExpiredTokenRevocations(ctx, time.Now())
+				if err != nil {
+					log.Error().Err(err).Msg("failed to clean up token revocations")
+					continue
+				}
+				log.Debug().Int64("deleted", deleted).Msg("expired token revocations cleaned up")
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Sync loads the unexpired revocations of the backend. Revocations made by
+// this instance while the query ran are kept, so that a token revoked here is
+// never accepted again until the next sync.
+func (s *Store) Sync(ctx context.Context) error {
+	now := time.Now()
+
+	revocations, err := s.backend.FindActiveTokenRevocations(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	tokens := make(map[string]time.Time, len(revocations))
+	users := make(map[int64]userRevocation)
+	for _, revocation := range revocations {
+		if revocation.Jti != nil {
+			tokens[*revocation.Jti] = revocation.ExpiresAt
+			continue
+		}
+
+		if revocation.UserId != nil {
+			mergeUserRevocation(users, *revocation.UserId, userRevocation{revokedAt: revocation.RevokedAt, expiresAt: revocation.ExpiresAt})
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for jti, expiresAt := range s.tokens {
+		if _, ok := tokens[jti]; !ok && expiresAt.After(now) {
+			tokens[jti] = expiresAt
+		}
+	}
+
+	for userId, revocation := range s.users {
+		if revocation.expiresAt.After(now) {
+			mergeUserRevocation(users, userId, revocation)
+		}
+	}
+
+	s.tokens = tokens
+	s.users = users
+
+	return nil
+}
+
+// mergeUserRevocation keeps the latest revocation of the user.
+func mergeUserRevocation(users map[int64]userRevocation, userId int64, revocation userRevocation) {
+	if current, ok := users[userId]; !ok || revocation.revokedAt.After(current.revokedAt) {
+		users[userId] = revocation
+	}
+}
+
+// IsRevoked reports whether the token was revoked by its jti, or was issued
+// before every token of its user got revoked.
+func (s *Store) IsRevoked(jti string, userId int64, issuedAt time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+
+	if jti != "" {
+		if expiresAt, ok := s.tokens[jti]; ok && expiresAt.After(now) {
+			return true
+		}
+	}
+
+	if revocation, ok := s.users[userId]; ok && revocation.expiresAt.After(now) {
+		return !issuedAt.After(revocation.revokedAt)
+	}
+
+	return false
+}
+
+// RevokeToken denies a single token until it expires.
+func (s *Store) RevokeToken(ctx context.Context, jti string, userId int64, expiresAt time.Time, reason string) error {
+	revocation := &models.TokenRevocation{
+		Jti:       &jti,
+		UserId:    &userId,
+		Reason:    reason,
+		RevokedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+
+	if err := s.backend.CreateTokenRevocation(ctx, revocation); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[jti] = expiresAt
+
+	return nil
+}
+
+// RevokeUser denies every token of the user issued up to now. The entry is kept
+// for lifetime, after which every token issued before it has expired anyway.
+// The time is kept to the microsecond the database stores, so tokens issued
+// later in the same second stay valid.
+func (s *Store) RevokeUser(ctx context.Context, userId int64, lifetime time.Duration, reason string) error {
+	now := time.Now().Truncate(time.Microsecond)
+
+	revocation := &models.TokenRevocation{
+		UserId:    &userId,
+		Reason:    reason,
+		RevokedAt: now,
+		ExpiresAt: now.Add(lifetime),
+	}
+
+	if err := s.backend.CreateTokenRevocation(ctx, revocation); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mergeUserRevocation(s.users, userId, userRevocation{revokedAt: revocation.RevokedAt, expiresAt: revocation.ExpiresAt})
+
+	return nil
+}
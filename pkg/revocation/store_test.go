@@ -0,0 +1,217 @@
+package revocation
+
+import (
+	"application/app/models"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryBackend keeps the revocations in a slice. beforeFind runs after the
+// query took its snapshot, like a revocation written while a sync is in flight.
+type memoryBackend struct {
+	mu          sync.Mutex
+	revocations []*models.TokenRevocation
+	beforeFind  func()
+	err         error
+}
+
+func (b *memoryBackend) CreateTokenRevocation(_ context.Context, revocation *models.TokenRevocation) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+
+	b.revocations = append(b.revocations, revocation)
+
+	return nil
+}
+
+func (b *memoryBackend) FindActiveTokenRevocations(_ context.Context, now time.Time) ([]*models.TokenRevocation, error) {
+	b.mu.Lock()
+	var active []*models.TokenRevocation
+	for _, revocation := range b.revocations {
+		if revocation.ExpiresAt.After(now) {
+			active = append(active, revocation)
+		}
+	}
+	hook := b.beforeFind
+	b.beforeFind = nil
+	b.mu.Unlock()
+
+	if hook != nil {
+		hook()
+	}
+
+	return active, b.err
+}
+
+func (b *memoryBackend) DeleteExpiredTokenRevocations(_ context.Context, now time.Time) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var kept []*models.TokenRevocation
+	for _, revocation := range b.revocations {
+		if revocation.ExpiresAt.After(now) {
+			kept = append(kept, revocation)
+		}
+	}
+
+	deleted := int64(len(b.revocations) - len(kept))
+	b.revocations = kept
+
+	return deleted, nil
+}
+
+func TestIsRevoked(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(&memoryBackend{}, 0, 0)
+
+	if err := store.RevokeToken(ctx, "revoked", 1, time.Now().Add(time.Hour), "logout"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RevokeToken(ctx, "expired", 1, time.Now().Add(-time.Second), "logout"); err != nil {
+		t.Fatal(err)
+	}
+
+	before := time.Now().Add(-time.Millisecond)
+	if err := store.RevokeUser(ctx, 2, time.Hour, "logout_all"); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now().Add(time.Microsecond)
+
+	tests := []struct {
+		name     string
+		jti      string
+		userId   int64
+		issuedAt time.Time
+		want     bool
+	}{
+		{"revoked jti", "revoked", 1, after, true},
+		{"expired jti", "expired", 1, after, false},
+		{"other jti", "other", 1, before, false},
+		{"token of a revoked user", "other", 2, before, true},
+		{"token issued after the revocation", "other", 2, after, false},
+		{"token of another user", "other", 3, before, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := store.IsRevoked(tt.jti, tt.userId, tt.issuedAt); got != tt.want {
+				t.Errorf("IsRevoked(%q, %d) = %v, want %v", tt.jti, tt.userId, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevokeUserKeepsSubsecondPrecision(t *testing.T) {
+	backend := &memoryBackend{}
+	store := NewStore(backend, 0, 0)
+
+	if err := store.RevokeUser(context.Background(), 1, time.Hour, "logout_all"); err != nil {
+		t.Fatal(err)
+	}
+
+	revokedAt := backend.revocations[0].RevokedAt
+
+	// a token issued within the same second, just after the revocation
+	if store.IsRevoked("", 1, revokedAt.Add(time.Millisecond)) {
+		t.Error("token issued after the revocation is revoked")
+	}
+	if !store.IsRevoked("", 1, revokedAt) {
+		t.Error("token issued at the revocation is not revoked")
+	}
+}
+
+func TestRevokeFailsWithTheBackend(t *testing.T) {
+	ctx := context.Background()
+	backend := &memoryBackend{err: errors.New("database is down")}
+	store := NewStore(backend, 0, 0)
+
+	if err := store.RevokeToken(ctx, "jti", 1, time.Now().Add(time.Hour), "logout"); err == nil {
+		t.Error("RevokeToken() error = nil, want the backend error")
+	}
+	if store.IsRevoked("jti", 1, time.Now()) {
+		t.Error("token is revoked in memory although the backend failed")
+	}
+}
+
+func TestSyncLoadsOtherInstances(t *testing.T) {
+	ctx := context.Background()
+	backend := &memoryBackend{}
+
+	other := NewStore(backend, 0, 0)
+	if err := other.RevokeToken(ctx, "jti", 1, time.Now().Add(time.Hour), "logout"); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.RevokeUser(ctx, 2, time.Hour, "logout_all"); err != nil {
+		t.Fatal(err)
+	}
+	issuedAt := time.Now().Add(-time.Minute)
+
+	store := NewStore(backend, 0, 0)
+	if store.IsRevoked("jti", 1, issuedAt) {
+		t.Fatal("token is revoked before the sync")
+	}
+
+	if err := store.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if !store.IsRevoked("jti", 1, issuedAt) {
+		t.Error("revoked jti of another instance is accepted")
+	}
+	if !store.IsRevoked("other", 2, issuedAt) {
+		t.Error("revoked user of another instance is accepted")
+	}
+}
+
+func TestSyncKeepsLocalRevocations(t *testing.T) {
+	ctx := context.Background()
+	backend := &memoryBackend{}
+	store := NewStore(backend, 0, 0)
+	issuedAt := time.Now().Add(-time.Minute)
+
+	backend.beforeFind = func() {
+		if err := store.RevokeToken(ctx, "jti", 1, time.Now().Add(time.Hour), "logout"); err != nil {
+			t.Error(err)
+		}
+		if err := store.RevokeUser(ctx, 2, time.Hour, "logout_all"); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := store.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if !store.IsRevoked("jti", 1, issuedAt) {
+		t.Error("token revoked during the sync is accepted")
+	}
+	if !store.IsRevoked("other", 2, issuedAt) {
+		t.Error("user revoked during the sync is accepted")
+	}
+}
+
+func TestSyncFailureKeepsTheDenylist(t *testing.T) {
+	ctx := context.Background()
+	backend := &memoryBackend{}
+	store := NewStore(backend, 0, 0)
+
+	if err := store.RevokeToken(ctx, "jti", 1, time.Now().Add(time.Hour), "logout"); err != nil {
+		t.Fatal(err)
+	}
+
+	backend.err = errors.New("database is down")
+	if err := store.Sync(ctx); err == nil {
+		t.Fatal("Sync() error = nil, want the backend error")
+	}
+
+	if !store.IsRevoked("jti", 1, time.Now()) {
+		t.Error("revoked token is accepted after a failed sync")
+	}
+}
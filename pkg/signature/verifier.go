@@ -0,0 +1,181 @@
+package signature
+
+import (
+	"application/app/models"
+	"application/pkg/secretbox"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	HeaderClientId  = "X-Client-Id"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+
+	DefaultReplayWindow = 5 * time.Minute
+
+	// ScopePaymentCallback lets a partner report payment statuses.
+	ScopePaymentCallback = "payments:callback"
+
+	// MaxNonceLength bounds the nonces stored for replay protection.
+	MaxNonceLength = 128
+)
+
+var (
+	ErrMissingHeaders       = errors.New("missing signature headers")
+	ErrUnknownClient        = errors.New("unknown signing client")
+	ErrClientDisabled       = errors.New("signing client is disabled")
+	ErrTimestampOutOfWindow = errors.New("request timestamp outside the replay window")
+	ErrInvalidNonce         = errors.New("request nonce too long")
+	ErrReplayedNonce        = errors.New("request nonce already used")
+	ErrInvalidSignature     = errors.New("invalid request signature")
+)
+
+// Request holds the signed parts of an HTTP request.
+type Request struct {
+	ClientId  string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Method    string
+	// Path is the request URI, query string included.
+	Path string
+	Body []byte
+}
+
+// Canonical is the string signed by clients:
+//
+//	METHOD\nPATH\nTIMESTAMP\nNONCE\nhex(sha256(BODY))
+func Canonical(method string, path string, timestamp string, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the canonical string.
+func Sign(secret []byte, canonical string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(canonical))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backend loads the signing clients and records the used nonces, shared by
+// every instance.
+type Backend interface {
+	FindSigningClientByClientId(ctx context.Context, clientId string) (*models.SigningClient, error)
+	// UseRequestNonce reports false when the nonce is recorded and not expired.
+	UseRequestNonce(ctx context.Context, clientId string, nonce string, expiresAt time.Time, now time.Time) (bool, error)
+	DeleteExpiredRequestNonces(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Verifier checks request signatures. Timestamps must fall within the replay
+// window and nonces are remembered for twice the window, so a captured request
+// cannot be sent again. Nonces are stored in the database so that a request
+// cannot be replayed against another instance either.
+type Verifier struct {
+	backend Backend
+	box     *secretbox.Box
+	window  time.Duration
+}
+
+func NewVerifier(backend Backend, box *secretbox.Box, window time.Duration) *Verifier {
+	if window <= 0 {
+		window = DefaultReplayWindow
+	}
+
+	return &Verifier{
+		backend: backend,
+		box:     box,
+		window:  window,
+	}
+}
+
+// StartJanitor deletes the expired nonces every replay window until the context is done.
+func (v *Verifier) StartJanitor(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(v.window)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := v.backend.DeleteExpiredRequestNonces(ctx, time.Now()); err != nil {
+					log.Error().Err(err).Msg("failed to delete expired request nonces")
+				}
+			}
+		}
+	}()
+}
+
+// Verify returns the client that signed the request.
+func (v *Verifier) Verify(ctx context.Context, request *Request) (*models.SigningClient, error) {
+	if request.ClientId == "" || request.Timestamp == "" || request.Nonce == "" || request.Signature == "" {
+		return nil, ErrMissingHeaders
+	}
+
+	if len(request.Nonce) > MaxNonceLength {
+		return nil, ErrInvalidNonce
+	}
+
+	timestamp, err := strconv.ParseInt(request.Timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrTimestampOutOfWindow
+	}
+
+	if age := time.Since(time.Unix(timestamp, 0)); age > v.window || age < -v.window {
+		return nil, ErrTimestampOutOfWindow
+	}
+
+	client, err := v.backend.FindSigningClientByClientId(ctx, request.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil {
+		return nil, ErrUnknownClient
+	}
+
+	if !client.IsEnabled {
+		return nil, ErrClientDisabled
+	}
+
+	secret, err := v.box.Open(client.SecretCiphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := Sign(secret, Canonical(request.Method, request.Path, request.Timestamp, request.Nonce, request.Body))
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(request.Signature))) {
+		return nil, ErrInvalidSignature
+	}
+
+	// only remember nonces of valid signatures so that forged requests cannot burn them
+	now := time.Now()
+	fresh, err := v.backend.UseRequestNonce(ctx, client.ClientId, request.Nonce, now.Add(2*v.window), now)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, ErrReplayedNonce
+	}
+
+	return client, nil
+}
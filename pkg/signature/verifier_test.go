@@ -0,0 +1,176 @@
+package signature
+
+import (
+	"application/app/models"
+	"application/pkg/secretbox"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryBackend keeps the clients and the used nonces in maps like the
+// signing_clients and request_nonces tables.
+type memoryBackend struct {
+	mu      sync.Mutex
+	clients map[string]*models.SigningClient
+	nonces  map[string]time.Time
+}
+
+func (b *memoryBackend) FindSigningClientByClientId(_ context.Context, clientId string) (*models.SigningClient, error) {
+	return b.clients[clientId], nil
+}
+
+func (b *memoryBackend) UseRequestNonce(_ context.Context, clientId string, nonce string, expiresAt time.Time, now time.Time) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := clientId + "|" + nonce
+	if current, ok := b.nonces[key]; ok && current.After(now) {
+		return false, nil
+	}
+	b.nonces[key] = expiresAt
+
+	return true, nil
+}
+
+func (b *memoryBackend) DeleteExpiredRequestNonces(_ context.Context, now time.Time) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var deleted int64
+	for key, expiresAt := range b.nonces {
+		if !expiresAt.After(now) {
+			delete(b.nonces, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+var testSecret = []byte("partner-secret")
+
+func newTestVerifier(t *testing.T) (*Verifier, *memoryBackend) {
+	t.Helper()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	box, err := secretbox.NewBox(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := box.Seal(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &memoryBackend{
+		clients: map[string]*models.SigningClient{
+			"acme":     {Id: 1, ClientId: "acme", SecretCiphertext: ciphertext, Scopes: models.StringList{ScopePaymentCallback}, IsEnabled: true},
+			"disabled": {Id: 2, ClientId: "disabled", SecretCiphertext: ciphertext},
+		},
+		nonces: make(map[string]time.Time),
+	}
+
+	return NewVerifier(backend, box, time.Minute), backend
+}
+
+func signedRequest(clientId string, timestamp time.Time, nonce string, body string) *Request {
+	request := &Request{
+		ClientId:  clientId,
+		Timestamp: strconv.FormatInt(timestamp.Unix(), 10),
+		Nonce:     nonce,
+		Method:    "POST",
+		Path:      "/api/v1/partners/payments/callback?attempt=1",
+		Body:      []byte(body),
+	}
+	request.Signature = Sign(testSecret, Canonical(request.Method, request.Path, request.Timestamp, request.Nonce, request.Body))
+
+	return request
+}
+
+func TestCanonical(t *testing.T) {
+	got := Canonical("post", "/callback?a=1", "1700000000", "nonce", []byte("{}"))
+	want := "POST\n/callback?a=1\n1700000000\nnonce\n44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+
+	if got != want {
+		t.Errorf("Canonical() = %q, want %q", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		change func(r *Request)
+		want   error
+	}{
+		{"valid", func(r *Request) {}, nil},
+		{"upper case signature", func(r *Request) { r.Signature = strings.ToUpper(r.Signature) }, nil},
+		{"missing signature", func(r *Request) { r.Signature = "" }, ErrMissingHeaders},
+		{"missing nonce", func(r *Request) { r.Nonce = "" }, ErrMissingHeaders},
+		{"nonce too long", func(r *Request) { r.Nonce = strings.Repeat("n", MaxNonceLength+1) }, ErrInvalidNonce},
+		{"invalid timestamp", func(r *Request) { r.Timestamp = "yesterday" }, ErrTimestampOutOfWindow},
+		{"old timestamp", func(r *Request) { r.Timestamp = strconv.FormatInt(now.Add(-2*time.Minute).Unix(), 10) }, ErrTimestampOutOfWindow},
+		{"future timestamp", func(r *Request) { r.Timestamp = strconv.FormatInt(now.Add(2*time.Minute).Unix(), 10) }, ErrTimestampOutOfWindow},
+		{"unknown client", func(r *Request) { r.ClientId = "unknown" }, ErrUnknownClient},
+		{"disabled client", func(r *Request) { r.ClientId = "disabled" }, ErrClientDisabled},
+		{"changed body", func(r *Request) { r.Body = []byte(`{"status":"PAID"}`) }, ErrInvalidSignature},
+		{"changed path", func(r *Request) { r.Path = "/api/v1/partners/payments/callback?attempt=2" }, ErrInvalidSignature},
+		{"changed method", func(r *Request) { r.Method = "PUT" }, ErrInvalidSignature},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, _ := newTestVerifier(t)
+			request := signedRequest("acme", now, "nonce-"+strconv.Itoa(i), `{"status":"FAILED"}`)
+			tt.change(request)
+
+			client, err := verifier.Verify(context.Background(), request)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && client.ClientId != "acme" {
+				t.Errorf("Verify() client = %s, want acme", client.ClientId)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsReplayedNonces(t *testing.T) {
+	ctx := context.Background()
+	verifier, backend := newTestVerifier(t)
+	request := signedRequest("acme", time.Now(), "nonce", "{}")
+
+	if _, err := verifier.Verify(ctx, request); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if _, err := verifier.Verify(ctx, request); !errors.Is(err, ErrReplayedNonce) {
+		t.Fatalf("replayed Verify() error = %v, want %v", err, ErrReplayedNonce)
+	}
+
+	// the nonce outlives the replay window of its timestamp
+	if expiresAt := backend.nonces["acme|nonce"]; expiresAt.Before(time.Now().Add(time.Minute)) {
+		t.Errorf("nonce expires at %v, want after the replay window", expiresAt)
+	}
+
+	// a forged request does not burn the nonce of the partner
+	forged := signedRequest("acme", time.Now(), "next", "{}")
+	forged.Signature = Sign([]byte("guess"), "guess")
+	if _, err := verifier.Verify(ctx, forged); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("forged Verify() error = %v, want %v", err, ErrInvalidSignature)
+	}
+	if _, err := verifier.Verify(ctx, signedRequest("acme", time.Now(), "next", "{}")); err != nil {
+		t.Errorf("Verify() after a forged request error = %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package util
 
-import gonanoid "github.com/matoous/go-nanoid/v2"
+import (
+	"github.com/google/uuid"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+)
 
 const (
 	ALPHA_NUMERIC_LOW_UPPER_CHAR_SET = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
@@ -16,3 +19,8 @@ func GenerateRandomAlphaNumericString(i int) string {
 	alpha := gonanoid.MustGenerate(ALPHA_NUMERIC_SET, i)
 	return alpha
 }
+
+// GenerateRequestId returns a new id correlating the logs of one request.
+func GenerateRequestId() string {
+	return uuid.NewString()
+}
@@ -1,17 +1,121 @@
 package util
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"fmt"
+	"sync"
 
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	passwordHashParams   = DefaultArgon2idParams
+	passwordHashParamsMu sync.RWMutex
+
+	dummyPasswordHash   string
+	dummyPasswordHashMu sync.Mutex
+)
+
+// SetPasswordHashParams changes the argon2id cost of new password hashes.
+// Zero values keep the defaults.
+func SetPasswordHashParams(params Argon2idParams) {
+	if params.Memory == 0 {
+		params.Memory = DefaultArgon2idParams.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = DefaultArgon2idParams.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = DefaultArgon2idParams.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = DefaultArgon2idParams.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = DefaultArgon2idParams.KeyLength
+	}
+
+	passwordHashParamsMu.Lock()
+	defer passwordHashParamsMu.Unlock()
+
+	passwordHashParams = params
+}
+
+func currentPasswordHashParams() Argon2idParams {
+	passwordHashParamsMu.RLock()
+	defer passwordHashParamsMu.RUnlock()
+
+	return passwordHashParams
+}
+
+// HashPassword hashes with argon2id using the configured parameters.
 func HashPassword(password string) (string, error) {
+	return HashArgon2id(password, currentPasswordHashParams())
+}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// PrepareDummyPasswordHash creates the hash returned by DummyPasswordHash with
+// the configured cost. It is called at startup, after SetPasswordHashParams,
+// so that a failure stops the application instead of weakening the logins.
+func PrepareDummyPasswordHash() error {
+	hash, err := HashPassword(GenerateRandomString(32))
 	if err != nil {
-		return "", err
+		return fmt.Errorf("failed to prepare dummy password hash: %w", err)
 	}
 
-	return string(hash), nil
+	dummyPasswordHashMu.Lock()
+	defer dummyPasswordHashMu.Unlock()
+
+	dummyPasswordHash = hash
+
+	return nil
 }
 
+// DummyPasswordHash is compared against when the account is unknown, so that
+// the response time does not tell whether it exists. A hash not prepared at
+// startup is prepared on first use; it panics rather than returning an empty
+// hash that would answer unknown accounts faster.
+func DummyPasswordHash() string {
+	dummyPasswordHashMu.Lock()
+	hash := dummyPasswordHash
+	dummyPasswordHashMu.Unlock()
+
+	if hash != "" {
+		return hash
+	}
+
+	if err := PrepareDummyPasswordHash(); err != nil {
+		panic(err)
+	}
+
+	return DummyPasswordHash()
+}
+
+// ComparePassword accepts argon2id hashes and the bcrypt hashes created before.
 func ComparePassword(hashPassword string, password string) error {
+	if IsArgon2idHash(hashPassword) {
+		return CompareArgon2id(hashPassword, password)
+	}
+
 	return bcrypt.CompareHashAndPassword([]byte(hashPassword), []byte(password))
 }
+
+// PasswordNeedsRehash reports whether the hash was made with bcrypt or with
+// other argon2id parameters than the configured ones. It is meant to be
+// called after a successful comparison, while the plaintext is at hand.
+func PasswordNeedsRehash(hashPassword string) bool {
+	if !IsArgon2idHash(hashPassword) {
+		return true
+	}
+
+	params, _, _, err := decodeArgon2id(hashPassword)
+	if err != nil {
+		return true
+	}
+
+	current := currentPasswordHashParams()
+
+	return params.Memory != current.Memory ||
+		params.Iterations != current.Iterations ||
+		params.Parallelism != current.Parallelism ||
+		params.KeyLength != current.KeyLength ||
+		params.SaltLength != current.SaltLength
+}
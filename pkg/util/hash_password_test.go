@@ -0,0 +1,119 @@
+package util
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2idParams keep the tests fast.
+var testArgon2idParams = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func setTestPasswordHashParams(t *testing.T, params Argon2idParams) {
+	t.Helper()
+
+	previous := currentPasswordHashParams()
+	SetPasswordHashParams(params)
+	t.Cleanup(func() { SetPasswordHashParams(previous) })
+}
+
+func TestHashArgon2id(t *testing.T) {
+	setTestPasswordHashParams(t, testArgon2idParams)
+
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("HashPassword() = %q, want a PHC string with the parameters", hash)
+	}
+
+	other, _ := HashPassword("correct horse")
+	if other == hash {
+		t.Error("HashPassword() returned the same hash twice, want a random salt")
+	}
+
+	if err := ComparePassword(hash, "correct horse"); err != nil {
+		t.Errorf("ComparePassword() error = %v", err)
+	}
+	if err := ComparePassword(hash, "wrong horse"); !errors.Is(err, ErrMismatchedHashAndPassword) {
+		t.Errorf("ComparePassword() with a wrong password error = %v, want %v", err, ErrMismatchedHashAndPassword)
+	}
+}
+
+func TestCompareArgon2idRejectsInvalidHashes(t *testing.T) {
+	for _, hash := range []string{
+		"$argon2id$",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$",
+	} {
+		if err := CompareArgon2id(hash, "secret"); !errors.Is(err, ErrInvalidArgon2idHash) {
+			t.Errorf("CompareArgon2id(%q) error = %v, want %v", hash, err, ErrInvalidArgon2idHash)
+		}
+	}
+}
+
+func TestComparePasswordAcceptsBcrypt(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ComparePassword(string(hash), "correct horse"); err != nil {
+		t.Errorf("ComparePassword() of a bcrypt hash error = %v", err)
+	}
+	if err := ComparePassword(string(hash), "wrong horse"); err == nil {
+		t.Error("ComparePassword() of a bcrypt hash with a wrong password error = nil")
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	setTestPasswordHashParams(t, testArgon2idParams)
+
+	current, _ := HashPassword("secret")
+	cheaper, _ := HashArgon2id("secret", Argon2idParams{Memory: 512, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	shorter, _ := HashArgon2id("secret", Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 16})
+	bcryptHash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{"current parameters", current, false},
+		{"other memory", cheaper, true},
+		{"other key length", shorter, true},
+		{"bcrypt", string(bcryptHash), true},
+		{"invalid", "$argon2id$invalid", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PasswordNeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("PasswordNeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDummyPasswordHash(t *testing.T) {
+	setTestPasswordHashParams(t, testArgon2idParams)
+
+	if err := PrepareDummyPasswordHash(); err != nil {
+		t.Fatalf("PrepareDummyPasswordHash() error = %v", err)
+	}
+
+	hash := DummyPasswordHash()
+	if !IsArgon2idHash(hash) || PasswordNeedsRehash(hash) {
+		t.Errorf("DummyPasswordHash() = %q, want a hash with the configured cost", hash)
+	}
+	if DummyPasswordHash() != hash {
+		t.Error("DummyPasswordHash() changed, want it prepared once")
+	}
+}
@@ -0,0 +1,23 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashSecret digests a generated secret, such as the secret of a basic auth or
+// OAuth2 client, with HMAC-SHA256 under key. Generated secrets are too random
+// to be guessed, so unlike passwords they need no slow hash, and rejecting bad
+// credentials stays cheap.
+func HashSecret(key []byte, secret string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(secret))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CompareSecret reports in constant time whether the secret matches the digest.
+func CompareSecret(key []byte, digest string, secret string) bool {
+	return hmac.Equal([]byte(HashSecret(key, secret)), []byte(digest))
+}
@@ -0,0 +1,177 @@
+package validation
+
+import (
+	"application/app/enums"
+	"application/pkg/i18n"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	TagPhone  = "phone"
+	TagNik    = "nik"
+	TagEnum   = "enum"
+	TagDateTz = "datetz"
+	TagFuture = "future"
+)
+
+var (
+	// phonePattern accepts international numbers (E.164, with or without +)
+	// and Indonesian local numbers starting with 0.
+	phonePattern = regexp.MustCompile(`^(\+?[1-9][0-9]{7,14}|0[0-9]{8,13})$`)
+	// phoneSeparators may be used to group digits.
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+	nikPattern = regexp.MustCompile(`^[0-9]{16}$`)
+
+	enumRegistry   = make(map[string]func() []string)
+	enumRegistryMu sync.RWMutex
+)
+
+func init() {
+	RegisterEnum("admin_role", enumValues(enums.AdminRoles))
+	RegisterEnum("permission", enumValues(enums.Permissions))
+	RegisterEnum("rate_limit_tier", enumValues(enums.RateLimitTiers))
+	RegisterEnum("locale", i18n.Locales)
+}
+
+func enumValues[T ~string](values func() []T) func() []string {
+	return func() []string {
+		codes := values()
+		result := make([]string, len(codes))
+		for i, code := range codes {
+			result[i] = string(code)
+		}
+		return result
+	}
+}
+
+// RegisterEnum makes the values of an enum available to the enum=<name> rule.
+func RegisterEnum(name string, values func() []string) {
+	enumRegistryMu.Lock()
+	defer enumRegistryMu.Unlock()
+
+	enumRegistry[name] = values
+}
+
+// EnumValues returns the values of a registered enum.
+func EnumValues(name string) ([]string, bool) {
+	enumRegistryMu.RLock()
+	defer enumRegistryMu.RUnlock()
+
+	values, ok := enumRegistry[name]
+	if !ok {
+		return nil, false
+	}
+
+	return values(), true
+}
+
+// IsPhone tells whether the value is a phone number, ignoring spaces, dashes,
+// dots and parentheses between the digits.
+func IsPhone(value string) bool {
+	return phonePattern.MatchString(phoneSeparators.Replace(strings.TrimSpace(value)))
+}
+
+// IsNik tells whether the value is a well-formed Indonesian NIK: 16 digits
+// made of the region code, the birth date (day plus 40 for women) and a
+// serial number that is not zero.
+func IsNik(value string) bool {
+	if !nikPattern.MatchString(value) {
+		return false
+	}
+
+	province, _ := strconv.Atoi(value[0:2])
+	if province < 11 || province > 94 {
+		return false
+	}
+
+	day, _ := strconv.Atoi(value[6:8])
+	month, _ := strconv.Atoi(value[8:10])
+	year, _ := strconv.Atoi(value[10:12])
+	if day > 40 {
+		day -= 40
+	}
+
+	// the century is unknown, 2000 is a leap year so 29 February is accepted
+	birth := time.Date(2000+year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if birth.Day() != day || int(birth.Month()) != month {
+		return false
+	}
+
+	return value[12:] != "0000"
+}
+
+// IsDateTz tells whether the value is an RFC 3339 timestamp with an explicit
+// offset or Z, so that it is never read in the timezone of the server.
+func IsDateTz(value string) bool {
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
+
+func validatePhone(fl validator.FieldLevel) bool {
+	return IsPhone(fl.Field().String())
+}
+
+func validateNik(fl validator.FieldLevel) bool {
+	return IsNik(fl.Field().String())
+}
+
+// validateEnum checks the value, or every value of a slice, against the
+// registered enum. The comparison ignores case.
+func validateEnum(fl validator.FieldLevel) bool {
+	values, ok := EnumValues(fl.Param())
+	if !ok {
+		panic("validation: unknown enum " + fl.Param())
+	}
+
+	contains := func(value string) bool {
+		for _, allowed := range values {
+			if strings.EqualFold(allowed, value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	field := fl.Field()
+	switch field.Kind() {
+	case reflect.String:
+		return contains(field.String())
+	case reflect.Slice, reflect.Array:
+		for i := range field.Len() {
+			item := reflect.Indirect(field.Index(i))
+			if item.Kind() != reflect.String || !contains(item.String()) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func validateDateTz(fl validator.FieldLevel) bool {
+	return IsDateTz(fl.Field().String())
+}
+
+// validateFuture accepts a time.Time or an RFC 3339 string after now.
+func validateFuture(fl validator.FieldLevel) bool {
+	field := fl.Field()
+
+	switch value := field.Interface().(type) {
+	case time.Time:
+		return value.After(time.Now())
+	case string:
+		parsed, err := time.Parse(time.RFC3339, value)
+		return err == nil && parsed.After(time.Now())
+	}
+
+	return false
+}
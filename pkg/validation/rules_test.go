@@ -0,0 +1,86 @@
+package validation
+
+import "testing"
+
+func TestIsPhone(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "+6281234567890", want: true},
+		{value: "6281234567890", want: true},
+		{value: "081234567890", want: true},
+		{value: "0812-3456-7890", want: true},
+		{value: "(021) 555.1234", want: true},
+		{value: "+0812345678", want: false},
+		{value: "12345", want: false},
+		{value: "0812345678901234", want: false},
+		{value: "0812abc67890", want: false},
+		{value: "", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPhone(tt.value); got != tt.want {
+			t.Errorf("IsPhone(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIsNik(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{name: "man", value: "3201010101900001", want: true},
+		{name: "woman", value: "3201014101900001", want: true},
+		{name: "29 February of a leap year", value: "3201012902960001", want: true},
+		{name: "29 February of 2000", value: "3201012902000001", want: true},
+		{name: "29 February of another year", value: "3201012902970001", want: false},
+		{name: "unknown province", value: "1001010101900001", want: false},
+		{name: "invalid day", value: "3201013201900001", want: false},
+		{name: "invalid month", value: "3201010113900001", want: false},
+		{name: "zero serial", value: "3201010101900000", want: false},
+		{name: "too short", value: "320101010190001", want: false},
+		{name: "not digits", value: "32010101019000a1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNik(tt.value); got != tt.want {
+				t.Errorf("IsNik(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDateTz(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "2026-10-19T08:00:00Z", want: true},
+		{value: "2026-10-19T15:00:00+07:00", want: true},
+		{value: "2026-10-19T08:00:00", want: false},
+		{value: "2026-10-19", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsDateTz(tt.value); got != tt.want {
+			t.Errorf("IsDateTz(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEnumValues(t *testing.T) {
+	RegisterEnum("test_color", func() []string { return []string{"red", "blue"} })
+
+	values, ok := EnumValues("test_color")
+	if !ok || len(values) != 2 || values[0] != "red" {
+		t.Errorf("EnumValues() = %v, %v, want [red blue]", values, ok)
+	}
+
+	if _, ok := EnumValues("unknown"); ok {
+		t.Error("EnumValues(unknown) ok = true, want false")
+	}
+}
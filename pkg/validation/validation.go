@@ -0,0 +1,179 @@
+// Package validation registers the custom rules of the request binding and
+// turns validator errors into per-field errors with localized messages.
+package validation
+
+import (
+	"application/pkg/i18n"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	idtranslations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// FieldError is the error of one field, named by its JSON path.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// messages are the translations of the custom rules, {0} is the field and {1} the param.
+var messages = map[string]map[string]string{
+	i18n.English: {
+		TagPhone:  "{0} must be a valid phone number",
+		TagNik:    "{0} must be a valid 16 digit NIK",
+		TagEnum:   "{0} must be one of {1}",
+		TagDateTz: "{0} must be an RFC 3339 date with a timezone offset",
+		TagFuture: "{0} must be in the future",
+		"type":    "{0} must be a {1}",
+	},
+	i18n.Indonesian: {
+		TagPhone:  "{0} harus berupa nomor telepon yang valid",
+		TagNik:    "{0} harus berupa NIK 16 digit yang valid",
+		TagEnum:   "{0} harus salah satu dari {1}",
+		TagDateTz: "{0} harus berupa tanggal RFC 3339 dengan zona waktu",
+		TagFuture: "{0} harus di masa depan",
+		"type":    "{0} harus berupa {1}",
+	},
+}
+
+var universal = ut.New(en.New(), en.New(), id.New())
+
+// Register adds the custom rules and the translations to the validator of the
+// gin binding, and names fields by their json or form tag.
+func Register() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("validation: the binding validator is not go-playground/validator")
+	}
+
+	return RegisterValidator(v)
+}
+
+// RegisterValidator is Register for any validator instance.
+func RegisterValidator(v *validator.Validate) error {
+	v.RegisterTagNameFunc(fieldName)
+
+	rules := map[string]validator.Func{
+		TagPhone:  validatePhone,
+		TagNik:    validateNik,
+		TagEnum:   validateEnum,
+		TagDateTz: validateDateTz,
+		TagFuture: validateFuture,
+	}
+	for tag, rule := range rules {
+		if err := v.RegisterValidation(tag, rule); err != nil {
+			return fmt.Errorf("validation: failed to register %s: %w", tag, err)
+		}
+	}
+
+	defaults := map[string]func(*validator.Validate, ut.Translator) error{
+		i18n.English:    entranslations.RegisterDefaultTranslations,
+		i18n.Indonesian: idtranslations.RegisterDefaultTranslations,
+	}
+	for locale, register := range defaults {
+		trans, _ := universal.GetTranslator(locale)
+		if err := register(v, trans); err != nil {
+			return fmt.Errorf("validation: failed to register %s translations: %w", locale, err)
+		}
+
+		for tag := range rules {
+			if err := v.RegisterTranslation(tag, trans, registerMessage(tag, messages[locale][tag]), translateMessage); err != nil {
+				return fmt.Errorf("validation: failed to register %s translation of %s: %w", locale, tag, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// FieldErrors turns a binding error into per-field errors in the locale. It
+// returns false for errors not caused by the content of a field.
+func FieldErrors(err error, locale string) ([]FieldError, bool) {
+	trans, _ := universal.GetTranslator(locale)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			fields = append(fields, FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: fe.Translate(trans),
+			})
+		}
+		return fields, true
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		message := strings.NewReplacer("{0}", typeError.Field, "{1}", typeError.Type.String()).
+			Replace(messages[trans.Locale()]["type"])
+
+		return []FieldError{{
+			Field:   typeError.Field,
+			Rule:    "type",
+			Param:   typeError.Type.String(),
+			Message: message,
+		}}, true
+	}
+
+	return nil, false
+}
+
+// fieldName names a struct field by its json tag, else its form tag.
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+// fieldPath drops the struct name leading the namespace.
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+
+	return namespace
+}
+
+func registerMessage(tag string, message string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(tag, message, true)
+	}
+}
+
+func translateMessage(trans ut.Translator, fe validator.FieldError) string {
+	param := fe.Param()
+	if fe.Tag() == TagEnum {
+		if values, ok := EnumValues(param); ok {
+			param = strings.Join(values, ", ")
+		}
+	}
+
+	message, err := trans.T(fe.Tag(), fe.Field(), param)
+	if err != nil {
+		return fe.Error()
+	}
+
+	return message
+}
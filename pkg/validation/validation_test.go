@@ -0,0 +1,125 @@
+package validation
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	testValidator     *validator.Validate
+	testValidatorOnce sync.Once
+)
+
+// newTestValidator registers the rules once, the translations are shared by
+// every validator.
+func newTestValidator(t *testing.T) *validator.Validate {
+	t.Helper()
+
+	var err error
+	testValidatorOnce.Do(func() {
+		testValidator = validator.New()
+		err = RegisterValidator(testValidator)
+	})
+	if err != nil {
+		t.Fatalf("RegisterValidator() error = %v", err)
+	}
+
+	return testValidator
+}
+
+type testContact struct {
+	Name     string     `json:"name" validate:"required"`
+	Phone    string     `json:"phone" validate:"omitempty,phone"`
+	Nik      string     `json:"nik" validate:"omitempty,nik"`
+	Role     string     `json:"role" validate:"omitempty,enum=admin_role"`
+	Roles    []string   `json:"roles" validate:"omitempty,enum=admin_role"`
+	Birthday string     `json:"birthday" validate:"omitempty,datetz"`
+	Expiry   *time.Time `json:"expiry" validate:"omitempty,future"`
+	Address  struct {
+		City string `json:"city" validate:"required"`
+	} `json:"address"`
+}
+
+func validContact() testContact {
+	contact := testContact{Name: "Jane", Phone: "081234567890", Nik: "3201010101900001", Role: "admin", Roles: []string{"VIEWER"}, Birthday: "1990-01-01T00:00:00+07:00"}
+	contact.Address.City = "Bandung"
+	return contact
+}
+
+func TestFieldErrors(t *testing.T) {
+	v := newTestValidator(t)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		modify func(*testContact)
+		want   FieldError
+	}{
+		{name: "required", modify: func(c *testContact) { c.Name = "" }, want: FieldError{Field: "name", Rule: "required", Message: "name is a required field"}},
+		{name: "phone", modify: func(c *testContact) { c.Phone = "12" }, want: FieldError{Field: "phone", Rule: TagPhone, Message: "phone must be a valid phone number"}},
+		{name: "nik", modify: func(c *testContact) { c.Nik = "1234" }, want: FieldError{Field: "nik", Rule: TagNik, Message: "nik must be a valid 16 digit NIK"}},
+		{name: "enum", modify: func(c *testContact) { c.Role = "ROOT" }, want: FieldError{Field: "role", Rule: TagEnum, Param: "admin_role", Message: "role must be one of SUPER_ADMIN, ADMIN, OPERATOR, VIEWER"}},
+		{name: "enum slice", modify: func(c *testContact) { c.Roles = []string{"ADMIN", "ROOT"} }, want: FieldError{Field: "roles", Rule: TagEnum, Param: "admin_role", Message: "roles must be one of SUPER_ADMIN, ADMIN, OPERATOR, VIEWER"}},
+		{name: "datetz", modify: func(c *testContact) { c.Birthday = "1990-01-01T00:00:00" }, want: FieldError{Field: "birthday", Rule: TagDateTz, Message: "birthday must be an RFC 3339 date with a timezone offset"}},
+		{name: "future", modify: func(c *testContact) { c.Expiry = &past }, want: FieldError{Field: "expiry", Rule: TagFuture, Message: "expiry must be in the future"}},
+		{name: "nested", modify: func(c *testContact) { c.Address.City = "" }, want: FieldError{Field: "address.city", Rule: "required", Message: "city is a required field"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contact := validContact()
+			tt.modify(&contact)
+
+			fields, ok := FieldErrors(v.Struct(contact), "en")
+			if !ok || len(fields) != 1 {
+				t.Fatalf("FieldErrors() = %+v, %v, want one field error", fields, ok)
+			}
+			if fields[0] != tt.want {
+				t.Errorf("FieldErrors() = %+v, want %+v", fields[0], tt.want)
+			}
+		})
+	}
+
+	if err := v.Struct(validContact()); err != nil {
+		t.Errorf("Struct(valid contact) error = %v", err)
+	}
+}
+
+func TestFieldErrorsAreLocalized(t *testing.T) {
+	v := newTestValidator(t)
+
+	contact := validContact()
+	contact.Phone = "12"
+
+	fields, ok := FieldErrors(v.Struct(contact), "id")
+	if !ok || len(fields) != 1 || fields[0].Message != "phone harus berupa nomor telepon yang valid" {
+		t.Errorf("FieldErrors(id) = %+v, %v", fields, ok)
+	}
+
+	// unsupported languages fall back to English
+	fields, ok = FieldErrors(v.Struct(contact), "fr")
+	if !ok || len(fields) != 1 || fields[0].Message != "phone must be a valid phone number" {
+		t.Errorf("FieldErrors(fr) = %+v, %v", fields, ok)
+	}
+}
+
+func TestFieldErrorsOfTypes(t *testing.T) {
+	var contact testContact
+	err := json.Unmarshal([]byte(`{"name": 42}`), &contact)
+
+	fields, ok := FieldErrors(err, "en")
+	if !ok || len(fields) != 1 {
+		t.Fatalf("FieldErrors() = %+v, %v, want one field error", fields, ok)
+	}
+	if want := (FieldError{Field: "name", Rule: "type", Param: "string", Message: "name must be a string"}); fields[0] != want {
+		t.Errorf("FieldErrors() = %+v, want %+v", fields[0], want)
+	}
+
+	if _, ok := FieldErrors(json.Unmarshal([]byte(`{`), &contact), "en"); ok {
+		t.Error("FieldErrors(syntax error) ok = true, want false")
+	}
+}
@@ -0,0 +1,66 @@
+// Package worker runs background jobs on a fixed number of goroutines.
+package worker
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	DefaultWorkers   = 2
+	DefaultQueueSize = 100
+)
+
+var ErrQueueFull = errors.New("worker queue is full")
+
+// Job runs in the background with the context of the pool.
+type Job func(ctx context.Context)
+
+// Pool runs jobs from a bounded queue, so that a burst of requests cannot
+// start an unbounded number of goroutines. Jobs submitted to a full queue are
+// refused instead of waiting.
+type Pool struct {
+	workers int
+	jobs    chan Job
+}
+
+// NewPool creates a pool. Zero values fall back to the defaults.
+func NewPool(workers int, queueSize int) *Pool {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	return &Pool{
+		workers: workers,
+		jobs:    make(chan Job, queueSize),
+	}
+}
+
+// Start runs the workers until the context is done. Jobs still queued then are dropped.
+func (p *Pool) Start(ctx context.Context) {
+	for range p.workers {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-p.jobs:
+					job(ctx)
+				}
+			}
+		}()
+	}
+}
+
+// Submit queues the job, or returns ErrQueueFull without blocking.
+func (p *Pool) Submit(job Job) error {
+	select {
+	case p.jobs <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
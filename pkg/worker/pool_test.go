@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPoolRunsTheJobs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool := NewPool(2, 10)
+	pool.Start(ctx)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ran := 0
+
+	for range 10 {
+		wg.Add(1)
+		if err := pool.Submit(func(context.Context) {
+			defer wg.Done()
+			mu.Lock()
+			ran++
+			mu.Unlock()
+		}); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	wg.Wait()
+
+	if ran != 10 {
+		t.Errorf("ran = %d jobs, want 10", ran)
+	}
+}
+
+func TestPoolRefusesJobsWhenFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool := NewPool(1, 1)
+	pool.Start(ctx)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	if err := pool.Submit(func(context.Context) {
+		close(started)
+		<-release
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	// the worker is busy, one job waits in the queue
+	if err := pool.Submit(func(context.Context) {}); err != nil {
+		t.Fatalf("Submit() error = %v, want the job queued", err)
+	}
+	if err := pool.Submit(func(context.Context) {}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Submit() error = %v, want %v", err, ErrQueueFull)
+	}
+
+	close(release)
+}
+
+func TestPoolStopsWithTheContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pool := NewPool(1, 1)
+	pool.Start(ctx)
+	cancel()
+
+	done := make(chan struct{})
+	time.AfterFunc(50*time.Millisecond, func() {
+		_ = pool.Submit(func(context.Context) { close(done) })
+	})
+
+	select {
+	case <-done:
+		t.Error("job ran after the context was done")
+	case <-time.After(200 * time.Millisecond):
+	}
+}